@@ -2,13 +2,23 @@ package main
 
 import (
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/urfave/cli/v2"
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/backup"
 	"github.com/yanodintsovmercuryo/cursync/pkg/config"
+	"github.com/yanodintsovmercuryo/cursync/pkg/editor"
 	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
 	"github.com/yanodintsovmercuryo/cursync/pkg/git"
+	"github.com/yanodintsovmercuryo/cursync/pkg/index"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
 	"github.com/yanodintsovmercuryo/cursync/pkg/output"
 	"github.com/yanodintsovmercuryo/cursync/pkg/path"
+	"github.com/yanodintsovmercuryo/cursync/pkg/watcher"
 	cfgService "github.com/yanodintsovmercuryo/cursync/service/config"
 	"github.com/yanodintsovmercuryo/cursync/service/file"
 	"github.com/yanodintsovmercuryo/cursync/service/sync"
@@ -16,12 +26,18 @@ import (
 
 const version = "v1.0.1"
 
+// watchPollInterval is the interval of polling watched directories where inotify is not used
+const watchPollInterval = time.Second
+
 func main() {
 	outputService := output.NewOutput()
-	fileOpsImpl := file_ops.NewFileOps()
+	backupRepository := backup.NewBackupRepository(backup.DefaultDir())
+	fileOpsImpl := backup.NewFileOps(file_ops.NewFileOps(), backupRepository)
 	pathUtilsImpl := path.NewPathUtils()
 	gitOpsImpl := git.NewGit()
-	fileServiceImpl := file.NewFileService(outputService, fileOpsImpl, pathUtilsImpl)
+	contentIndex := index.NewIndex(index.DefaultDir())
+	fileServiceImpl := file.NewFileService(outputService, fileOpsImpl, pathUtilsImpl, contentIndex)
+	watcherImpl := watcher.NewWatcher(watchPollInterval)
 
 	syncService := sync.NewSyncService(
 		outputService,
@@ -29,18 +45,62 @@ func main() {
 		pathUtilsImpl,
 		gitOpsImpl,
 		fileServiceImpl,
+		manifest.NewManifestRepository(),
+		backupRepository,
+		contentIndex,
+		watcherImpl,
 	)
 
-	cfgServiceInstance := cfgService.NewCfgService(config.NewConfigRepository(), outputService)
+	configRepository := config.NewConfigRepository()
+	cfgServiceInstance := cfgService.NewCfgService(configRepository, outputService, gitOpsImpl, editor.NewEditor())
+
+	setBackupLimits := func(c *cli.Context) {
+		options, err := cfgServiceInstance.CreateBackupOptions(c)
+		if err != nil {
+			outputService.PrintFatalf("Error: %v", err)
+		}
+		backupRepository.SetLimits(options.MaxCount, options.MaxAge)
+	}
+
+	// rollbackOnSignal makes pull or push stop before the next file and roll back its changes on Ctrl+C or SIGTERM,
+	// a second signal kills the process, returned function stops handling signals
+	rollbackOnSignal := func() func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-signals:
+				signal.Stop(signals)
+				outputService.PrintWarning("Interrupted, rolling back changes")
+				backupRepository.Interrupt()
+			case <-done:
+			}
+		}()
+		return func() {
+			signal.Stop(signals)
+			close(done)
+		}
+	}
 
 	app := &cli.App{
 		Name:    "cursor-rules-syncer",
 		Usage:   "A CLI tool to sync cursor rules",
 		Version: version,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  cfgService.FlagConfig,
+				Usage: "Path to config file (overrides $CURSYNC_CONFIG, default: $XDG_CONFIG_HOME/cursync.toml or ~/.config/cursync.toml)",
+			},
+		},
+		Before: func(c *cli.Context) error {
+			configRepository.SetConfigPath(c.String(cfgService.FlagConfig))
+			return nil
+		},
 		Commands: []*cli.Command{
 			{
 				Name:  "pull",
-				Usage: "Pulls rules from the source directory to the current git project's .cursor/rules directory, deleting extra files in the project.",
+				Usage: "Pulls rules from the source directory to the current git project's .cursor/rules directory, deleting files previously pulled but removed from the source.",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:    cfgService.FlagRulesDir,
@@ -55,13 +115,63 @@ func main() {
 					&cli.StringFlag{
 						Name:    cfgService.FlagFilePatterns,
 						Aliases: []string{cfgService.FlagAliasFilePatterns},
-						Usage:   "Comma-separated file patterns to sync (e.g., 'local_*.mdc,translate/*.md') (overrides config file)",
+						Usage:   "Comma-separated file patterns to pull, braces are expanded (e.g., '*.{md,mdc},translate/*') (overrides pull.include and file_patterns)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagExclude,
+						Usage: "Comma-separated file patterns to skip (overrides pull.exclude)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagDelete,
+						Usage: "Which destination files missing in source to delete: all, none or managed (overrides delete)",
+					},
+					&cli.BoolFlag{
+						Name:  cfgService.FlagNoDelete,
+						Usage: "Never delete destination files, same as --delete=none",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagFileMode,
+						Usage: "Permissions of copied files: preserve source mode and modification time, or octal mode like 0644 (overrides file_mode)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagSymlinks,
+						Usage: "How symlinks in source directory are synced: follow, preserve or skip (overrides symlinks)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagJobs,
+						Usage: "Number of files compared and copied in parallel: auto for one per CPU, or a positive number (overrides jobs)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagLineEndings,
+						Usage: "Line endings of copied text files: lf, crlf, native or preserve (overrides line_endings)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagCompare,
+						Usage: "How text files are compared: normalized or exact (overrides compare)",
+					},
+					&cli.BoolFlag{
+						Name:  cfgService.FlagLink,
+						Usage: "Symlink project rules to files of the rules directory instead of copying them, pull without it converts links back to copies",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagProfile,
+						Usage: "Name of configuration profile to use (overrides default_profile)",
+					},
+					&cli.BoolFlag{
+						Name:  cfgService.FlagExplain,
+						Usage: "Print effective value of each option and where it came from",
 					},
 				},
 				Action: func(c *cli.Context) error {
-					options := cfgServiceInstance.CreatePullOptions(c)
+					options, err := cfgServiceInstance.CreatePullOptions(c)
+					if err != nil {
+						outputService.PrintFatalf("Error: %v", err)
+					}
+					setBackupLimits(c)
 
-					_, err := syncService.PullRules(options)
+					stop := rollbackOnSignal()
+					_, err = syncService.PullRules(options)
+					stop()
 					if err != nil {
 						outputService.PrintFatalf("Error: %v", err)
 					}
@@ -70,7 +180,7 @@ func main() {
 			},
 			{
 				Name:  "push",
-				Usage: "Pushes rules from the current git project's .cursor/rules directory to the source directory, deleting extra files in the source, and commits changes",
+				Usage: "Pushes managed rules from the current git project's .cursor/rules directory to the source directory, deleting files previously pushed but removed from the project, and commits changes",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:    cfgService.FlagRulesDir,
@@ -90,13 +200,59 @@ func main() {
 					&cli.StringFlag{
 						Name:    cfgService.FlagFilePatterns,
 						Aliases: []string{cfgService.FlagAliasFilePatterns},
-						Usage:   "Comma-separated file patterns to sync (e.g., 'local_*.mdc,translate/*.md') (overrides config file)",
+						Usage:   "Comma-separated file patterns to push, braces are expanded (e.g., '*.{md,mdc},translate/*') (overrides push.include and file_patterns)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagExclude,
+						Usage: "Comma-separated file patterns to skip (overrides push.exclude)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagDelete,
+						Usage: "Which destination files missing in source to delete: all, none or managed (overrides delete)",
+					},
+					&cli.BoolFlag{
+						Name:  cfgService.FlagNoDelete,
+						Usage: "Never delete destination files, same as --delete=none",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagFileMode,
+						Usage: "Permissions of copied files: preserve source mode and modification time, or octal mode like 0644 (overrides file_mode)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagSymlinks,
+						Usage: "How symlinks in source directory are synced: follow, preserve or skip (overrides symlinks)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagJobs,
+						Usage: "Number of files compared and copied in parallel: auto for one per CPU, or a positive number (overrides jobs)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagLineEndings,
+						Usage: "Line endings of copied text files: lf, crlf, native or preserve (overrides line_endings)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagCompare,
+						Usage: "How text files are compared: normalized or exact (overrides compare)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagProfile,
+						Usage: "Name of configuration profile to use (overrides default_profile)",
+					},
+					&cli.BoolFlag{
+						Name:  cfgService.FlagExplain,
+						Usage: "Print effective value of each option and where it came from",
 					},
 				},
 				Action: func(c *cli.Context) error {
-					options := cfgServiceInstance.CreatePushOptions(c)
+					options, err := cfgServiceInstance.CreatePushOptions(c)
+					if err != nil {
+						outputService.PrintFatalf("Error: %v", err)
+					}
+					setBackupLimits(c)
 
-					_, err := syncService.PushRules(options)
+					stop := rollbackOnSignal()
+					_, err = syncService.PushRules(options)
+					stop()
 					if err != nil {
 						outputService.PrintFatalf("Error: %v", err)
 					}
@@ -104,42 +260,266 @@ func main() {
 				},
 			},
 			{
-				Name:        "cfg",
-				Usage:       "Manage configuration values",
-				Description: "Set, get, or clear configuration values. For string flags, empty value clears the default. For bool flags, use --flag=false to clear.",
+				Name:  "watch",
+				Usage: "Watches the current git project's .cursor/rules directory and the source directory, pushing or pulling changes once edits settle, until interrupted",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:    cfgService.FlagRulesDir,
 						Aliases: []string{cfgService.FlagAliasRulesDir},
-						Usage:   "Set default rules directory (empty value clears it)",
+						Usage:   "Path to rules directory (overrides config file)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagDirection,
+						Usage: "Which changes to sync: both, push (project changes only) or pull (source changes only)",
+						Value: string(models.WatchBoth),
+					},
+					&cli.DurationFlag{
+						Name:  cfgService.FlagDebounce,
+						Usage: "Quiet period after the last change before syncing, edits within it are pushed in a single commit",
+						Value: 500 * time.Millisecond,
+					},
+					&cli.BoolFlag{
+						Name:  cfgService.FlagPoll,
+						Usage: "Poll directories instead of using inotify, e.g. on network or container mounts",
+					},
+					&cli.BoolFlag{
+						Name:    cfgService.FlagGitWithoutPush,
+						Aliases: []string{cfgService.FlagAliasGitWithoutPush},
+						Usage:   "Commit changes but don't push to remote",
+					},
+					&cli.BoolFlag{
+						Name:    cfgService.FlagOverwriteHeaders,
+						Aliases: []string{cfgService.FlagAliasOverwriteHeaders},
+						Usage:   "Overwrite headers instead of preserving them",
 					},
 					&cli.StringFlag{
 						Name:    cfgService.FlagFilePatterns,
 						Aliases: []string{cfgService.FlagAliasFilePatterns},
-						Usage:   "Set default file patterns (empty value clears it)",
+						Usage:   "Comma-separated file patterns to sync, braces are expanded (e.g., '*.{md,mdc},translate/*') (overrides pull.include, push.include and file_patterns)",
 					},
 					&cli.StringFlag{
-						Name:    cfgService.FlagOverwriteHeaders,
-						Aliases: []string{cfgService.FlagAliasOverwriteHeaders},
-						Usage:   "Set default overwrite-headers flag (use 'true', '1', 'false', '0', or empty to clear)",
+						Name:  cfgService.FlagExclude,
+						Usage: "Comma-separated file patterns to skip (overrides pull.exclude and push.exclude)",
 					},
 					&cli.StringFlag{
-						Name:    cfgService.FlagGitWithoutPush,
-						Aliases: []string{cfgService.FlagAliasGitWithoutPush},
-						Usage:   "Set default git-without-push flag (use 'true', '1', 'false', '0', or empty to clear)",
+						Name:  cfgService.FlagDelete,
+						Usage: "Which destination files missing in source to delete: all, none or managed (overrides delete)",
+					},
+					&cli.BoolFlag{
+						Name:  cfgService.FlagNoDelete,
+						Usage: "Never delete destination files, same as --delete=none",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagFileMode,
+						Usage: "Permissions of copied files: preserve source mode and modification time, or octal mode like 0644 (overrides file_mode)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagSymlinks,
+						Usage: "How symlinks in source directory are synced: follow, preserve or skip (overrides symlinks)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagJobs,
+						Usage: "Number of files compared and copied in parallel: auto for one per CPU, or a positive number (overrides jobs)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagLineEndings,
+						Usage: "Line endings of copied text files: lf, crlf, native or preserve (overrides line_endings)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagCompare,
+						Usage: "How text files are compared: normalized or exact (overrides compare)",
+					},
+					&cli.BoolFlag{
+						Name:  cfgService.FlagLink,
+						Usage: "Pull symlinks to files of the rules directory instead of copies, edits through them are committed",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagProfile,
+						Usage: "Name of configuration profile to use (overrides default_profile)",
 					},
 				},
 				Action: func(c *cli.Context) error {
-					if !cfgServiceInstance.HasConfigFlags(c) {
-						return cfgServiceInstance.ShowConfig()
+					options, err := cfgServiceInstance.CreateWatchOptions(c)
+					if err != nil {
+						outputService.PrintFatalf("Error: %v", err)
+					}
+					setBackupLimits(c)
+
+					watcherImpl.SetPolling(c.Bool(cfgService.FlagPoll))
+
+					ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+					defer stop()
+
+					if err := syncService.Watch(ctx, options); err != nil {
+						outputService.PrintFatalf("Error: %v", err)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "add",
+				Usage:     "Marks files of the current git project's .cursor/rules directory as managed, so push sends them to the source directory",
+				ArgsUsage: "<file>...",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    cfgService.FlagRulesDir,
+						Aliases: []string{cfgService.FlagAliasRulesDir},
+						Usage:   "Path to rules directory (overrides config file)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagProfile,
+						Usage: "Name of configuration profile to use (overrides default_profile)",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.NArg() == 0 {
+						outputService.PrintFatalf("Error: no files given")
+					}
+
+					options, err := cfgServiceInstance.CreatePushOptions(c)
+					if err != nil {
+						outputService.PrintFatalf("Error: %v", err)
+					}
+
+					if err := syncService.AddFiles(options, c.Args().Slice()); err != nil {
+						outputService.PrintFatalf("Error: %v", err)
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "undo",
+				Usage: "Restores files changed by the last pull or push in the current git project",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  cfgService.FlagForce,
+						Usage: "Restore files even if they were changed after the pull or push",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if _, err := syncService.Undo(c.Bool(cfgService.FlagForce)); err != nil {
+						outputService.PrintFatalf("Error: %v", err)
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "backups",
+				Usage: "Lists backups of files changed by pulls and pushes in the current git project",
+				Action: func(c *cli.Context) error {
+					if _, err := syncService.ListBackups(); err != nil {
+						outputService.PrintFatalf("Error: %v", err)
 					}
-					return cfgServiceInstance.UpdateConfig(c)
+					return nil
+				},
+			},
+			{
+				Name:  "status",
+				Usage: "Shows managed, unmanaged and orphaned files of the current git project's .cursor/rules directory",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    cfgService.FlagRulesDir,
+						Aliases: []string{cfgService.FlagAliasRulesDir},
+						Usage:   "Path to rules directory (overrides config file)",
+					},
+					&cli.StringFlag{
+						Name:  cfgService.FlagProfile,
+						Usage: "Name of configuration profile to use (overrides default_profile)",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					options, err := cfgServiceInstance.CreatePullOptions(c)
+					if err != nil {
+						outputService.PrintFatalf("Error: %v", err)
+					}
+
+					if _, err := syncService.Status(options); err != nil {
+						outputService.PrintFatalf("Error: %v", err)
+					}
+					return nil
+				},
+			},
+			{
+				Name:        "cfg",
+				Usage:       "Manage configuration values",
+				Description: "Without subcommand displays configuration, same as 'cfg list'. Keys: " + configKeyNames() + ".",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  cfgService.FlagProfile,
+						Usage: "Show values of the named profile",
+					},
+					&cli.BoolFlag{
+						Name:  cfgService.FlagExplain,
+						Usage: "Print effective value of each option and where it came from (respects --profile)",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.Bool(cfgService.FlagExplain) {
+						return cfgServiceInstance.ExplainConfig(c)
+					}
+					return cfgServiceInstance.ShowConfig(c)
+				},
+				Subcommands: []*cli.Command{
+					{
+						Name:      "get",
+						Usage:     "Print value of a configuration key",
+						ArgsUsage: "<key>",
+						Flags:     []cli.Flag{profileFlag()},
+						Action:    cfgServiceInstance.GetConfig,
+					},
+					{
+						Name:      "set",
+						Usage:     "Set value of a configuration key, bool values accept true, false, 1 or 0",
+						ArgsUsage: "<key> <value>",
+						Flags:     []cli.Flag{profileFlag()},
+						Action:    cfgServiceInstance.SetConfig,
+					},
+					{
+						Name:      "unset",
+						Usage:     "Clear value of a configuration key",
+						ArgsUsage: "<key>",
+						Flags:     []cli.Flag{profileFlag()},
+						Action:    cfgServiceInstance.UnsetConfig,
+					},
+					{
+						Name:   "list",
+						Usage:  "Display configuration",
+						Flags:  []cli.Flag{profileFlag()},
+						Action: cfgServiceInstance.ShowConfig,
+					},
+					{
+						Name:   "edit",
+						Usage:  "Open config file in $EDITOR, changes are saved only if they are valid",
+						Action: cfgServiceInstance.EditConfig,
+					},
+					{
+						Name:   "validate",
+						Usage:  "Check that configured rules directories exist and file patterns parse",
+						Action: cfgServiceInstance.ValidateConfig,
+					},
 				},
 			},
 		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
-		os.Exit(1)
+		outputService.PrintFatalf("Error: %v", err)
+	}
+}
+
+// profileFlag returns flag selecting profile of cfg subcommands
+func profileFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  cfgService.FlagProfile,
+		Usage: "Use values of the named profile instead of global ones",
+	}
+}
+
+// configKeyNames returns comma-separated names of configuration keys
+func configKeyNames() string {
+	names := []string{}
+	for _, key := range config.Keys() {
+		names = append(names, key.Name)
 	}
+	return strings.Join(names, ", ")
 }
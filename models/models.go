@@ -1,5 +1,13 @@
 package models
 
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // OperationType represents the type of file operation
 type OperationType string
 
@@ -17,12 +25,32 @@ type FileOperation struct {
 	RelativePath string        `json:"relative_path"`
 }
 
+// ChangedFile represents a file changed in rules repository history
+type ChangedFile struct {
+	Type         OperationType `json:"type"`
+	RelativePath string        `json:"relative_path"`
+}
+
 // SyncResult represents the result of a sync operation
 type SyncResult struct {
 	Operations []FileOperation `json:"operations"`
 	HasChanges bool            `json:"has_changes"`
 }
 
+// FileStatus describes ownership of a file in project rules directory
+type FileStatus struct {
+	RelativePath string `json:"relative_path"`
+	Source       string `json:"source,omitempty"` // rules directory the file was synced from, empty for unmanaged files
+	Linked       bool   `json:"linked,omitempty"` // file is a symlink to its source made by pull --link
+}
+
+// StatusResult groups files of project rules directory by ownership
+type StatusResult struct {
+	Managed   []FileStatus `json:"managed"`   // files synced by cursync whose source still exists
+	Unmanaged []FileStatus `json:"unmanaged"` // local files not synced by cursync
+	Orphaned  []FileStatus `json:"orphaned"`  // files synced by cursync whose source no longer exists
+}
+
 // IgnorePattern represents a compiled ignore pattern
 type IgnorePattern struct {
 	Pattern    string `json:"pattern"`
@@ -30,10 +58,212 @@ type IgnorePattern struct {
 	IsNegation bool   `json:"is_negation"`
 }
 
+// DeleteMode defines which destination files missing in source are deleted
+type DeleteMode string
+
+const (
+	DeleteAll     DeleteMode = "all"
+	DeleteNone    DeleteMode = "none"
+	DeleteManaged DeleteMode = "managed"
+
+	// DefaultDeleteMode is delete mode used when none is configured
+	DefaultDeleteMode = DeleteManaged
+)
+
+// ParseDeleteMode parses delete mode, empty value means DefaultDeleteMode
+func ParseDeleteMode(value string) (DeleteMode, error) {
+	switch mode := DeleteMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return DefaultDeleteMode, nil
+	case DeleteAll, DeleteNone, DeleteManaged:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid delete mode %q, use all, none or managed", value)
+	}
+}
+
+// SymlinkMode defines how symlinks found in source directory are synced
+type SymlinkMode string
+
+const (
+	SymlinkFollow   SymlinkMode = "follow"
+	SymlinkPreserve SymlinkMode = "preserve"
+	SymlinkSkip     SymlinkMode = "skip"
+)
+
+// ParseSymlinkMode parses symlink mode, empty value means SymlinkFollow
+func ParseSymlinkMode(value string) (SymlinkMode, error) {
+	switch mode := SymlinkMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return SymlinkFollow, nil
+	case SymlinkFollow, SymlinkPreserve, SymlinkSkip:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid symlink mode %q, use follow, preserve or skip", value)
+	}
+}
+
+// FileScan holds files found in directory tree
+type FileScan struct {
+	Files   []string // Files selected by symlink mode
+	Links   bool     // Tree contains symlinks
+	Skipped []string // Symlinks left out because they point outside the tree
+}
+
+// FileModePreserve keeps permissions and modification time of source files when copying
+const FileModePreserve os.FileMode = 0
+
+// ParseFileMode parses permissions applied to copied files, empty value and "preserve" mean FileModePreserve
+func ParseFileMode(value string) (os.FileMode, error) {
+	mode := strings.ToLower(strings.TrimSpace(value))
+	if mode == "" || mode == "preserve" {
+		return FileModePreserve, nil
+	}
+
+	perm, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || perm == 0 || perm > uint64(os.ModePerm) {
+		return 0, fmt.Errorf("invalid file mode %q, use preserve or octal permissions like 0644", value)
+	}
+	return os.FileMode(perm), nil
+}
+
+// ParseJobs parses number of files synced in parallel, empty value and "auto" mean 0, one job per CPU
+func ParseJobs(value string) (int, error) {
+	jobs := strings.ToLower(strings.TrimSpace(value))
+	if jobs == "" || jobs == "auto" {
+		return 0, nil
+	}
+
+	count, err := strconv.Atoi(jobs)
+	if err != nil || count < 1 {
+		return 0, fmt.Errorf("invalid jobs %q, use auto or a positive number", value)
+	}
+	return count, nil
+}
+
+// LineEndings defines how content of copied text files is normalized, other than .mdc files only when set explicitly
+type LineEndings string
+
+const (
+	LineEndingsLF       LineEndings = "lf"
+	LineEndingsCRLF     LineEndings = "crlf"
+	LineEndingsNative   LineEndings = "native"
+	LineEndingsPreserve LineEndings = "preserve"
+)
+
+// ParseLineEndings parses line endings policy, empty value means LineEndingsLF
+func ParseLineEndings(value string) (LineEndings, error) {
+	switch lineEndings := LineEndings(strings.ToLower(strings.TrimSpace(value))); lineEndings {
+	case "":
+		return LineEndingsLF, nil
+	case LineEndingsLF, LineEndingsCRLF, LineEndingsNative, LineEndingsPreserve:
+		return lineEndings, nil
+	default:
+		return "", fmt.Errorf("invalid line endings %q, use lf, crlf, native or preserve", value)
+	}
+}
+
+// CompareMode defines how text files are compared
+type CompareMode string
+
+const (
+	CompareNormalized CompareMode = "normalized"
+	CompareExact      CompareMode = "exact"
+)
+
+// ParseCompareMode parses compare mode, empty value means CompareNormalized
+func ParseCompareMode(value string) (CompareMode, error) {
+	switch mode := CompareMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return CompareNormalized, nil
+	case CompareNormalized, CompareExact:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid compare mode %q, use normalized or exact", value)
+	}
+}
+
+// Normalization defines how text files are written and compared
+type Normalization struct {
+	LineEndings  LineEndings // Line endings of copied text files, LineEndingsLF when empty
+	AllTextFiles bool        // Apply LineEndings to all text files, set when the policy is explicit, only .mdc files otherwise
+	Compare      CompareMode // CompareNormalized when empty
+}
+
+// ParseBackupCount parses number of backups kept per project, 0 disables the limit
+func ParseBackupCount(value string) (int, error) {
+	count, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("invalid backup count %q, use a non-negative number", value)
+	}
+	return count, nil
+}
+
+// ParseBackupAge parses age after which backups are pruned, in days like 30d or as duration like 12h,
+// 0 disables the limit
+func ParseBackupAge(value string) (time.Duration, error) {
+	age := strings.ToLower(strings.TrimSpace(value))
+	if days, ok := strings.CutSuffix(age, "d"); ok {
+		count, err := strconv.Atoi(days)
+		if err != nil || count < 0 {
+			return 0, fmt.Errorf("invalid backup age %q, use days like 30d or duration like 12h", value)
+		}
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(age)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid backup age %q, use days like 30d or duration like 12h", value)
+	}
+	return duration, nil
+}
+
+// WatchDirection defines which directories watch monitors and which sync runs on their changes
+type WatchDirection string
+
+const (
+	WatchBoth WatchDirection = "both"
+	WatchPush WatchDirection = "push"
+	WatchPull WatchDirection = "pull"
+)
+
+// ParseWatchDirection parses watch direction, empty value means WatchBoth
+func ParseWatchDirection(value string) (WatchDirection, error) {
+	switch direction := WatchDirection(strings.ToLower(strings.TrimSpace(value))); direction {
+	case "":
+		return WatchBoth, nil
+	case WatchBoth, WatchPush, WatchPull:
+		return direction, nil
+	default:
+		return "", fmt.Errorf("invalid watch direction %q, use both, push or pull", value)
+	}
+}
+
 // SyncOptions contains configuration for sync operations
 type SyncOptions struct {
 	RulesDir         string
 	GitWithoutPush   bool
 	OverwriteHeaders bool
-	FilePatterns     string // Comma-separated file patterns to sync (e.g., "local_*.mdc,translate/*.md")
+	FilePatterns     []string      // File patterns to sync (e.g., "local_*.mdc", "translate/*.md"), all files when empty
+	ExcludePatterns  []string      // File patterns excluded from sync
+	DeleteMode       DeleteMode    // Destination files deleted when missing in source, parsed options hold DefaultDeleteMode unless configured
+	FileMode         os.FileMode   // Permissions of copied files, FileModePreserve keeps source mode and modification time
+	Symlinks         SymlinkMode   // How source symlinks are synced, SymlinkFollow when empty
+	Link             bool          // Pull symlinks to source files instead of copying them
+	Jobs             int           // Number of files compared and copied in parallel, one per CPU when 0
+	Normalization    Normalization // How text files are written and compared
+}
+
+// WatchOptions contains configuration for watch command
+type WatchOptions struct {
+	Pull      *SyncOptions   // Options of pulls run on changes of rules directory
+	Push      *SyncOptions   // Options of pushes run on changes of project rules
+	Direction WatchDirection // Directories watched, WatchBoth when empty
+	Debounce  time.Duration  // Quiet period after the last change before sync runs
+}
+
+// BackupOptions contains retention limits of backups made by pull and push
+type BackupOptions struct {
+	MaxCount int           // Number of backups kept per project, no limit when 0
+	MaxAge   time.Duration // Age after which backups are pruned, no limit when 0
 }
@@ -0,0 +1,39 @@
+package backup
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrModified is returned by restore when files were changed after the operation
+var ErrModified = errors.New("files changed after the operation")
+
+// ErrInterrupted is returned by backup of files changed after the operation was interrupted
+var ErrInterrupted = errors.New("operation interrupted")
+
+// Entry describes a file changed by an operation
+type Entry struct {
+	// Path is absolute path of the changed file
+	Path string `json:"path"`
+	// Existed reports if file existed before the operation, files created by the operation are removed on restore
+	Existed bool `json:"existed"`
+	// Mode is permission of the file before the operation
+	Mode uint32 `json:"mode,omitempty"`
+	// File is name of the saved copy within the backup directory
+	File string `json:"file,omitempty"`
+	// Link is target of the file if it was a symlink, links are restored without saved copy
+	Link string `json:"link,omitempty"`
+	// Result is fingerprint of the file left by the operation, restore refuses to overwrite files changed since then
+	Result string `json:"result,omitempty"`
+}
+
+// Backup holds previous versions of files changed by a single pull or push
+type Backup struct {
+	ID          string    `json:"id"`
+	Operation   string    `json:"operation"`
+	ProjectRoot string    `json:"project_root"`
+	CreatedAt   time.Time `json:"created_at"`
+	Entries     []Entry   `json:"entries"`
+	// Committed is repository directory where the operation committed its changes, commits are not reverted on restore
+	Committed string `json:"committed,omitempty"`
+}
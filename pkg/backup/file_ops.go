@@ -0,0 +1,54 @@
+package backup
+
+import (
+	"os"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
+)
+
+// FileOps saves previous version of every file it overwrites or removes into the backup repository
+type FileOps struct {
+	*file_ops.FileOps
+	backups *BackupRepository
+}
+
+// NewFileOps creates FileOps backing up files changed by fileOps
+func NewFileOps(fileOps *file_ops.FileOps, backups *BackupRepository) *FileOps {
+	return &FileOps{
+		FileOps: fileOps,
+		backups: backups,
+	}
+}
+
+// WriteFile backs up the file and writes content to it, symlinked file is written through so its target is backed up
+func (f *FileOps) WriteFile(filePath, content string, perm os.FileMode) error {
+	if err := f.backups.Save(file_ops.ResolvePath(filePath)); err != nil {
+		return err
+	}
+	return f.FileOps.WriteFile(filePath, content, perm)
+}
+
+// CopyFile backs up destination file and copies source file over it, symlinked destination is written through
+// so its target is backed up
+func (f *FileOps) CopyFile(srcPath, dstPath string) error {
+	if err := f.backups.Save(file_ops.ResolvePath(dstPath)); err != nil {
+		return err
+	}
+	return f.FileOps.CopyFile(srcPath, dstPath)
+}
+
+// WriteSymlink backs up the file and replaces it with symlink to target
+func (f *FileOps) WriteSymlink(target, filePath string) error {
+	if err := f.backups.Save(filePath); err != nil {
+		return err
+	}
+	return f.FileOps.WriteSymlink(target, filePath)
+}
+
+// RemoveFile backs up the file and removes it
+func (f *FileOps) RemoveFile(filePath string) error {
+	if err := f.backups.Save(filePath); err != nil {
+		return err
+	}
+	return f.FileOps.RemoveFile(filePath)
+}
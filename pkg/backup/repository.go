@@ -0,0 +1,509 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
+)
+
+const (
+	backupFileName = "backup.json"
+	filesDirName   = "files"
+	idTimeFormat   = "20060102-150405.000000"
+
+	// companionFilePrefix prefixes names of saved companion files, other files are named by their entry index
+	companionFilePrefix = "c"
+
+	// DefaultMaxCount is number of backups kept per project
+	DefaultMaxCount = 20
+	// DefaultMaxAge is age after which backups are pruned
+	DefaultMaxAge = 30 * 24 * time.Hour
+)
+
+// BackupRepository stores previous versions of files changed by sync operations,
+// backups are kept per project under the root directory
+type BackupRepository struct {
+	rootDir  string
+	maxCount int
+	maxAge   time.Duration
+	now      func() time.Time
+
+	mu          sync.Mutex
+	session     *session
+	interrupted bool
+}
+
+// session holds backup being recorded by the current operation
+type session struct {
+	backup    *Backup
+	dir       string
+	recorded  map[string]bool
+	companion []Entry
+}
+
+// NewBackupRepository creates BackupRepository storing backups in rootDir
+func NewBackupRepository(rootDir string) *BackupRepository {
+	return &BackupRepository{
+		rootDir:  rootDir,
+		maxCount: DefaultMaxCount,
+		maxAge:   DefaultMaxAge,
+		now:      time.Now,
+	}
+}
+
+// DefaultDir returns backups directory within user cache directory, or within temp directory if there is none
+func DefaultDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	return filepath.Join(cacheDir, "cursync", "backups")
+}
+
+// SetLimits sets number and age of backups kept per project, zero disables the limit
+func (r *BackupRepository) SetLimits(maxCount int, maxAge time.Duration) {
+	r.maxCount = maxCount
+	r.maxAge = maxAge
+}
+
+// Begin starts recording backup of the operation in the project and writes its journal,
+// so that files changed by an interrupted operation can still be restored.
+// Companion files are kept only if the operation changes any other file
+func (r *BackupRepository) Begin(projectRoot, operation string, companions ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.interrupted {
+		return ErrInterrupted
+	}
+
+	now := r.now()
+	id := r.newID(projectRoot, now)
+	s := &session{
+		backup: &Backup{
+			ID:          id,
+			Operation:   operation,
+			ProjectRoot: projectRoot,
+			CreatedAt:   now,
+		},
+		dir:      filepath.Join(r.projectDir(projectRoot), id),
+		recorded: make(map[string]bool),
+	}
+
+	for i, path := range companions {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+		entry, err := readEntry(absPath)
+		if err != nil {
+			return err
+		}
+		if err := s.write(&entry, companionFilePrefix+strconv.Itoa(i)); err != nil {
+			return err
+		}
+		s.companion = append(s.companion, entry)
+		s.recorded[absPath] = true
+	}
+	if err := s.writeJournal(); err != nil {
+		return err
+	}
+
+	r.session = s
+	return nil
+}
+
+// Save saves current version of the file before it is overwritten or deleted,
+// does nothing outside of an operation or if the file was already saved by it
+func (r *BackupRepository) Save(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.session
+	if s == nil {
+		return nil
+	}
+	if r.interrupted {
+		return ErrInterrupted
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	if s.recorded[absPath] {
+		return nil
+	}
+
+	entry, err := readEntry(absPath)
+	if err != nil {
+		return err
+	}
+	if err := s.write(&entry, strconv.Itoa(len(s.backup.Entries))); err != nil {
+		return err
+	}
+
+	s.recorded[absPath] = true
+	s.backup.Entries = append(s.backup.Entries, entry)
+	return s.writeJournal()
+}
+
+// Interrupt makes the current operation fail before it changes the next file, so that it is rolled back
+// instead of leaving the project half-synced, later operations fail to start
+func (r *BackupRepository) Interrupt() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interrupted = true
+}
+
+// MarkCommitted records that the current operation committed its changes in the repository directory
+func (r *BackupRepository) MarkCommitted(repoDir string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.session != nil {
+		r.session.backup.Committed = repoDir
+	}
+}
+
+// Finish completes backup of the operation and prunes old backups of the project,
+// operations that changed no files leave no backup
+func (r *BackupRepository) Finish() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.session
+	r.session = nil
+	if s == nil {
+		return nil
+	}
+	if len(s.backup.Entries) == 0 {
+		return s.discard()
+	}
+	return r.store(s)
+}
+
+// Rollback puts back files changed by the current operation and discards its backup,
+// if some files cannot be restored the backup is kept so that the operation can still be undone
+func (r *BackupRepository) Rollback() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.session
+	r.session = nil
+	if s == nil {
+		return nil
+	}
+	if len(s.backup.Entries) == 0 {
+		return s.discard()
+	}
+
+	var errs []error
+	for i := len(s.backup.Entries) - 1; i >= 0; i-- {
+		if err := restoreEntry(s.dir, s.backup.Entries[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		if err := r.store(s); err != nil {
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+	return s.discard()
+}
+
+// store completes journal of the session with its companion files and prunes old backups of the project,
+// current state of every file is recorded as result of the operation
+func (r *BackupRepository) store(s *session) error {
+	s.backup.Entries = append(s.backup.Entries, s.companion...)
+	s.companion = nil
+	for i := range s.backup.Entries {
+		s.backup.Entries[i].Result = fingerprint(s.backup.Entries[i].Path)
+	}
+	if err := s.writeJournal(); err != nil {
+		return err
+	}
+
+	return r.prune(s.backup.ProjectRoot)
+}
+
+// List returns backups of the project, newest first
+func (r *BackupRepository) List(projectRoot string) ([]*Backup, error) {
+	entries, err := os.ReadDir(r.projectDir(projectRoot))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backups: %w", err)
+	}
+
+	backups := []*Backup{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		b, err := r.load(projectRoot, entry.Name())
+		if err != nil {
+			continue
+		}
+		backups = append(backups, b)
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].ID > backups[j].ID })
+	return backups, nil
+}
+
+// Restore puts back files changed by the latest operation in the project and removes its backup,
+// fails with ErrModified if files were changed after the operation unless force is set
+func (r *BackupRepository) Restore(projectRoot string, force bool) (*Backup, error) {
+	backups, err := r.List(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) == 0 {
+		return nil, fmt.Errorf("no backups for project %s", projectRoot)
+	}
+
+	latest := backups[0]
+	if !force {
+		if modified := modifiedFiles(latest); len(modified) > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrModified, strings.Join(modified, ", "))
+		}
+	}
+
+	dir := filepath.Join(r.projectDir(projectRoot), latest.ID)
+	for i := len(latest.Entries) - 1; i >= 0; i-- {
+		if err := restoreEntry(dir, latest.Entries[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to remove backup %s: %w", latest.ID, err)
+	}
+	return latest, nil
+}
+
+// projectDir returns directory holding backups of the project
+func (r *BackupRepository) projectDir(projectRoot string) string {
+	sum := sha256.Sum256([]byte(projectRoot))
+	return filepath.Join(r.rootDir, filepath.Base(projectRoot)+"-"+hex.EncodeToString(sum[:])[:12])
+}
+
+// newID returns id of backup started at the time, unique within the project
+func (r *BackupRepository) newID(projectRoot string, now time.Time) string {
+	base := now.UTC().Format(idTimeFormat)
+	id := base
+	for i := 1; ; i++ {
+		if _, err := os.Stat(filepath.Join(r.projectDir(projectRoot), id)); os.IsNotExist(err) {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// load loads backup of the project by id
+func (r *BackupRepository) load(projectRoot, id string) (*Backup, error) {
+	data, err := os.ReadFile(filepath.Join(r.projectDir(projectRoot), id, backupFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Backup{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("failed to parse backup %s: %w", id, err)
+	}
+	return b, nil
+}
+
+// prune removes backups of the project exceeding count and age limits
+func (r *BackupRepository) prune(projectRoot string) error {
+	backups, err := r.List(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i, b := range backups {
+		tooMany := r.maxCount > 0 && i >= r.maxCount
+		tooOld := r.maxAge > 0 && r.now().Sub(b.CreatedAt) > r.maxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(r.projectDir(projectRoot), b.ID)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to prune backup %s: %w", b.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// write streams content of the entry file into backup directory under the name
+func (s *session) write(entry *Entry, name string) error {
+	if !entry.Existed || entry.Link != "" {
+		return nil
+	}
+
+	filesDir := filepath.Join(s.dir, filesDirName)
+	if err := os.MkdirAll(filesDir, 0700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	src, err := os.Open(entry.Path)
+	if err != nil {
+		return fmt.Errorf("failed to back up %s: %w", entry.Path, err)
+	}
+	defer src.Close()
+
+	entry.File = name
+	if err := file_ops.CopyFileAtomic(filepath.Join(filesDir, entry.File), src, 0600); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", entry.Path, err)
+	}
+	return nil
+}
+
+// writeJournal writes backup of the session with files saved so far and its companion files
+func (s *session) writeJournal() error {
+	journal := *s.backup
+	journal.Entries = append(append([]Entry{}, s.backup.Entries...), s.companion...)
+
+	data, err := json.MarshalIndent(&journal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal backup: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if err := file_ops.WriteFileAtomic(filepath.Join(s.dir, backupFileName), data, 0600); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	return nil
+}
+
+// discard removes backup of the session
+func (s *session) discard() error {
+	if err := os.RemoveAll(s.dir); err != nil {
+		return fmt.Errorf("failed to remove backup %s: %w", s.backup.ID, err)
+	}
+	return nil
+}
+
+// readEntry reads current state of the file, its content is saved by session write
+func readEntry(path string) (Entry, error) {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return Entry{Path: path}, nil
+	}
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return Entry{}, fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+		return Entry{Path: path, Existed: true, Link: target}, nil
+	}
+	return Entry{Path: path, Existed: true, Mode: uint32(info.Mode().Perm())}, nil
+}
+
+// modifiedFiles returns files of the backup whose state differs from the one left by the operation,
+// files of backups recorded without results are not checked
+func modifiedFiles(b *Backup) []string {
+	var modified []string
+	for _, entry := range b.Entries {
+		if entry.Result != "" && fingerprint(entry.Path) != entry.Result {
+			modified = append(modified, entry.Path)
+		}
+	}
+	return modified
+}
+
+// fingerprint describes current state of the file: absent, symlink target or hash of content,
+// empty if the file cannot be read
+func fingerprint(path string) string {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return "absent"
+	}
+	if err != nil {
+		return ""
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return ""
+		}
+		return "link:" + target
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return ""
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// restoreEntry puts file back to its state before the operation
+func restoreEntry(dir string, entry Entry) error {
+	if !entry.Existed {
+		if err := os.Remove(entry.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", entry.Path, err)
+		}
+		return nil
+	}
+
+	if entry.Link != "" {
+		if err := os.MkdirAll(filepath.Dir(entry.Path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", entry.Path, err)
+		}
+		if err := file_ops.WriteSymlinkAtomic(entry.Link, entry.Path); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", entry.Path, err)
+		}
+		return nil
+	}
+
+	src, err := os.Open(filepath.Join(dir, filesDirName, entry.File))
+	if err != nil {
+		return fmt.Errorf("failed to read backup of %s: %w", entry.Path, err)
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(entry.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", entry.Path, err)
+	}
+	// symlink made by the operation is replaced, writing through it would change its target
+	if info, err := os.Lstat(entry.Path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(entry.Path); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", entry.Path, err)
+		}
+	}
+	if err := file_ops.CopyFileAtomic(entry.Path, src, os.FileMode(entry.Mode)); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", entry.Path, err)
+	}
+	if err := os.Chmod(entry.Path, os.FileMode(entry.Mode)); err != nil {
+		return fmt.Errorf("failed to restore mode of %s: %w", entry.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,343 @@
+package backup_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/backup"
+	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestBackupRepository_Restore(t *testing.T) {
+	t.Parallel()
+
+	projectRoot := t.TempDir()
+	rulesDir := filepath.Join(projectRoot, ".cursor", "rules")
+	updated := filepath.Join(rulesDir, "updated.mdc")
+	deleted := filepath.Join(rulesDir, "dir", "deleted.mdc")
+	added := filepath.Join(rulesDir, "added.mdc")
+	companion := filepath.Join(projectRoot, ".cursor", "cursync-manifest.json")
+	writeFile(t, updated, "old")
+	writeFile(t, deleted, "removed")
+	writeFile(t, companion, "manifest")
+
+	repo := backup.NewBackupRepository(t.TempDir())
+	fileOps := backup.NewFileOps(file_ops.NewFileOps(), repo)
+
+	require.NoError(t, repo.Begin(projectRoot, "pull", companion))
+	require.NoError(t, fileOps.WriteFile(updated, "new", 0644))
+	require.NoError(t, fileOps.WriteFile(updated, "newer", 0644))
+	require.NoError(t, fileOps.RemoveFile(deleted))
+	require.NoError(t, fileOps.WriteFile(added, "added", 0644))
+	writeFile(t, companion, "changed manifest")
+	require.NoError(t, repo.Finish())
+
+	backups, err := repo.List(projectRoot)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	require.Equal(t, "pull", backups[0].Operation)
+	require.Len(t, backups[0].Entries, 4)
+
+	restored, err := repo.Restore(projectRoot, false)
+	require.NoError(t, err)
+	require.Equal(t, backups[0].ID, restored.ID)
+
+	if diff := cmp.Diff("old", readFile(t, updated)); diff != "" {
+		t.Errorf("Updated file mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("removed", readFile(t, deleted)); diff != "" {
+		t.Errorf("Deleted file mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("manifest", readFile(t, companion)); diff != "" {
+		t.Errorf("Companion file mismatch (-want +got):\n%s", diff)
+	}
+	require.NoFileExists(t, added)
+
+	backups, err = repo.List(projectRoot)
+	require.NoError(t, err)
+	require.Empty(t, backups)
+}
+
+func TestBackupRepository_NoChanges(t *testing.T) {
+	t.Parallel()
+
+	projectRoot := t.TempDir()
+	companion := filepath.Join(projectRoot, "manifest.json")
+	writeFile(t, companion, "manifest")
+
+	repo := backup.NewBackupRepository(t.TempDir())
+	require.NoError(t, repo.Begin(projectRoot, "push", companion))
+	require.NoError(t, repo.Finish())
+
+	backups, err := repo.List(projectRoot)
+	require.NoError(t, err)
+	require.Empty(t, backups)
+
+	_, err = repo.Restore(projectRoot, false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no backups")
+}
+
+func TestBackupRepository_Rollback(t *testing.T) {
+	t.Parallel()
+
+	projectRoot := t.TempDir()
+	rulesDir := filepath.Join(projectRoot, ".cursor", "rules")
+	updated := filepath.Join(rulesDir, "updated.mdc")
+	deleted := filepath.Join(rulesDir, "deleted.mdc")
+	added := filepath.Join(rulesDir, "added.mdc")
+	writeFile(t, updated, "old")
+	writeFile(t, deleted, "removed")
+
+	repo := backup.NewBackupRepository(t.TempDir())
+	fileOps := backup.NewFileOps(file_ops.NewFileOps(), repo)
+
+	require.NoError(t, repo.Begin(projectRoot, "pull"))
+	require.NoError(t, fileOps.RemoveFile(deleted))
+	require.NoError(t, fileOps.WriteFile(updated, "new", 0644))
+	require.NoError(t, fileOps.WriteFile(added, "added", 0644))
+	require.NoError(t, repo.Rollback())
+
+	if diff := cmp.Diff("old", readFile(t, updated)); diff != "" {
+		t.Errorf("Updated file mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("removed", readFile(t, deleted)); diff != "" {
+		t.Errorf("Deleted file mismatch (-want +got):\n%s", diff)
+	}
+	require.NoFileExists(t, added)
+
+	// rolled back operation leaves no backup and a following finish does nothing
+	require.NoError(t, repo.Finish())
+	backups, err := repo.List(projectRoot)
+	require.NoError(t, err)
+	require.Empty(t, backups)
+}
+
+func TestBackupRepository_Interrupt(t *testing.T) {
+	t.Parallel()
+
+	projectRoot := t.TempDir()
+	rulesDir := filepath.Join(projectRoot, ".cursor", "rules")
+	updated := filepath.Join(rulesDir, "updated.mdc")
+	skipped := filepath.Join(rulesDir, "skipped.mdc")
+	writeFile(t, updated, "old")
+	writeFile(t, skipped, "kept")
+
+	repo := backup.NewBackupRepository(t.TempDir())
+	fileOps := backup.NewFileOps(file_ops.NewFileOps(), repo)
+
+	require.NoError(t, repo.Begin(projectRoot, "pull"))
+	require.NoError(t, fileOps.WriteFile(updated, "new", 0644))
+	repo.Interrupt()
+
+	err := fileOps.WriteFile(skipped, "new", 0644)
+	require.ErrorIs(t, err, backup.ErrInterrupted)
+	require.NoError(t, repo.Rollback())
+
+	if diff := cmp.Diff("old", readFile(t, updated)); diff != "" {
+		t.Errorf("Updated file mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("kept", readFile(t, skipped)); diff != "" {
+		t.Errorf("Skipped file mismatch (-want +got):\n%s", diff)
+	}
+
+	// interrupted process starts no further operations
+	require.ErrorIs(t, repo.Begin(projectRoot, "push"), backup.ErrInterrupted)
+}
+
+func TestBackupRepository_RestoreSymlinks(t *testing.T) {
+	t.Parallel()
+
+	projectRoot := t.TempDir()
+	rulesDir := filepath.Join(projectRoot, ".cursor", "rules")
+	replaced := filepath.Join(rulesDir, "replaced.mdc")
+	linked := filepath.Join(rulesDir, "linked.mdc")
+	writeFile(t, filepath.Join(rulesDir, "rule.mdc"), "rule")
+	writeFile(t, linked, "content")
+	require.NoError(t, os.Symlink("rule.mdc", replaced))
+
+	repo := backup.NewBackupRepository(t.TempDir())
+	fileOps := backup.NewFileOps(file_ops.NewFileOps(), repo)
+
+	require.NoError(t, repo.Begin(projectRoot, "pull"))
+	require.NoError(t, fileOps.WriteFile(replaced, "copied", 0644))
+	require.NoError(t, fileOps.WriteSymlink("rule.mdc", linked))
+	require.NoError(t, repo.Finish())
+
+	_, err := repo.Restore(projectRoot, false)
+	require.NoError(t, err)
+
+	target, err := os.Readlink(replaced)
+	require.NoError(t, err)
+	require.Equal(t, "rule.mdc", target)
+
+	info, err := os.Lstat(linked)
+	require.NoError(t, err)
+	require.Zero(t, info.Mode()&os.ModeSymlink)
+	if diff := cmp.Diff("content", readFile(t, linked)); diff != "" {
+		t.Errorf("Linked file mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBackupRepository_SaveOutsideOperation(t *testing.T) {
+	t.Parallel()
+
+	projectRoot := t.TempDir()
+	path := filepath.Join(projectRoot, "file.mdc")
+	writeFile(t, path, "content")
+
+	repo := backup.NewBackupRepository(t.TempDir())
+	require.NoError(t, repo.Save(path))
+
+	backups, err := repo.List(projectRoot)
+	require.NoError(t, err)
+	require.Empty(t, backups)
+}
+
+func TestBackupRepository_Prune(t *testing.T) {
+	t.Parallel()
+
+	projectRoot := t.TempDir()
+	path := filepath.Join(projectRoot, "file.mdc")
+	writeFile(t, path, "v0")
+
+	repo := backup.NewBackupRepository(t.TempDir())
+	repo.SetLimits(2, time.Hour)
+	fileOps := backup.NewFileOps(file_ops.NewFileOps(), repo)
+
+	for _, content := range []string{"v1", "v2", "v3"} {
+		require.NoError(t, repo.Begin(projectRoot, "pull"))
+		require.NoError(t, fileOps.WriteFile(path, content, 0644))
+		require.NoError(t, repo.Finish())
+	}
+
+	backups, err := repo.List(projectRoot)
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+
+	_, err = repo.Restore(projectRoot, false)
+	require.NoError(t, err)
+	if diff := cmp.Diff("v2", readFile(t, path)); diff != "" {
+		t.Errorf("Restored file mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBackupRepository_RestoreModified(t *testing.T) {
+	t.Parallel()
+
+	projectRoot := t.TempDir()
+	rulesDir := filepath.Join(projectRoot, ".cursor", "rules")
+	updated := filepath.Join(rulesDir, "updated.mdc")
+	added := filepath.Join(rulesDir, "added.mdc")
+	writeFile(t, updated, "old")
+
+	repo := backup.NewBackupRepository(t.TempDir())
+	fileOps := backup.NewFileOps(file_ops.NewFileOps(), repo)
+
+	require.NoError(t, repo.Begin(projectRoot, "push"))
+	require.NoError(t, fileOps.WriteFile(updated, "new", 0644))
+	require.NoError(t, fileOps.WriteFile(added, "added", 0644))
+	repo.MarkCommitted(rulesDir)
+	require.NoError(t, repo.Finish())
+
+	writeFile(t, added, "edited after push")
+
+	_, err := repo.Restore(projectRoot, false)
+	require.ErrorIs(t, err, backup.ErrModified)
+	require.Contains(t, err.Error(), added)
+	require.NotContains(t, err.Error(), updated)
+	if diff := cmp.Diff("new", readFile(t, updated)); diff != "" {
+		t.Errorf("Updated file mismatch (-want +got):\n%s", diff)
+	}
+
+	restored, err := repo.Restore(projectRoot, true)
+	require.NoError(t, err)
+	require.Equal(t, rulesDir, restored.Committed)
+	if diff := cmp.Diff("old", readFile(t, updated)); diff != "" {
+		t.Errorf("Updated file mismatch (-want +got):\n%s", diff)
+	}
+	require.NoFileExists(t, added)
+}
+
+func TestBackupRepository_RestoreInterrupted(t *testing.T) {
+	t.Parallel()
+
+	projectRoot := t.TempDir()
+	rulesDir := filepath.Join(projectRoot, ".cursor", "rules")
+	updated := filepath.Join(rulesDir, "updated.mdc")
+	added := filepath.Join(rulesDir, "added.mdc")
+	companion := filepath.Join(projectRoot, ".cursor", "cursync-manifest.json")
+	writeFile(t, updated, "old")
+	writeFile(t, companion, "manifest")
+
+	backupsDir := t.TempDir()
+	repo := backup.NewBackupRepository(backupsDir)
+	fileOps := backup.NewFileOps(file_ops.NewFileOps(), repo)
+
+	// operation is interrupted before Finish or Rollback
+	require.NoError(t, repo.Begin(projectRoot, "pull", companion))
+	require.NoError(t, fileOps.WriteFile(updated, "new", 0644))
+	require.NoError(t, fileOps.WriteFile(added, "added", 0644))
+	writeFile(t, companion, "changed manifest")
+
+	restored, err := backup.NewBackupRepository(backupsDir).Restore(projectRoot, false)
+	require.NoError(t, err)
+	require.Equal(t, "pull", restored.Operation)
+
+	if diff := cmp.Diff("old", readFile(t, updated)); diff != "" {
+		t.Errorf("Updated file mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("manifest", readFile(t, companion)); diff != "" {
+		t.Errorf("Companion file mismatch (-want +got):\n%s", diff)
+	}
+	require.NoFileExists(t, added)
+}
+
+func TestBackupRepository_RestoreWrittenThroughSymlink(t *testing.T) {
+	t.Parallel()
+
+	projectRoot := t.TempDir()
+	target := filepath.Join(t.TempDir(), "shared.mdc")
+	linked := filepath.Join(projectRoot, ".cursor", "rules", "shared.mdc")
+	writeFile(t, target, "old")
+	require.NoError(t, os.MkdirAll(filepath.Dir(linked), 0755))
+	require.NoError(t, os.Symlink(target, linked))
+
+	repo := backup.NewBackupRepository(t.TempDir())
+	fileOps := backup.NewFileOps(file_ops.NewFileOps(), repo)
+
+	require.NoError(t, repo.Begin(projectRoot, "pull"))
+	require.NoError(t, fileOps.WriteFile(linked, "new", 0644))
+	require.NoError(t, repo.Finish())
+
+	info, err := os.Lstat(linked)
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode()&os.ModeSymlink)
+	if diff := cmp.Diff("new", readFile(t, target)); diff != "" {
+		t.Errorf("Target file mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err = repo.Restore(projectRoot, false)
+	require.NoError(t, err)
+	if diff := cmp.Diff("old", readFile(t, target)); diff != "" {
+		t.Errorf("Target file mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -1,9 +1,100 @@
 package config
 
+import (
+	"fmt"
+	"sort"
+)
+
 // Config holds configuration values
 type Config struct {
-	RulesDir         string `toml:"rules_dir,omitempty"`
-	FilePatterns     string `toml:"file_patterns,omitempty"`
-	OverwriteHeaders bool   `toml:"overwrite_headers,omitempty"`
-	GitWithoutPush   bool   `toml:"git_without_push,omitempty"`
+	Version          int                 `toml:"version"`
+	RulesDir         string              `toml:"rules_dir,omitempty"`
+	FilePatterns     []string            `toml:"file_patterns,omitempty"`
+	OverwriteHeaders bool                `toml:"overwrite_headers,omitempty"`
+	GitWithoutPush   bool                `toml:"git_without_push,omitempty"`
+	Pull             Scope               `toml:"pull,omitempty"`
+	Push             Scope               `toml:"push,omitempty"`
+	Delete           string              `toml:"delete,omitempty"`
+	FileMode         string              `toml:"file_mode,omitempty"`
+	Symlinks         string              `toml:"symlinks,omitempty"`
+	Jobs             string              `toml:"jobs,omitempty"`
+	LineEndings      string              `toml:"line_endings,omitempty"`
+	Compare          string              `toml:"compare,omitempty"`
+	BackupCount      string              `toml:"backup_count,omitempty"`
+	BackupMaxAge     string              `toml:"backup_max_age,omitempty"`
+	DefaultProfile   string              `toml:"default_profile,omitempty"`
+	Profiles         map[string]*Profile `toml:"profile,omitempty"`
+
+	// Migration describes upgrade performed when the config was loaded, nil if config was up to date
+	Migration *Migration `toml:"-"`
+}
+
+// Overrides holds optional configuration values that take precedence over the global configuration
+type Overrides struct {
+	RulesDir         string   `toml:"rules_dir,omitempty"`
+	FilePatterns     []string `toml:"file_patterns,omitempty"`
+	OverwriteHeaders *bool    `toml:"overwrite_headers,omitempty"`
+	GitWithoutPush   *bool    `toml:"git_without_push,omitempty"`
+	Pull             Scope    `toml:"pull,omitempty"`
+	Push             Scope    `toml:"push,omitempty"`
+	Delete           string   `toml:"delete,omitempty"`
+	FileMode         string   `toml:"file_mode,omitempty"`
+	Symlinks         string   `toml:"symlinks,omitempty"`
+	Jobs             string   `toml:"jobs,omitempty"`
+	LineEndings      string   `toml:"line_endings,omitempty"`
+	Compare          string   `toml:"compare,omitempty"`
+}
+
+// Scope holds file patterns applied to a single sync direction,
+// include patterns replace file_patterns of the same configuration layer
+type Scope struct {
+	Include []string `toml:"include,omitempty"`
+	Exclude []string `toml:"exclude,omitempty"`
+}
+
+// Profile holds configuration values of a named profile
+type Profile struct {
+	Overrides
+}
+
+// ProjectConfig holds configuration values committed to the project
+type ProjectConfig struct {
+	Overrides
+}
+
+// EnvConfig holds configuration values set by CURSYNC_* environment variables
+type EnvConfig struct {
+	Overrides
+	DefaultProfile string
+}
+
+// GetProfile returns profile by name
+func (c *Config) GetProfile(name string) (*Profile, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile: %s", name)
+	}
+	return profile, nil
+}
+
+// ProfileNames returns sorted names of all profiles
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsEmpty checks if no override values are set
+func (o *Overrides) IsEmpty() bool {
+	return o.RulesDir == "" && len(o.FilePatterns) == 0 && o.OverwriteHeaders == nil && o.GitWithoutPush == nil &&
+		o.Delete == "" && o.FileMode == "" && o.Symlinks == "" && o.Jobs == "" && o.LineEndings == "" && o.Compare == "" &&
+		o.Pull.IsEmpty() && o.Push.IsEmpty()
+}
+
+// IsEmpty checks if no patterns are set
+func (s *Scope) IsEmpty() bool {
+	return len(s.Include) == 0 && len(s.Exclude) == 0
 }
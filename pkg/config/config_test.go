@@ -3,6 +3,7 @@ package config_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -10,6 +11,8 @@ import (
 )
 
 func TestConfigPath(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, "")
+	t.Setenv(config.EnvXDGConfigHome, "")
 	repo := config.NewConfigRepository()
 	path, err := repo.GetConfigPath()
 	if err != nil {
@@ -27,7 +30,65 @@ func TestConfigPath(t *testing.T) {
 	}
 }
 
+func TestConfigPathOverrides(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv(config.EnvConfigPath, "")
+	t.Setenv(config.EnvXDGConfigHome, configHome)
+
+	repo := config.NewConfigRepository()
+	assertConfigPath(t, repo, filepath.Join(configHome, "cursync.toml"))
+
+	// Relative XDG_CONFIG_HOME is ignored
+	t.Setenv(config.EnvXDGConfigHome, "relative")
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("Failed to get home directory: %v", err)
+	}
+	assertConfigPath(t, repo, filepath.Join(homeDir, ".config", "cursync.toml"))
+
+	envPath := filepath.Join(configHome, "env.toml")
+	t.Setenv(config.EnvConfigPath, envPath)
+	assertConfigPath(t, repo, envPath)
+
+	flagPath := filepath.Join(configHome, "flag.toml")
+	repo.SetConfigPath(flagPath)
+	assertConfigPath(t, repo, flagPath)
+}
+
+func assertConfigPath(t *testing.T, repo *config.ConfigRepository, expectedPath string) {
+	t.Helper()
+	path, err := repo.GetConfigPath()
+	if err != nil {
+		t.Fatalf("Failed to get config path: %v", err)
+	}
+	if path != expectedPath {
+		t.Errorf("Expected path %s, got %s", expectedPath, path)
+	}
+}
+
+func TestConfigLoadDoesNotCreateDirectory(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), "nested", "dir")
+	repo := config.NewConfigRepository()
+	repo.SetConfigPath(filepath.Join(configDir, "cursync.toml"))
+
+	if _, err := repo.Load(); err != nil {
+		t.Fatalf("Failed to load non-existent config: %v", err)
+	}
+	if _, err := os.Stat(configDir); !os.IsNotExist(err) {
+		t.Errorf("Expected config directory not to be created on load, got %v", err)
+	}
+
+	if err := repo.Save(&config.Config{RulesDir: "/path/to/rules"}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "cursync.toml")); err != nil {
+		t.Errorf("Expected config file to be created on save: %v", err)
+	}
+}
+
 func TestConfigLoadSave(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, "")
+	t.Setenv(config.EnvXDGConfigHome, "")
 	// Create temporary config file
 	tempDir := t.TempDir()
 	originalHome := os.Getenv("HOME")
@@ -39,9 +100,10 @@ func TestConfigLoadSave(t *testing.T) {
 	repo := config.NewConfigRepository()
 	cfg := &config.Config{
 		RulesDir:         "/path/to/rules",
-		FilePatterns:     "*.mdc",
+		FilePatterns:     []string{"*.mdc"},
 		OverwriteHeaders: true,
 		GitWithoutPush:   false,
+		Push:             config.Scope{Include: []string{"team/**"}, Exclude: []string{"team/draft_*"}},
 	}
 
 	if err := repo.Save(cfg); err != nil {
@@ -59,6 +121,8 @@ func TestConfigLoadSave(t *testing.T) {
 }
 
 func TestConfigLoadNonExistent(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, "")
+	t.Setenv(config.EnvXDGConfigHome, "")
 	tempDir := t.TempDir()
 	originalHome := os.Getenv("HOME")
 
@@ -71,7 +135,7 @@ func TestConfigLoadNonExistent(t *testing.T) {
 		t.Fatalf("Failed to load non-existent config: %v", err)
 	}
 
-	if cfg.RulesDir != "" || cfg.FilePatterns != "" || cfg.OverwriteHeaders || cfg.GitWithoutPush {
+	if cfg.RulesDir != "" || len(cfg.FilePatterns) > 0 || cfg.OverwriteHeaders || cfg.GitWithoutPush {
 		t.Errorf("Expected empty config, got %+v", cfg)
 	}
 }
@@ -137,20 +201,655 @@ func TestConfigGetAll(t *testing.T) {
 	repo := config.NewConfigRepository()
 	cfg := &config.Config{
 		RulesDir:         "/test/rules",
-		FilePatterns:     "*.mdc",
+		FilePatterns:     []string{"*.mdc"},
 		OverwriteHeaders: true,
 		GitWithoutPush:   false,
+		Pull:             config.Scope{Exclude: []string{"draft_*"}},
 	}
 
 	all := repo.GetAll(cfg)
 	expected := map[string]interface{}{
 		"rules_dir":         "/test/rules",
-		"file_patterns":     "*.mdc",
+		"symlinks":          "",
+		"jobs":              "",
+		"line_endings":      "",
+		"compare":           "",
+		"file_patterns":     []string{"*.mdc"},
+		"pull.include":      []string(nil),
+		"pull.exclude":      []string{"draft_*"},
+		"push.include":      []string(nil),
+		"push.exclude":      []string(nil),
 		"overwrite_headers": true,
 		"git_without_push":  false,
+		"delete":            "",
+		"file_mode":         "",
+		"backup_count":      "",
+		"backup_max_age":    "",
+		"default_profile":   "",
 	}
 
 	if diff := cmp.Diff(expected, all); diff != "" {
 		t.Errorf("GetAll mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestConfigLoadProject(t *testing.T) {
+	projectRoot := t.TempDir()
+	content := "rules_dir = \"shared/rules\"\nfile_patterns = \"*.mdc\"\noverwrite_headers = false\n\n[push]\ninclude = [\"team/**\"]\n"
+	if err := os.WriteFile(filepath.Join(projectRoot, ".cursync.toml"), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+
+	repo := config.NewConfigRepository()
+	projectCfg, err := repo.LoadProject(projectRoot)
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+
+	overwriteHeaders := false
+	expected := &config.ProjectConfig{
+		Overrides: config.Overrides{
+			RulesDir:         filepath.Join(projectRoot, "shared", "rules"),
+			FilePatterns:     []string{"*.mdc"},
+			OverwriteHeaders: &overwriteHeaders,
+			Push:             config.Scope{Include: []string{"team/**"}},
+		},
+	}
+	if diff := cmp.Diff(expected, projectCfg); diff != "" {
+		t.Errorf("Project config mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfigLoadProjectNonExistent(t *testing.T) {
+	repo := config.NewConfigRepository()
+	projectCfg, err := repo.LoadProject(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to load non-existent project config: %v", err)
+	}
+
+	if diff := cmp.Diff(&config.ProjectConfig{}, projectCfg); diff != "" {
+		t.Errorf("Expected empty project config (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfigSetProfile(t *testing.T) {
+	repo := config.NewConfigRepository()
+	cfg := &config.Config{}
+
+	if err := repo.SetProfile(cfg, "backend", "rules-dir", "/backend/rules"); err != nil {
+		t.Fatalf("Failed to set rules-dir in profile: %v", err)
+	}
+	if err := repo.SetProfile(cfg, "backend", "git-without-push", false); err != nil {
+		t.Fatalf("Failed to set git-without-push in profile: %v", err)
+	}
+
+	all, err := repo.GetAllProfile(cfg, "backend")
+	if err != nil {
+		t.Fatalf("Failed to get profile: %v", err)
+	}
+	expected := map[string]interface{}{
+		"rules_dir":        "/backend/rules",
+		"git_without_push": false,
+	}
+	if diff := cmp.Diff(expected, all); diff != "" {
+		t.Errorf("Profile mismatch (-want +got):\n%s", diff)
+	}
+
+	// Clearing all values removes the profile
+	if err := repo.SetProfile(cfg, "backend", "rules-dir", ""); err != nil {
+		t.Fatalf("Failed to clear rules-dir in profile: %v", err)
+	}
+	if err := repo.SetProfile(cfg, "backend", "git-without-push", ""); err != nil {
+		t.Fatalf("Failed to clear git-without-push in profile: %v", err)
+	}
+	if _, err := repo.GetAllProfile(cfg, "backend"); err == nil {
+		t.Error("Expected error for removed profile")
+	}
+
+	if err := repo.SetProfile(cfg, "backend", "invalid-key", "value"); err == nil {
+		t.Error("Expected error for invalid key")
+	}
+}
+
+func TestConfigLoadSaveProfiles(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, "")
+	t.Setenv(config.EnvXDGConfigHome, "")
+	tempDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+
+	os.Setenv("HOME", tempDir)
+	defer os.Setenv("HOME", originalHome)
+
+	overwriteHeaders := true
+	repo := config.NewConfigRepository()
+	cfg := &config.Config{
+		RulesDir:       "/path/to/rules",
+		DefaultProfile: "frontend",
+		Profiles: map[string]*config.Profile{
+			"frontend": {Overrides: config.Overrides{RulesDir: "/frontend/rules", OverwriteHeaders: &overwriteHeaders}},
+		},
+	}
+
+	if err := repo.Save(cfg); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	loaded, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if diff := cmp.Diff(cfg, loaded); diff != "" {
+		t.Errorf("Config mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfigLoadEnv(t *testing.T) {
+	t.Setenv(config.EnvRulesDir, "/env/rules")
+	t.Setenv(config.EnvFilePatterns, "*.{md,mdc}, docs/*")
+	t.Setenv(config.EnvPullInclude, "")
+	t.Setenv(config.EnvPullExclude, "")
+	t.Setenv(config.EnvPushInclude, "team/**")
+	t.Setenv(config.EnvPushExclude, "")
+	t.Setenv(config.EnvOverwriteHeaders, "false")
+	t.Setenv(config.EnvGitWithoutPush, "")
+	t.Setenv(config.EnvDelete, "managed")
+	t.Setenv(config.EnvFileMode, "0644")
+	t.Setenv(config.EnvSymlinks, "preserve")
+	t.Setenv(config.EnvJobs, "4")
+	t.Setenv(config.EnvLineEndings, "crlf")
+	t.Setenv(config.EnvCompare, "exact")
+	t.Setenv(config.EnvDefaultProfile, "backend")
+
+	repo := config.NewConfigRepository()
+	env, err := repo.LoadEnv()
+	if err != nil {
+		t.Fatalf("Failed to load env config: %v", err)
+	}
+
+	overwriteHeaders := false
+	expected := &config.EnvConfig{
+		Overrides: config.Overrides{
+			RulesDir:         "/env/rules",
+			FilePatterns:     []string{"*.{md,mdc}", "docs/*"},
+			OverwriteHeaders: &overwriteHeaders,
+			Push:             config.Scope{Include: []string{"team/**"}},
+			Delete:           "managed",
+			FileMode:         "0644",
+			Symlinks:         "preserve",
+			Jobs:             "4",
+			LineEndings:      "crlf",
+			Compare:          "exact",
+		},
+		DefaultProfile: "backend",
+	}
+	if diff := cmp.Diff(expected, env); diff != "" {
+		t.Errorf("Env config mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfigLoadEnvInvalidBool(t *testing.T) {
+	t.Setenv(config.EnvGitWithoutPush, "maybe")
+
+	repo := config.NewConfigRepository()
+	if _, err := repo.LoadEnv(); err == nil {
+		t.Error("Expected error for invalid boolean value")
+	}
+}
+
+func TestConfigUnset(t *testing.T) {
+	repo := config.NewConfigRepository()
+	cfg := &config.Config{RulesDir: "/test/rules", GitWithoutPush: true}
+
+	if err := repo.Unset(cfg, "rules_dir"); err != nil {
+		t.Fatalf("Failed to unset rules_dir: %v", err)
+	}
+	if err := repo.Unset(cfg, "git-without-push"); err != nil {
+		t.Fatalf("Failed to unset git-without-push: %v", err)
+	}
+	if diff := cmp.Diff(&config.Config{}, cfg); diff != "" {
+		t.Errorf("Config mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := repo.Unset(cfg, "invalid-key"); err == nil {
+		t.Error("Expected error for invalid key")
+	}
+}
+
+func TestConfigSetInvalidType(t *testing.T) {
+	repo := config.NewConfigRepository()
+	cfg := &config.Config{}
+
+	if err := repo.Set(cfg, "overwrite_headers", "true"); err == nil {
+		t.Error("Expected error for string value of bool key")
+	}
+	if err := repo.Set(cfg, "rules_dir", true); err == nil {
+		t.Error("Expected error for bool value of string key")
+	}
+	if err := repo.SetProfile(cfg, "backend", "default_profile", "frontend"); err == nil {
+		t.Error("Expected error for key that cannot be set within a profile")
+	}
+}
+
+func TestConfigUnsetProfile(t *testing.T) {
+	repo := config.NewConfigRepository()
+	overwriteHeaders := false
+	cfg := &config.Config{
+		Profiles: map[string]*config.Profile{
+			"backend": {Overrides: config.Overrides{RulesDir: "/backend/rules", OverwriteHeaders: &overwriteHeaders}},
+		},
+	}
+
+	if err := repo.UnsetProfile(cfg, "backend", "overwrite_headers"); err != nil {
+		t.Fatalf("Failed to unset overwrite_headers in profile: %v", err)
+	}
+	expected := map[string]*config.Profile{
+		"backend": {Overrides: config.Overrides{RulesDir: "/backend/rules"}},
+	}
+	if diff := cmp.Diff(expected, cfg.Profiles); diff != "" {
+		t.Errorf("Profiles mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := repo.UnsetProfile(cfg, "frontend", "rules_dir"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	rulesDir := t.TempDir()
+	repo := config.NewConfigRepository()
+
+	valid := &config.Config{
+		RulesDir:       rulesDir,
+		FilePatterns:   []string{"*.mdc", "docs/*.{md,txt}"},
+		DefaultProfile: "backend",
+		Profiles: map[string]*config.Profile{
+			"backend": {Overrides: config.Overrides{RulesDir: rulesDir}},
+		},
+	}
+	if err := repo.Validate(valid); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+
+	invalid := &config.Config{
+		RulesDir:       filepath.Join(rulesDir, "missing"),
+		FilePatterns:   []string{"[a"},
+		Push:           config.Scope{Exclude: []string{"{x,[c}"}},
+		BackupCount:    "-1",
+		BackupMaxAge:   "month",
+		DefaultProfile: "frontend",
+		Profiles: map[string]*config.Profile{
+			"backend": {Overrides: config.Overrides{FilePatterns: []string{"*.mdc", "[b"}, Delete: "some", FileMode: "rwx", Symlinks: "copy", Jobs: "0", LineEndings: "cr", Compare: "loose"}},
+		},
+	}
+	err := repo.Validate(invalid)
+	if err == nil {
+		t.Fatal("Expected error for invalid config")
+	}
+	for _, want := range []string{"rules_dir", "file_patterns", "push.exclude", "profile.backend.file_patterns", "profile.backend.delete", "profile.backend.file_mode", "profile.backend.symlinks", "profile.backend.jobs", "profile.backend.line_endings", "profile.backend.compare", "backup_count", "backup_max_age", "default_profile"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %s, got %v", want, err)
+		}
+	}
+}
+
+func TestParseBool(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+		wantErr  bool
+	}{
+		{input: "true", expected: true},
+		{input: "TRUE", expected: true},
+		{input: "1", expected: true},
+		{input: "false", expected: false},
+		{input: "False", expected: false},
+		{input: " 0 ", expected: false},
+		{input: "", wantErr: true},
+		{input: "yes", wantErr: true},
+		{input: "maybe", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := config.ParseBool(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseBool(%q) expected error", tt.input)
+			}
+			continue
+		}
+		if err != nil || got != tt.expected {
+			t.Errorf("ParseBool(%q) = %v, %v, want %v", tt.input, got, err, tt.expected)
+		}
+	}
+}
+
+func TestLookupKey(t *testing.T) {
+	key, err := config.LookupKey("overwrite-headers")
+	if err != nil {
+		t.Fatalf("Failed to lookup key: %v", err)
+	}
+	if key.Name != "overwrite_headers" || key.Type != config.ValueTypeBool {
+		t.Errorf("Unexpected key %+v", key)
+	}
+
+	if _, err := config.LookupKey("unknown"); err == nil {
+		t.Error("Expected error for unknown key")
+	}
+}
+
+func TestConfigLoadMigratesOldVersion(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "cursync.toml")
+	original := "rules-dir = \"/path/to/rules\"\nfile_patterns = \"*.mdc, docs/*.{md,mdc}\"\n\n[profile.backend]\ngit-without-push = true\n"
+	if err := os.WriteFile(configPath, []byte(original), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	repo := config.NewConfigRepository()
+	repo.SetConfigPath(configPath)
+
+	cfg, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	gitWithoutPush := true
+	expected := &config.Config{
+		Version:      config.CurrentVersion,
+		RulesDir:     "/path/to/rules",
+		FilePatterns: []string{"*.mdc", "docs/*.{md,mdc}"},
+		Profiles: map[string]*config.Profile{
+			"backend": {Overrides: config.Overrides{GitWithoutPush: &gitWithoutPush}},
+		},
+		Migration: &config.Migration{
+			FromVersion: 0,
+			ToVersion:   config.CurrentVersion,
+			BackupPath:  configPath + ".v0.bak",
+		},
+	}
+	if diff := cmp.Diff(expected, cfg); diff != "" {
+		t.Errorf("Config mismatch (-want +got):\n%s", diff)
+	}
+
+	backup, err := os.ReadFile(configPath + ".v0.bak")
+	if err != nil {
+		t.Fatalf("Failed to read backup: %v", err)
+	}
+	if string(backup) != original {
+		t.Errorf("Expected backup to contain original config, got %q", backup)
+	}
+
+	// Migrated config is saved, so next load doesn't migrate again
+	reloaded, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	expected.Migration = nil
+	if diff := cmp.Diff(expected, reloaded); diff != "" {
+		t.Errorf("Reloaded config mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfigLoadMigratesFlatKeysToProfile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "cursync.toml")
+	original := "version = 2\nrules_dir = \"/path/to/rules\"\ngit_without_push = true\nteam = \"platform\"\n\n[push]\nexclude = [\"draft_*\"]\n"
+	if err := os.WriteFile(configPath, []byte(original), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	repo := config.NewConfigRepository()
+	repo.SetConfigPath(configPath)
+
+	cfg, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	gitWithoutPush := true
+	expected := &config.Config{
+		Version:        config.CurrentVersion,
+		DefaultProfile: "default",
+		Profiles: map[string]*config.Profile{
+			"default": {Overrides: config.Overrides{
+				RulesDir:       "/path/to/rules",
+				GitWithoutPush: &gitWithoutPush,
+				Push:           config.Scope{Exclude: []string{"draft_*"}},
+			}},
+		},
+		Migration: &config.Migration{
+			FromVersion: 2,
+			ToVersion:   config.CurrentVersion,
+			BackupPath:  configPath + ".v2.bak",
+		},
+	}
+	if diff := cmp.Diff(expected, cfg); diff != "" {
+		t.Errorf("Config mismatch (-want +got):\n%s", diff)
+	}
+
+	// Keys unknown to cursync survive migration
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if !strings.Contains(string(data), "team = 'platform'") {
+		t.Errorf("Expected unknown key to be kept, got %q", data)
+	}
+}
+
+func TestConfigLoadProjectMigratesPatternString(t *testing.T) {
+	projectRoot := t.TempDir()
+	content := "file_patterns = \"*.mdc,docs/*\"\n"
+	if err := os.WriteFile(filepath.Join(projectRoot, ".cursync.toml"), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+
+	repo := config.NewConfigRepository()
+	projectCfg, err := repo.LoadProject(projectRoot)
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+
+	expected := &config.ProjectConfig{Overrides: config.Overrides{FilePatterns: []string{"*.mdc", "docs/*"}}}
+	if diff := cmp.Diff(expected, projectCfg); diff != "" {
+		t.Errorf("Project config mismatch (-want +got):\n%s", diff)
+	}
+
+	// Project config is migrated in memory only
+	data, err := os.ReadFile(filepath.Join(projectRoot, ".cursync.toml"))
+	if err != nil {
+		t.Fatalf("Failed to read project config: %v", err)
+	}
+	if string(data) != content {
+		t.Errorf("Expected project config to be unchanged, got %q", data)
+	}
+}
+
+func TestKeyParseValueList(t *testing.T) {
+	key, err := config.LookupKey("push.include")
+	if err != nil {
+		t.Fatalf("Failed to lookup key: %v", err)
+	}
+
+	value, err := key.ParseValue("team/**, *.{md,mdc}")
+	if err != nil {
+		t.Fatalf("Failed to parse list: %v", err)
+	}
+	if diff := cmp.Diff([]string{"team/**", "*.{md,mdc}"}, value); diff != "" {
+		t.Errorf("List mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := key.ParseValue("[a"); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestKeyParseValueDelete(t *testing.T) {
+	key, err := config.LookupKey("delete")
+	if err != nil {
+		t.Fatalf("Failed to lookup key: %v", err)
+	}
+
+	for _, value := range []string{"all", "none", "managed"} {
+		if _, err := key.ParseValue(value); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", value, err)
+		}
+	}
+	if _, err := key.ParseValue("some"); err == nil {
+		t.Error("Expected error for unknown delete mode")
+	}
+}
+
+func TestKeyParseValueFileMode(t *testing.T) {
+	key, err := config.LookupKey("file_mode")
+	if err != nil {
+		t.Fatalf("Failed to lookup key: %v", err)
+	}
+
+	for _, value := range []string{"preserve", "0644", "755"} {
+		if _, err := key.ParseValue(value); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", value, err)
+		}
+	}
+	for _, value := range []string{"rwx", "0", "0999", "1777"} {
+		if _, err := key.ParseValue(value); err == nil {
+			t.Errorf("Expected error for file mode %s", value)
+		}
+	}
+}
+
+func TestKeyParseValueSymlinks(t *testing.T) {
+	key, err := config.LookupKey("symlinks")
+	if err != nil {
+		t.Fatalf("Failed to lookup key: %v", err)
+	}
+
+	for _, value := range []string{"follow", "preserve", "skip"} {
+		if _, err := key.ParseValue(value); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", value, err)
+		}
+	}
+	if _, err := key.ParseValue("copy"); err == nil {
+		t.Error("Expected error for unknown symlink mode")
+	}
+}
+
+func TestKeyParseValueJobs(t *testing.T) {
+	key, err := config.LookupKey("jobs")
+	if err != nil {
+		t.Fatalf("Failed to lookup key: %v", err)
+	}
+
+	for _, value := range []string{"auto", "1", "16"} {
+		if _, err := key.ParseValue(value); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", value, err)
+		}
+	}
+	for _, value := range []string{"0", "-2", "many"} {
+		if _, err := key.ParseValue(value); err == nil {
+			t.Errorf("Expected error for jobs %s", value)
+		}
+	}
+}
+
+func TestKeyParseValueLineEndings(t *testing.T) {
+	key, err := config.LookupKey("line_endings")
+	if err != nil {
+		t.Fatalf("Failed to lookup key: %v", err)
+	}
+
+	for _, value := range []string{"lf", "crlf", "native", "preserve"} {
+		if _, err := key.ParseValue(value); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", value, err)
+		}
+	}
+	if _, err := key.ParseValue("cr"); err == nil {
+		t.Error("Expected error for unknown line endings")
+	}
+}
+
+func TestKeyParseValueCompare(t *testing.T) {
+	key, err := config.LookupKey("compare")
+	if err != nil {
+		t.Fatalf("Failed to lookup key: %v", err)
+	}
+
+	for _, value := range []string{"normalized", "exact"} {
+		if _, err := key.ParseValue(value); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", value, err)
+		}
+	}
+	if _, err := key.ParseValue("loose"); err == nil {
+		t.Error("Expected error for unknown compare mode")
+	}
+}
+
+func TestKeyParseValueBackupLimits(t *testing.T) {
+	count, err := config.LookupKey("backup_count")
+	if err != nil {
+		t.Fatalf("Failed to lookup key: %v", err)
+	}
+	for _, value := range []string{"0", "5"} {
+		if _, err := count.ParseValue(value); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", value, err)
+		}
+	}
+	if _, err := count.ParseValue("-1"); err == nil {
+		t.Error("Expected error for negative backup count")
+	}
+
+	age, err := config.LookupKey("backup-max-age")
+	if err != nil {
+		t.Fatalf("Failed to lookup key: %v", err)
+	}
+	for _, value := range []string{"0", "7d", "36h"} {
+		if _, err := age.ParseValue(value); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", value, err)
+		}
+	}
+	for _, value := range []string{"month", "-2d", "-1h"} {
+		if _, err := age.ParseValue(value); err == nil {
+			t.Errorf("Expected error for backup age %s", value)
+		}
+	}
+}
+
+func TestKeyDefaultValue(t *testing.T) {
+	for _, key := range config.Keys() {
+		value := key.DefaultValue()
+		switch key.Type {
+		case config.ValueTypeBool:
+			if value != false {
+				t.Errorf("Expected false default for %s, got %v", key.Name, value)
+			}
+		case config.ValueTypeString:
+			if key.Default == "" {
+				continue
+			}
+			if _, err := key.ParseValue(key.Default); err != nil {
+				t.Errorf("Expected default of %s to be valid, got %v", key.Name, err)
+			}
+		}
+	}
+
+	key, err := config.LookupKey("jobs")
+	if err != nil {
+		t.Fatalf("Failed to lookup key: %v", err)
+	}
+	if diff := cmp.Diff("auto", key.DefaultValue()); diff != "" {
+		t.Errorf("Default mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfigLoadUnsupportedVersion(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "cursync.toml")
+	if err := os.WriteFile(configPath, []byte("version = 99\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	repo := config.NewConfigRepository()
+	repo.SetConfigPath(configPath)
+
+	if _, err := repo.Load(); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Expected unsupported version error, got %v", err)
+	}
+}
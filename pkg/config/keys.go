@@ -0,0 +1,453 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/string_utils"
+)
+
+// ValueType describes type of configuration value
+type ValueType int
+
+// Value types
+const (
+	ValueTypeString ValueType = iota
+	ValueTypeBool
+	ValueTypeList
+)
+
+// String returns name of value type
+func (t ValueType) String() string {
+	switch t {
+	case ValueTypeBool:
+		return "bool"
+	case ValueTypeList:
+		return "list"
+	default:
+		return "string"
+	}
+}
+
+// Key describes a configuration key
+type Key struct {
+	Name  string
+	Type  ValueType
+	Usage string
+	// Env is environment variable overriding the key
+	Env string
+	// Profile reports if key can be set within a profile
+	Profile bool
+	// Default is value of string key used when it is set nowhere
+	Default string
+
+	check       func(value interface{}) error
+	get         func(cfg *Config) interface{}
+	set         func(cfg *Config, value interface{})
+	getOverride func(o *Overrides) (interface{}, bool)
+	setOverride func(o *Overrides, value interface{})
+}
+
+// keys holds all configuration keys in display order
+var keys = []Key{
+	{
+		Name:    "rules_dir",
+		Type:    ValueTypeString,
+		Usage:   "Path to rules directory",
+		Env:     EnvRulesDir,
+		Profile: true,
+		get:     func(cfg *Config) interface{} { return cfg.RulesDir },
+		set:     func(cfg *Config, value interface{}) { cfg.RulesDir, _ = value.(string) },
+		getOverride: func(o *Overrides) (interface{}, bool) {
+			return o.RulesDir, o.RulesDir != ""
+		},
+		setOverride: func(o *Overrides, value interface{}) { o.RulesDir, _ = value.(string) },
+	},
+	{
+		Name:        "file_patterns",
+		Type:        ValueTypeList,
+		Usage:       "File patterns to sync in both directions",
+		Env:         EnvFilePatterns,
+		Profile:     true,
+		check:       checkPatterns,
+		get:         func(cfg *Config) interface{} { return cfg.FilePatterns },
+		set:         func(cfg *Config, value interface{}) { cfg.FilePatterns, _ = value.([]string) },
+		getOverride: func(o *Overrides) (interface{}, bool) { return o.FilePatterns, len(o.FilePatterns) > 0 },
+		setOverride: func(o *Overrides, value interface{}) { o.FilePatterns, _ = value.([]string) },
+	},
+	{
+		Name:        "pull.include",
+		Type:        ValueTypeList,
+		Usage:       "File patterns to pull, replace file_patterns",
+		Env:         EnvPullInclude,
+		Profile:     true,
+		check:       checkPatterns,
+		get:         func(cfg *Config) interface{} { return cfg.Pull.Include },
+		set:         func(cfg *Config, value interface{}) { cfg.Pull.Include, _ = value.([]string) },
+		getOverride: func(o *Overrides) (interface{}, bool) { return o.Pull.Include, len(o.Pull.Include) > 0 },
+		setOverride: func(o *Overrides, value interface{}) { o.Pull.Include, _ = value.([]string) },
+	},
+	{
+		Name:        "pull.exclude",
+		Type:        ValueTypeList,
+		Usage:       "File patterns excluded from pull",
+		Env:         EnvPullExclude,
+		Profile:     true,
+		check:       checkPatterns,
+		get:         func(cfg *Config) interface{} { return cfg.Pull.Exclude },
+		set:         func(cfg *Config, value interface{}) { cfg.Pull.Exclude, _ = value.([]string) },
+		getOverride: func(o *Overrides) (interface{}, bool) { return o.Pull.Exclude, len(o.Pull.Exclude) > 0 },
+		setOverride: func(o *Overrides, value interface{}) { o.Pull.Exclude, _ = value.([]string) },
+	},
+	{
+		Name:        "push.include",
+		Type:        ValueTypeList,
+		Usage:       "File patterns to push, replace file_patterns",
+		Env:         EnvPushInclude,
+		Profile:     true,
+		check:       checkPatterns,
+		get:         func(cfg *Config) interface{} { return cfg.Push.Include },
+		set:         func(cfg *Config, value interface{}) { cfg.Push.Include, _ = value.([]string) },
+		getOverride: func(o *Overrides) (interface{}, bool) { return o.Push.Include, len(o.Push.Include) > 0 },
+		setOverride: func(o *Overrides, value interface{}) { o.Push.Include, _ = value.([]string) },
+	},
+	{
+		Name:        "push.exclude",
+		Type:        ValueTypeList,
+		Usage:       "File patterns excluded from push",
+		Env:         EnvPushExclude,
+		Profile:     true,
+		check:       checkPatterns,
+		get:         func(cfg *Config) interface{} { return cfg.Push.Exclude },
+		set:         func(cfg *Config, value interface{}) { cfg.Push.Exclude, _ = value.([]string) },
+		getOverride: func(o *Overrides) (interface{}, bool) { return o.Push.Exclude, len(o.Push.Exclude) > 0 },
+		setOverride: func(o *Overrides, value interface{}) { o.Push.Exclude, _ = value.([]string) },
+	},
+	{
+		Name:    "overwrite_headers",
+		Type:    ValueTypeBool,
+		Usage:   "Overwrite headers instead of preserving them",
+		Env:     EnvOverwriteHeaders,
+		Profile: true,
+		get:     func(cfg *Config) interface{} { return cfg.OverwriteHeaders },
+		set:     func(cfg *Config, value interface{}) { cfg.OverwriteHeaders, _ = value.(bool) },
+		getOverride: func(o *Overrides) (interface{}, bool) {
+			return derefBool(o.OverwriteHeaders), o.OverwriteHeaders != nil
+		},
+		setOverride: func(o *Overrides, value interface{}) { o.OverwriteHeaders = optionalBool(value) },
+	},
+	{
+		Name:    "git_without_push",
+		Type:    ValueTypeBool,
+		Usage:   "Commit changes but don't push to remote",
+		Env:     EnvGitWithoutPush,
+		Profile: true,
+		get:     func(cfg *Config) interface{} { return cfg.GitWithoutPush },
+		set:     func(cfg *Config, value interface{}) { cfg.GitWithoutPush, _ = value.(bool) },
+		getOverride: func(o *Overrides) (interface{}, bool) {
+			return derefBool(o.GitWithoutPush), o.GitWithoutPush != nil
+		},
+		setOverride: func(o *Overrides, value interface{}) { o.GitWithoutPush = optionalBool(value) },
+	},
+	{
+		Name:        "delete",
+		Type:        ValueTypeString,
+		Usage:       "Which destination files missing in source are deleted: all, none or managed",
+		Env:         EnvDelete,
+		Profile:     true,
+		Default:     string(models.DefaultDeleteMode),
+		check:       checkDeleteMode,
+		get:         func(cfg *Config) interface{} { return cfg.Delete },
+		set:         func(cfg *Config, value interface{}) { cfg.Delete, _ = value.(string) },
+		getOverride: func(o *Overrides) (interface{}, bool) { return o.Delete, o.Delete != "" },
+		setOverride: func(o *Overrides, value interface{}) { o.Delete, _ = value.(string) },
+	},
+	{
+		Name:        "file_mode",
+		Type:        ValueTypeString,
+		Usage:       "Permissions of copied files: preserve source mode and modification time, or octal mode like 0644",
+		Env:         EnvFileMode,
+		Profile:     true,
+		Default:     "preserve",
+		check:       checkFileMode,
+		get:         func(cfg *Config) interface{} { return cfg.FileMode },
+		set:         func(cfg *Config, value interface{}) { cfg.FileMode, _ = value.(string) },
+		getOverride: func(o *Overrides) (interface{}, bool) { return o.FileMode, o.FileMode != "" },
+		setOverride: func(o *Overrides, value interface{}) { o.FileMode, _ = value.(string) },
+	},
+	{
+		Name:        "symlinks",
+		Type:        ValueTypeString,
+		Usage:       "How symlinks in source directory are synced: follow, preserve or skip",
+		Env:         EnvSymlinks,
+		Profile:     true,
+		Default:     string(models.SymlinkFollow),
+		check:       checkSymlinkMode,
+		get:         func(cfg *Config) interface{} { return cfg.Symlinks },
+		set:         func(cfg *Config, value interface{}) { cfg.Symlinks, _ = value.(string) },
+		getOverride: func(o *Overrides) (interface{}, bool) { return o.Symlinks, o.Symlinks != "" },
+		setOverride: func(o *Overrides, value interface{}) { o.Symlinks, _ = value.(string) },
+	},
+	{
+		Name:        "jobs",
+		Type:        ValueTypeString,
+		Usage:       "Number of files compared and copied in parallel: auto for one per CPU, or a positive number",
+		Env:         EnvJobs,
+		Profile:     true,
+		Default:     "auto",
+		check:       checkJobs,
+		get:         func(cfg *Config) interface{} { return cfg.Jobs },
+		set:         func(cfg *Config, value interface{}) { cfg.Jobs, _ = value.(string) },
+		getOverride: func(o *Overrides) (interface{}, bool) { return o.Jobs, o.Jobs != "" },
+		setOverride: func(o *Overrides, value interface{}) { o.Jobs, _ = value.(string) },
+	},
+	{
+		Name:        "line_endings",
+		Type:        ValueTypeString,
+		Usage:       "Line endings of copied text files: lf, crlf, native or preserve",
+		Env:         EnvLineEndings,
+		Profile:     true,
+		Default:     string(models.LineEndingsLF),
+		check:       checkLineEndings,
+		get:         func(cfg *Config) interface{} { return cfg.LineEndings },
+		set:         func(cfg *Config, value interface{}) { cfg.LineEndings, _ = value.(string) },
+		getOverride: func(o *Overrides) (interface{}, bool) { return o.LineEndings, o.LineEndings != "" },
+		setOverride: func(o *Overrides, value interface{}) { o.LineEndings, _ = value.(string) },
+	},
+	{
+		Name:        "compare",
+		Type:        ValueTypeString,
+		Usage:       "How text files are compared: normalized ignores line endings and trailing whitespace, exact compares bytes",
+		Env:         EnvCompare,
+		Profile:     true,
+		Default:     string(models.CompareNormalized),
+		check:       checkCompareMode,
+		get:         func(cfg *Config) interface{} { return cfg.Compare },
+		set:         func(cfg *Config, value interface{}) { cfg.Compare, _ = value.(string) },
+		getOverride: func(o *Overrides) (interface{}, bool) { return o.Compare, o.Compare != "" },
+		setOverride: func(o *Overrides, value interface{}) { o.Compare, _ = value.(string) },
+	},
+	{
+		Name:    "backup_count",
+		Type:    ValueTypeString,
+		Usage:   "Number of backups kept per project, 0 keeps all",
+		Default: "20",
+		check:   checkBackupCount,
+		get:     func(cfg *Config) interface{} { return cfg.BackupCount },
+		set:     func(cfg *Config, value interface{}) { cfg.BackupCount, _ = value.(string) },
+	},
+	{
+		Name:    "backup_max_age",
+		Type:    ValueTypeString,
+		Usage:   "Age after which backups are pruned, in days like 30d or as duration like 12h, 0 keeps all",
+		Default: "30d",
+		check:   checkBackupAge,
+		get:     func(cfg *Config) interface{} { return cfg.BackupMaxAge },
+		set:     func(cfg *Config, value interface{}) { cfg.BackupMaxAge, _ = value.(string) },
+	},
+	{
+		Name:  "default_profile",
+		Type:  ValueTypeString,
+		Usage: "Profile used when --profile is not given",
+		Env:   EnvDefaultProfile,
+		get:   func(cfg *Config) interface{} { return cfg.DefaultProfile },
+		set:   func(cfg *Config, value interface{}) { cfg.DefaultProfile, _ = value.(string) },
+	},
+}
+
+// Keys returns all configuration keys
+func Keys() []Key {
+	return append([]Key(nil), keys...)
+}
+
+// LookupKey returns configuration key by name, dashes are accepted in place of underscores
+func LookupKey(name string) (Key, error) {
+	normalized := strings.ReplaceAll(strings.TrimSpace(name), "-", "_")
+	for _, key := range keys {
+		if key.Name == normalized {
+			return key, nil
+		}
+	}
+	return Key{}, fmt.Errorf("unknown config key: %s", name)
+}
+
+// ParseValue parses string value according to key type, list values are comma-separated
+// with commas inside braces kept, e.g. "*.{md,mdc},docs/*"
+func (k Key) ParseValue(value string) (interface{}, error) {
+	var parsed interface{}
+	switch k.Type {
+	case ValueTypeBool:
+		val, err := ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", k.Name, err)
+		}
+		parsed = val
+	case ValueTypeList:
+		parsed = ParseList(value)
+	default:
+		parsed = value
+	}
+
+	if k.check != nil {
+		if err := k.check(parsed); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", k.Name, err)
+		}
+	}
+	return parsed, nil
+}
+
+// FormatValue formats value of the key for output
+func (k Key) FormatValue(value interface{}) string {
+	if list, ok := value.([]string); ok {
+		return strings.Join(list, ", ")
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// IsSet checks if value differs from zero value of the key type
+func (k Key) IsSet(value interface{}) bool {
+	switch val := value.(type) {
+	case string:
+		return val != ""
+	case bool:
+		return val
+	case []string:
+		return len(val) > 0
+	default:
+		return value != nil
+	}
+}
+
+// Get returns value of the key in global configuration
+func (k Key) Get(cfg *Config) interface{} {
+	return k.get(cfg)
+}
+
+// DefaultValue returns value of the key used when it is set nowhere
+func (k Key) DefaultValue() interface{} {
+	switch k.Type {
+	case ValueTypeBool:
+		return false
+	case ValueTypeList:
+		return []string(nil)
+	default:
+		return k.Default
+	}
+}
+
+// GetOverride returns value of the key within overrides and reports if it is set
+func (k Key) GetOverride(o *Overrides) (interface{}, bool) {
+	if k.getOverride == nil {
+		return nil, false
+	}
+	return k.getOverride(o)
+}
+
+// DisplayName returns name of the key as shown to the user
+func (k Key) DisplayName() string {
+	return strings.ReplaceAll(k.Name, "_", "-")
+}
+
+// ParseList splits comma-separated list keeping commas inside braces
+func ParseList(value string) []string {
+	return string_utils.SplitOutsideBraces(value, ',')
+}
+
+// ParseBool parses boolean value, accepts only "true", "false", "1" and "0" in any case
+func ParseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "1":
+		return true, nil
+	case "false", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid boolean value %q, use true, false, 1 or 0", value)
+	}
+}
+
+// checkType checks that value matches type of the key
+func (k Key) checkType(value interface{}) error {
+	switch value.(type) {
+	case string:
+		if k.Type == ValueTypeString {
+			return nil
+		}
+	case bool:
+		if k.Type == ValueTypeBool {
+			return nil
+		}
+	case []string:
+		if k.Type == ValueTypeList {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid %s value %v for %s", k.Type, value, k.Name)
+}
+
+// checkPatterns checks that list value contains valid patterns
+func checkPatterns(value interface{}) error {
+	patterns, _ := value.([]string)
+	return ValidatePatterns(patterns)
+}
+
+// checkDeleteMode checks that value is a known delete mode
+func checkDeleteMode(value interface{}) error {
+	mode, _ := value.(string)
+	_, err := models.ParseDeleteMode(mode)
+	return err
+}
+
+// checkFileMode checks that value is preserve or octal permissions
+func checkFileMode(value interface{}) error {
+	mode, _ := value.(string)
+	_, err := models.ParseFileMode(mode)
+	return err
+}
+
+// checkSymlinkMode checks that value is a known symlink mode
+func checkSymlinkMode(value interface{}) error {
+	mode, _ := value.(string)
+	_, err := models.ParseSymlinkMode(mode)
+	return err
+}
+
+// checkJobs checks that value is auto or a positive number
+func checkJobs(value interface{}) error {
+	jobs, _ := value.(string)
+	_, err := models.ParseJobs(jobs)
+	return err
+}
+
+// checkLineEndings checks that value is a known line endings policy
+func checkLineEndings(value interface{}) error {
+	lineEndings, _ := value.(string)
+	_, err := models.ParseLineEndings(lineEndings)
+	return err
+}
+
+// checkCompareMode checks that value is a known compare mode
+func checkCompareMode(value interface{}) error {
+	mode, _ := value.(string)
+	_, err := models.ParseCompareMode(mode)
+	return err
+}
+
+// checkBackupCount checks that value is a non-negative number
+func checkBackupCount(value interface{}) error {
+	count, _ := value.(string)
+	_, err := models.ParseBackupCount(count)
+	return err
+}
+
+// checkBackupAge checks that value is a number of days or a duration
+func checkBackupAge(value interface{}) error {
+	age, _ := value.(string)
+	_, err := models.ParseBackupAge(age)
+	return err
+}
+
+// derefBool returns value of optional bool or false
+func derefBool(value *bool) bool {
+	return value != nil && *value
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CurrentVersion is version of config schema written by this version of cursync
+const CurrentVersion = 3
+
+const versionKey = "version"
+
+// migratedProfile is profile that values of a config written before profiles existed are moved to
+const migratedProfile = "default"
+
+// Migration describes upgrade of the config file performed on load
+type Migration struct {
+	FromVersion int
+	ToVersion   int
+	BackupPath  string
+}
+
+// migration upgrades decoded config of the previous version in place
+type migration struct {
+	upgrade func(raw map[string]interface{}) error
+	// globalOnly reports that project configs, which have no profiles, are left as is
+	globalOnly bool
+}
+
+// migrations maps schema version to migration upgrading config to it
+var migrations = map[int]migration{
+	1: {upgrade: migrateDashedKeys},
+	2: {upgrade: migratePatternLists},
+	3: {upgrade: migrateFlatKeysToProfile, globalOnly: true},
+}
+
+// migrate upgrades decoded global or project config to the current version and returns version of the original config
+func migrate(raw map[string]interface{}, global bool) (int, error) {
+	fromVersion, err := rawVersion(raw)
+	if err != nil {
+		return 0, err
+	}
+	if fromVersion > CurrentVersion {
+		return 0, fmt.Errorf("config version %d is not supported, latest supported version is %d, upgrade cursync", fromVersion, CurrentVersion)
+	}
+
+	for version := fromVersion + 1; version <= CurrentVersion; version++ {
+		m, ok := migrations[version]
+		if !ok {
+			return 0, fmt.Errorf("no migration to config version %d", version)
+		}
+		if m.globalOnly && !global {
+			continue
+		}
+		if err := m.upgrade(raw); err != nil {
+			return 0, fmt.Errorf("failed to migrate config to version %d: %w", version, err)
+		}
+	}
+
+	raw[versionKey] = int64(CurrentVersion)
+	return fromVersion, nil
+}
+
+// rawVersion returns schema version of decoded config, config without version is version 0
+func rawVersion(raw map[string]interface{}) (int, error) {
+	value, ok := raw[versionKey]
+	if !ok {
+		return 0, nil
+	}
+
+	version, ok := value.(int64)
+	if !ok || version < 0 {
+		return 0, fmt.Errorf("invalid config version %v", value)
+	}
+	return int(version), nil
+}
+
+// migratePatternLists converts comma-separated file_patterns strings to lists
+// at top level and within profiles
+func migratePatternLists(raw map[string]interface{}) error {
+	return forEachTable(raw, func(table map[string]interface{}) {
+		if patterns, ok := table["file_patterns"].(string); ok {
+			list := []interface{}{}
+			for _, pattern := range ParseList(patterns) {
+				list = append(list, pattern)
+			}
+			table["file_patterns"] = list
+		}
+	})
+}
+
+// forEachTable calls fn for top level table and table of each profile
+func forEachTable(raw map[string]interface{}, fn func(table map[string]interface{})) error {
+	fn(raw)
+
+	profiles, ok := raw["profile"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for _, profile := range profiles {
+		if values, ok := profile.(map[string]interface{}); ok {
+			fn(values)
+		}
+	}
+	return nil
+}
+
+// migrateDashedKeys renames keys written with dashes, as accepted by cfg commands, to TOML key names
+// at top level and within profiles; unknown keys are left as is
+func migrateDashedKeys(raw map[string]interface{}) error {
+	return forEachTable(raw, renameDashedKeys)
+}
+
+// renameDashedKeys renames known dashed keys of a table unless the TOML key name is already set
+func renameDashedKeys(table map[string]interface{}) {
+	for name, value := range table {
+		if !strings.Contains(name, "-") {
+			continue
+		}
+
+		key, err := LookupKey(name)
+		if err != nil {
+			continue
+		}
+		if _, exists := table[key.Name]; !exists {
+			table[key.Name] = value
+		}
+		delete(table, name)
+	}
+}
+
+// migrateFlatKeysToProfile moves values of a config without profiles into the default profile selected by
+// default_profile, so a config written before profiles existed becomes a profile with the same effective values
+func migrateFlatKeysToProfile(raw map[string]interface{}) error {
+	if _, ok := raw["profile"]; ok {
+		return nil
+	}
+	if _, ok := raw["default_profile"]; ok {
+		return nil
+	}
+
+	profile := map[string]interface{}{}
+	for _, key := range keys {
+		if !key.Profile {
+			continue
+		}
+		name, _, _ := strings.Cut(key.Name, ".")
+		if value, ok := raw[name]; ok {
+			profile[name] = value
+			delete(raw, name)
+		}
+	}
+	if len(profile) == 0 {
+		return nil
+	}
+
+	raw["profile"] = map[string]interface{}{migratedProfile: profile}
+	raw["default_profile"] = migratedProfile
+	return nil
+}
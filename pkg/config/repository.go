@@ -4,163 +4,413 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pelletier/go-toml/v2"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
 )
 
 const (
-	configDirName  = ".config"
-	configFileName = "cursync.toml"
+	configDirName         = ".config"
+	configFileName        = "cursync.toml"
+	projectConfigFileName = ".cursync.toml"
+)
+
+// Environment variables locating config file
+const (
+	EnvConfigPath    = "CURSYNC_CONFIG"
+	EnvXDGConfigHome = "XDG_CONFIG_HOME"
+)
+
+// Environment variables overriding config keys
+const (
+	EnvRulesDir         = "CURSYNC_RULES_DIR"
+	EnvFilePatterns     = "CURSYNC_FILE_PATTERNS"
+	EnvPullInclude      = "CURSYNC_PULL_INCLUDE"
+	EnvPullExclude      = "CURSYNC_PULL_EXCLUDE"
+	EnvPushInclude      = "CURSYNC_PUSH_INCLUDE"
+	EnvPushExclude      = "CURSYNC_PUSH_EXCLUDE"
+	EnvOverwriteHeaders = "CURSYNC_OVERWRITE_HEADERS"
+	EnvGitWithoutPush   = "CURSYNC_GIT_WITHOUT_PUSH"
+	EnvDelete           = "CURSYNC_DELETE"
+	EnvFileMode         = "CURSYNC_FILE_MODE"
+	EnvSymlinks         = "CURSYNC_SYMLINKS"
+	EnvJobs             = "CURSYNC_JOBS"
+	EnvLineEndings      = "CURSYNC_LINE_ENDINGS"
+	EnvCompare          = "CURSYNC_COMPARE"
+	EnvDefaultProfile   = "CURSYNC_DEFAULT_PROFILE"
 )
 
 // ConfigRepositoryInterface defines interface for config repository
 type ConfigRepositoryInterface interface {
+	GetConfigPath() (string, error)
+	GetProjectConfigPath(projectRoot string) string
 	Load() (*Config, error)
-	LoadOrDefault() *Config
+	LoadFile(configPath string) (*Config, error)
+	LoadProject(projectRoot string) (*ProjectConfig, error)
+	LoadEnv() (*EnvConfig, error)
 	Save(cfg *Config) error
+	SaveRaw(data []byte) error
 	Set(cfg *Config, key string, value interface{}) error
+	Unset(cfg *Config, key string) error
 	Get(cfg *Config, key string) (interface{}, error)
 	GetAll(cfg *Config) map[string]interface{}
+	SetProfile(cfg *Config, profile, key string, value interface{}) error
+	UnsetProfile(cfg *Config, profile, key string) error
+	GetAllProfile(cfg *Config, profile string) (map[string]interface{}, error)
+	Validate(cfg *Config) error
+	ValidateProject(projectCfg *ProjectConfig) error
 }
 
 // ConfigRepository handles loading and saving configuration
-type ConfigRepository struct{}
+type ConfigRepository struct {
+	configPath string
+}
 
 // NewConfigRepository creates a new ConfigRepository
 func NewConfigRepository() *ConfigRepository {
 	return &ConfigRepository{}
 }
 
-// GetConfigPath returns the path to the config file
+// SetConfigPath sets path to the config file overriding CURSYNC_CONFIG and default location,
+// empty path restores default behaviour
+func (r *ConfigRepository) SetConfigPath(configPath string) {
+	r.configPath = configPath
+}
+
+// GetConfigPath returns the path to the config file: path set explicitly, CURSYNC_CONFIG,
+// $XDG_CONFIG_HOME/cursync.toml or ~/.config/cursync.toml
 func (r *ConfigRepository) GetConfigPath() (string, error) {
+	if r.configPath != "" {
+		return r.configPath, nil
+	}
+	if configPath := lookupEnv(EnvConfigPath); configPath != "" {
+		return configPath, nil
+	}
+
+	// Relative XDG_CONFIG_HOME is invalid according to the XDG Base Directory specification
+	if configHome := lookupEnv(EnvXDGConfigHome); filepath.IsAbs(configHome) {
+		return filepath.Join(configHome, configFileName), nil
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get user home directory: %w", err)
 	}
 
-	configDir := filepath.Join(homeDir, configDirName)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create config directory: %w", err)
-	}
-
-	return filepath.Join(configDir, configFileName), nil
+	return filepath.Join(homeDir, configDirName, configFileName), nil
 }
 
-// Load loads configuration from file
+// Load loads configuration from file, config of an older version is migrated
+// and saved while the original is kept as a backup
 func (r *ConfigRepository) Load() (*Config, error) {
 	configPath, err := r.GetConfigPath()
 	if err != nil {
 		return nil, err
 	}
 
-	cfg := &Config{}
+	data, err := readConfigFile(configPath)
+	if err != nil || data == nil {
+		return &Config{}, err
+	}
 
-	// If config file doesn't exist, return empty config
-	if _, statErr := os.Stat(configPath); os.IsNotExist(statErr) {
+	cfg := &Config{}
+	fromVersion, migrated, err := decodeFile(data, configPath, cfg, true)
+	if err != nil {
+		return nil, err
+	}
+	if fromVersion == CurrentVersion {
 		return cfg, nil
 	}
 
+	backupPath := fmt.Sprintf("%s.v%d.bak", configPath, fromVersion)
+	if err := os.WriteFile(backupPath, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to back up config file before migration: %w", err)
+	}
+	// Migrated content is saved instead of cfg, so keys unknown to this version of cursync are kept
+	if err := r.SaveRaw(migrated); err != nil {
+		return nil, err
+	}
+
+	cfg.Migration = &Migration{
+		FromVersion: fromVersion,
+		ToVersion:   CurrentVersion,
+		BackupPath:  backupPath,
+	}
+	return cfg, nil
+}
+
+// LoadFile loads configuration from the given file, missing file results in empty config,
+// config of an older version is migrated in memory only
+func (r *ConfigRepository) LoadFile(configPath string) (*Config, error) {
+	data, err := readConfigFile(configPath)
+	if err != nil || data == nil {
+		return &Config{}, err
+	}
+
+	cfg := &Config{}
+	if _, _, err := decodeFile(data, configPath, cfg, true); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// readConfigFile reads config file content, returns nil if file doesn't exist
+func readConfigFile(configPath string) ([]byte, error) {
 	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
+	return data, nil
+}
 
-	if err := toml.Unmarshal(data, cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+// decodeFile parses content of global or project config file into out migrating it to the current version,
+// returns version of the original content and migrated content
+func decodeFile(data []byte, configPath string, out interface{}, global bool) (int, []byte, error) {
+	raw := map[string]interface{}{}
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return 0, nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
 	}
 
-	return cfg, nil
+	fromVersion, err := migrate(raw, global)
+	if err != nil {
+		return 0, nil, fmt.Errorf("config file %s: %w", configPath, err)
+	}
+
+	if fromVersion != CurrentVersion {
+		if data, err = toml.Marshal(raw); err != nil {
+			return 0, nil, fmt.Errorf("failed to marshal migrated config: %w", err)
+		}
+	}
+
+	if err := toml.Unmarshal(data, out); err != nil {
+		return 0, nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+
+	return fromVersion, data, nil
 }
 
-// LoadOrDefault loads configuration or returns empty config on error
-func (r *ConfigRepository) LoadOrDefault() *Config {
-	cfg, err := r.Load()
+// GetProjectConfigPath returns the path to the project config file
+func (r *ConfigRepository) GetProjectConfigPath(projectRoot string) string {
+	return filepath.Join(projectRoot, projectConfigFileName)
+}
+
+// LoadProject loads project configuration from the project root, project config of an older
+// version is migrated in memory only, relative rules directory is resolved against the project root
+func (r *ConfigRepository) LoadProject(projectRoot string) (*ProjectConfig, error) {
+	projectCfg := &ProjectConfig{}
+
+	projectCfgPath := r.GetProjectConfigPath(projectRoot)
+	data, err := readConfigFile(projectCfgPath)
 	if err != nil {
-		return &Config{}
+		return nil, fmt.Errorf("failed to read project config file: %w", err)
+	}
+	if data == nil {
+		return projectCfg, nil
 	}
-	return cfg
+
+	if _, _, err := decodeFile(data, projectCfgPath, projectCfg, false); err != nil {
+		return nil, err
+	}
+
+	if projectCfg.RulesDir != "" && !filepath.IsAbs(projectCfg.RulesDir) {
+		projectCfg.RulesDir = filepath.Join(projectRoot, projectCfg.RulesDir)
+	}
+
+	return projectCfg, nil
+}
+
+// LoadEnv loads configuration values from CURSYNC_* environment variables, empty variables are ignored
+func (r *ConfigRepository) LoadEnv() (*EnvConfig, error) {
+	env := &EnvConfig{
+		DefaultProfile: lookupEnv(EnvDefaultProfile),
+	}
+
+	for _, key := range keys {
+		valStr := lookupEnv(key.Env)
+		if !key.Profile || valStr == "" {
+			continue
+		}
+
+		value, err := key.ParseValue(valStr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key.Env, err)
+		}
+		key.setOverride(&env.Overrides, value)
+	}
+
+	return env, nil
+}
+
+// lookupEnv returns trimmed value of environment variable
+func lookupEnv(name string) string {
+	return strings.TrimSpace(os.Getenv(name))
 }
 
-// Save saves configuration to file
+// Save saves configuration to file stamped with the current schema version
 func (r *ConfigRepository) Save(cfg *Config) error {
+	cfg.Version = CurrentVersion
+
+	data, err := toml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	return r.SaveRaw(data)
+}
+
+// SaveRaw writes configuration file content as is
+func (r *ConfigRepository) SaveRaw(data []byte) error {
 	configPath, err := r.GetConfigPath()
 	if err != nil {
 		return err
 	}
 
-	data, err := toml.Marshal(cfg)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
+	if err := file_ops.WriteFileAtomic(configPath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
 
-// Set sets a configuration value by key
-func (r *ConfigRepository) Set(cfg *Config, key string, value interface{}) error {
-	switch key {
-	case "rules-dir", "rules_dir":
-		if val, ok := value.(string); ok {
-			if val == "" {
-				cfg.RulesDir = ""
-			} else {
-				cfg.RulesDir = val
-			}
-		}
-	case "file-patterns", "file_patterns":
-		if val, ok := value.(string); ok {
-			if val == "" {
-				cfg.FilePatterns = ""
-			} else {
-				cfg.FilePatterns = val
-			}
-		}
-	case "overwrite-headers", "overwrite_headers":
-		if val, ok := value.(bool); ok {
-			cfg.OverwriteHeaders = val
-		} else if valStr, ok := value.(string); ok && valStr == "" {
-			cfg.OverwriteHeaders = false
-		}
-	case "git-without-push", "git_without_push":
-		if val, ok := value.(bool); ok {
-			cfg.GitWithoutPush = val
-		} else if valStr, ok := value.(string); ok && valStr == "" {
-			cfg.GitWithoutPush = false
-		}
-	default:
-		return fmt.Errorf("unknown config key: %s", key)
+// Set sets a configuration value by key, empty string value clears it
+func (r *ConfigRepository) Set(cfg *Config, name string, value interface{}) error {
+	key, err := LookupKey(name)
+	if err != nil {
+		return err
+	}
+
+	if valStr, ok := value.(string); ok && valStr == "" {
+		key.set(cfg, nil)
+		return nil
+	}
+	if err := key.checkType(value); err != nil {
+		return err
+	}
+
+	key.set(cfg, value)
+	return nil
+}
+
+// Unset clears a configuration value by key
+func (r *ConfigRepository) Unset(cfg *Config, name string) error {
+	key, err := LookupKey(name)
+	if err != nil {
+		return err
 	}
+
+	key.set(cfg, nil)
 	return nil
 }
 
 // Get returns configuration value by key
-func (r *ConfigRepository) Get(cfg *Config, key string) (interface{}, error) {
-	switch key {
-	case "rules-dir", "rules_dir":
-		return cfg.RulesDir, nil
-	case "file-patterns", "file_patterns":
-		return cfg.FilePatterns, nil
-	case "overwrite-headers", "overwrite_headers":
-		return cfg.OverwriteHeaders, nil
-	case "git-without-push", "git_without_push":
-		return cfg.GitWithoutPush, nil
-	default:
-		return nil, fmt.Errorf("unknown config key: %s", key)
+func (r *ConfigRepository) Get(cfg *Config, name string) (interface{}, error) {
+	key, err := LookupKey(name)
+	if err != nil {
+		return nil, err
 	}
+	return key.get(cfg), nil
 }
 
 // GetAll returns all configuration as a map
 func (r *ConfigRepository) GetAll(cfg *Config) map[string]interface{} {
-	return map[string]interface{}{
-		"rules_dir":         cfg.RulesDir,
-		"file_patterns":     cfg.FilePatterns,
-		"overwrite_headers": cfg.OverwriteHeaders,
-		"git_without_push":  cfg.GitWithoutPush,
+	all := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		all[key.Name] = key.get(cfg)
 	}
+	return all
+}
+
+// SetProfile sets a configuration value by key within a profile, creating the profile if needed.
+// Empty string value clears the key, profile without values is removed
+func (r *ConfigRepository) SetProfile(cfg *Config, profile, name string, value interface{}) error {
+	if profile == "" {
+		return fmt.Errorf("profile name is empty")
+	}
+
+	key, err := lookupProfileKey(name)
+	if err != nil {
+		return err
+	}
+
+	if valStr, ok := value.(string); ok && valStr == "" {
+		value = nil
+	} else if err := key.checkType(value); err != nil {
+		return err
+	}
+
+	p, ok := cfg.Profiles[profile]
+	if !ok {
+		p = &Profile{}
+	}
+
+	key.setOverride(&p.Overrides, value)
+
+	if p.IsEmpty() {
+		delete(cfg.Profiles, profile)
+		return nil
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]*Profile)
+	}
+	cfg.Profiles[profile] = p
+	return nil
+}
+
+// UnsetProfile clears a configuration value by key within a profile, profile without values is removed
+func (r *ConfigRepository) UnsetProfile(cfg *Config, profile, name string) error {
+	if _, err := cfg.GetProfile(profile); err != nil {
+		return err
+	}
+	return r.SetProfile(cfg, profile, name, "")
+}
+
+// GetAllProfile returns all values set within a profile as a map
+func (r *ConfigRepository) GetAllProfile(cfg *Config, profile string) (map[string]interface{}, error) {
+	p, err := cfg.GetProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	all := make(map[string]interface{})
+	for _, key := range keys {
+		if !key.Profile {
+			continue
+		}
+		if value, ok := key.getOverride(&p.Overrides); ok {
+			all[key.Name] = value
+		}
+	}
+	return all, nil
+}
+
+// lookupProfileKey returns configuration key that can be set within a profile
+func lookupProfileKey(name string) (Key, error) {
+	key, err := LookupKey(name)
+	if err != nil {
+		return Key{}, err
+	}
+	if !key.Profile {
+		return Key{}, fmt.Errorf("%s cannot be set within a profile", key.Name)
+	}
+	return key, nil
+}
+
+// optionalBool converts bool value to pointer, any other value clears it
+func optionalBool(value interface{}) *bool {
+	if val, ok := value.(bool); ok {
+		return &val
+	}
+	return nil
 }
 
 // Load loads configuration from file (global function for backward compatibility)
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/pattern_matcher"
+)
+
+// Validate checks that configured rules directories exist, file patterns, delete, file and symlink modes, jobs, line endings, compare mode and backup limits parse and default profile is defined
+func (r *ConfigRepository) Validate(cfg *Config) error {
+	errs := validateOverrides("", &Overrides{
+		RulesDir:     cfg.RulesDir,
+		FilePatterns: cfg.FilePatterns,
+		Pull:         cfg.Pull,
+		Push:         cfg.Push,
+		Delete:       cfg.Delete,
+		FileMode:     cfg.FileMode,
+		Symlinks:     cfg.Symlinks,
+		Jobs:         cfg.Jobs,
+		LineEndings:  cfg.LineEndings,
+		Compare:      cfg.Compare,
+	})
+
+	for _, name := range cfg.ProfileNames() {
+		errs = append(errs, validateOverrides("profile."+name+".", &cfg.Profiles[name].Overrides)...)
+	}
+
+	if cfg.BackupCount != "" {
+		if err := checkBackupCount(cfg.BackupCount); err != nil {
+			errs = append(errs, fmt.Errorf("backup_count: %w", err))
+		}
+	}
+	if cfg.BackupMaxAge != "" {
+		if err := checkBackupAge(cfg.BackupMaxAge); err != nil {
+			errs = append(errs, fmt.Errorf("backup_max_age: %w", err))
+		}
+	}
+
+	if cfg.DefaultProfile != "" {
+		if _, err := cfg.GetProfile(cfg.DefaultProfile); err != nil {
+			errs = append(errs, fmt.Errorf("default_profile: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateProject checks that rules directory of project config exists, file patterns, delete, file and symlink modes, jobs, line endings and compare mode parse
+func (r *ConfigRepository) ValidateProject(projectCfg *ProjectConfig) error {
+	return errors.Join(validateOverrides("", &projectCfg.Overrides)...)
+}
+
+// validateOverrides validates values of a configuration layer, prefix is prepended to key names in errors
+func validateOverrides(prefix string, o *Overrides) []error {
+	var errs []error
+	if o.RulesDir != "" {
+		if err := validateRulesDir(o.RulesDir); err != nil {
+			errs = append(errs, fmt.Errorf("%srules_dir: %w", prefix, err))
+		}
+	}
+	if o.Delete != "" {
+		if err := checkDeleteMode(o.Delete); err != nil {
+			errs = append(errs, fmt.Errorf("%sdelete: %w", prefix, err))
+		}
+	}
+	if o.FileMode != "" {
+		if err := checkFileMode(o.FileMode); err != nil {
+			errs = append(errs, fmt.Errorf("%sfile_mode: %w", prefix, err))
+		}
+	}
+	if o.Symlinks != "" {
+		if err := checkSymlinkMode(o.Symlinks); err != nil {
+			errs = append(errs, fmt.Errorf("%ssymlinks: %w", prefix, err))
+		}
+	}
+	if o.Jobs != "" {
+		if err := checkJobs(o.Jobs); err != nil {
+			errs = append(errs, fmt.Errorf("%sjobs: %w", prefix, err))
+		}
+	}
+	if o.LineEndings != "" {
+		if err := checkLineEndings(o.LineEndings); err != nil {
+			errs = append(errs, fmt.Errorf("%sline_endings: %w", prefix, err))
+		}
+	}
+	if o.Compare != "" {
+		if err := checkCompareMode(o.Compare); err != nil {
+			errs = append(errs, fmt.Errorf("%scompare: %w", prefix, err))
+		}
+	}
+	for _, key := range keys {
+		if key.Type != ValueTypeList || key.getOverride == nil {
+			continue
+		}
+		patterns, _ := key.getOverride(o)
+		if err := ValidatePatterns(patterns.([]string)); err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %w", prefix, key.Name, err))
+		}
+	}
+	return errs
+}
+
+// validateRulesDir checks that rules directory exists
+func validateRulesDir(rulesDir string) error {
+	info, err := os.Stat(rulesDir)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("directory %s does not exist", rulesDir)
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", rulesDir)
+	}
+	return nil
+}
+
+// ValidatePatterns checks that each file pattern parses after brace expansion
+func ValidatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		for _, expanded := range pattern_matcher.ExpandBraces(strings.TrimSpace(pattern)) {
+			if _, err := pattern_matcher.CompilePattern(expanded); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
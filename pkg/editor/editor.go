@@ -0,0 +1,41 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const defaultEditor = "vi"
+
+// Editor opens files in user's editor
+type Editor struct{}
+
+// NewEditor creates a new Editor instance
+func NewEditor() *Editor {
+	return &Editor{}
+}
+
+// Edit opens file in $VISUAL, $EDITOR or vi and waits for the editor to exit
+func (e *Editor) Edit(path string) error {
+	command := defaultEditor
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			command = value
+			break
+		}
+	}
+
+	// Editor command may contain arguments, e.g. "code --wait"
+	args := strings.Fields(command)
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", command, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package file_ops
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// tempFilePrefix starts names of temporary files created by atomic writes
+const tempFilePrefix = ".cursync-tmp-"
+
+// IsTempFile checks if file name belongs to a temporary file left by an interrupted atomic write
+func IsTempFile(name string) bool {
+	return strings.HasPrefix(name, tempFilePrefix)
+}
+
+// WriteFileAtomic writes data to a temporary file in the same directory, syncs it and renames it over path,
+// so that the file has either old or new content even if writing is interrupted.
+// Existing file keeps its permissions, new file gets perm. Symlinks are followed like by os.WriteFile
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return writeAtomic(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// CopyFileAtomic streams content of src into path like WriteFileAtomic, without loading it into memory
+func CopyFileAtomic(path string, src io.Reader, perm os.FileMode) error {
+	return writeAtomic(path, perm, func(w io.Writer) error {
+		_, err := io.Copy(w, src)
+		return err
+	})
+}
+
+// ResolvePath returns path with symlinks resolved, or path itself if it is not an existing symlink target
+func ResolvePath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// writeAtomic writes content with write to a temporary file and renames it over path,
+// symlinked path is resolved first so that the link is kept and its target gets the content
+func writeAtomic(path string, perm os.FileMode, write func(w io.Writer) error) (err error) {
+	path = ResolvePath(path)
+	if info, statErr := os.Stat(path); statErr == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, tempFilePrefix+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if err = write(tmp); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes directory entry of renamed file to disk, not supported on every platform so errors are ignored
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	_ = d.Sync()
+}
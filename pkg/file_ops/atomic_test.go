@@ -0,0 +1,113 @@
+package file_ops_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	t.Run("creates file with permissions", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "rule.mdc")
+		require.NoError(t, file_ops.WriteFileAtomic(path, []byte("content"), 0640))
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		if diff := cmp.Diff("content", string(content)); diff != "" {
+			t.Errorf("Content mismatch (-want +got):\n%s", diff)
+		}
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0640), info.Mode().Perm())
+	})
+
+	t.Run("replaces file keeping its permissions", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "rule.mdc")
+		require.NoError(t, os.WriteFile(path, []byte("old content"), 0600))
+		require.NoError(t, os.Chmod(path, 0644))
+
+		require.NoError(t, file_ops.WriteFileAtomic(path, []byte("new"), 0600))
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		if diff := cmp.Diff("new", string(content)); diff != "" {
+			t.Errorf("Content mismatch (-want +got):\n%s", diff)
+		}
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	})
+
+	t.Run("writes through symlink", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		targetDir := filepath.Join(dir, "dotfiles")
+		require.NoError(t, os.Mkdir(targetDir, 0755))
+		target := filepath.Join(targetDir, "cursync.toml")
+		require.NoError(t, os.WriteFile(target, []byte("old content"), 0644))
+		link := filepath.Join(dir, "cursync.toml")
+		require.NoError(t, os.Symlink(target, link))
+
+		require.NoError(t, file_ops.WriteFileAtomic(link, []byte("new"), 0600))
+
+		info, err := os.Lstat(link)
+		require.NoError(t, err)
+		require.NotZero(t, info.Mode()&os.ModeSymlink)
+
+		content, err := os.ReadFile(target)
+		require.NoError(t, err)
+		if diff := cmp.Diff("new", string(content)); diff != "" {
+			t.Errorf("Content mismatch (-want +got):\n%s", diff)
+		}
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+	})
+
+	t.Run("error leaves no temporary file", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "rule.mdc")
+		require.NoError(t, os.Mkdir(path, 0755))
+
+		require.Error(t, file_ops.WriteFileAtomic(path, []byte("content"), 0600))
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	})
+}
+
+func TestFileOps_FindAllFiles_SkipsTempFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	rule := filepath.Join(dir, "rule.mdc")
+	require.NoError(t, os.WriteFile(rule, []byte("content"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".cursync-tmp-rule.mdc-123"), []byte("cont"), 0600))
+
+	files, err := file_ops.NewFileOps().FindAllFiles(dir)
+	require.NoError(t, err)
+	if diff := cmp.Diff([]string{rule}, files); diff != "" {
+		t.Errorf("Files mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,98 @@
+package file_ops
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"unicode/utf8"
+)
+
+const (
+	// sniffSize is how many leading bytes of a file are inspected to detect binary content
+	sniffSize = 8000
+	// MaxTextSize is size above which files are handled as binary, so they are never loaded into memory
+	MaxTextSize = 8 << 20
+)
+
+// IsBinary checks if content has NUL bytes or is not valid UTF-8,
+// a multi-byte character cut at the end of content is not counted as invalid
+func IsBinary(content []byte) bool {
+	if bytes.IndexByte(content, 0) >= 0 {
+		return true
+	}
+	for len(content) > 0 {
+		r, size := utf8.DecodeRune(content)
+		if r == utf8.RuneError && size == 1 {
+			return utf8.FullRune(content)
+		}
+		content = content[size:]
+	}
+	return false
+}
+
+// IsBinaryFile checks if file is binary by its leading bytes, files larger than MaxTextSize are binary
+func (f *FileOps) IsBinaryFile(filePath string) (bool, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Size() > MaxTextSize {
+		return true, nil
+	}
+	return sniffBinary(file)
+}
+
+// IsLargeTextFile checks if file is larger than MaxTextSize while its leading bytes are text,
+// IsBinaryFile reports such file as binary
+func (f *FileOps) IsLargeTextFile(filePath string) (bool, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Size() <= MaxTextSize {
+		return false, nil
+	}
+
+	binary, err := sniffBinary(file)
+	return !binary, err
+}
+
+// sniffBinary checks if file is binary by its leading bytes
+func sniffBinary(file *os.File) (bool, error) {
+	buf := make([]byte, sniffSize)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return IsBinary(buf[:n]), nil
+}
+
+// HashFile returns hex encoded SHA-256 of raw file content, reading the file as a stream
+func (f *FileOps) HashFile(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,133 @@
+package file_ops_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
+)
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    bool
+	}{
+		{name: "empty", content: nil, want: false},
+		{name: "text", content: []byte("# Rule\r\nline  \n"), want: false},
+		{name: "utf-8 text", content: []byte("правило ✓\n"), want: false},
+		{name: "nul byte", content: []byte("text\x00more"), want: true},
+		{name: "invalid utf-8", content: []byte{'a', 0xff, 'b'}, want: true},
+		{name: "png header", content: []byte("\x89PNG\r\n\x1a\n"), want: true},
+		{name: "character cut at the end", content: []byte("ok \xe2\x9c"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(tt.want, file_ops.IsBinary(tt.content)); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFileOps_IsBinaryFile(t *testing.T) {
+	t.Run("detects binary by leading bytes", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		text := filepath.Join(dir, "rule.mdc")
+		image := filepath.Join(dir, "logo.png")
+		require.NoError(t, os.WriteFile(text, []byte("content\r\n"), 0644))
+		require.NoError(t, os.WriteFile(image, []byte("\x89PNG\r\n\x1a\n\x00\x00"), 0644))
+
+		binary, err := file_ops.NewFileOps().IsBinaryFile(text)
+		require.NoError(t, err)
+		require.False(t, binary)
+
+		binary, err = file_ops.NewFileOps().IsBinaryFile(image)
+		require.NoError(t, err)
+		require.True(t, binary)
+	})
+
+	t.Run("handles large files as binary", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "large.md")
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+		require.NoError(t, os.Truncate(path, file_ops.MaxTextSize+1))
+
+		binary, err := file_ops.NewFileOps().IsBinaryFile(path)
+		require.NoError(t, err)
+		require.True(t, binary)
+	})
+
+	t.Run("error for missing file", func(t *testing.T) {
+		t.Parallel()
+		_, err := file_ops.NewFileOps().IsBinaryFile(filepath.Join(t.TempDir(), "missing"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestFileOps_IsLargeTextFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	largeText := filepath.Join(dir, "large.md")
+	require.NoError(t, os.WriteFile(largeText, bytes.Repeat([]byte("line\n"), file_ops.MaxTextSize/5+1), 0644))
+	largeBinary := filepath.Join(dir, "large.bin")
+	require.NoError(t, os.WriteFile(largeBinary, nil, 0644))
+	require.NoError(t, os.Truncate(largeBinary, file_ops.MaxTextSize+1))
+	smallText := filepath.Join(dir, "rule.mdc")
+	require.NoError(t, os.WriteFile(smallText, []byte("content\n"), 0644))
+
+	fileOps := file_ops.NewFileOps()
+	for path, expected := range map[string]bool{largeText: true, largeBinary: false, smallText: false} {
+		large, err := fileOps.IsLargeTextFile(path)
+		require.NoError(t, err)
+		require.Equal(t, expected, large, path)
+	}
+}
+
+func TestFileOps_HashFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "a.bin")
+	file2 := filepath.Join(dir, "b.bin")
+	file3 := filepath.Join(dir, "c.bin")
+	require.NoError(t, os.WriteFile(file1, []byte("\x00data\r\n"), 0644))
+	require.NoError(t, os.WriteFile(file2, []byte("\x00data\r\n"), 0644))
+	require.NoError(t, os.WriteFile(file3, []byte("\x00data\n"), 0644))
+
+	fileOps := file_ops.NewFileOps()
+	hash1, err := fileOps.HashFile(file1)
+	require.NoError(t, err)
+	hash2, err := fileOps.HashFile(file2)
+	require.NoError(t, err)
+	hash3, err := fileOps.HashFile(file3)
+	require.NoError(t, err)
+
+	require.Equal(t, hash1, hash2)
+	require.NotEqual(t, hash1, hash3, "line endings of binary files must not be normalized")
+}
+
+func TestFileOps_CopyFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	src := filepath.Join(dir, "logo.png")
+	dst := filepath.Join(dir, "nested", "logo.png")
+	content := []byte("\x89PNG\r\n\x1a\n\x00  \r\n\n\n")
+	require.NoError(t, os.WriteFile(src, content, 0644))
+
+	require.NoError(t, file_ops.NewFileOps().CopyFile(src, dst))
+
+	copied, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	if diff := cmp.Diff(content, copied); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
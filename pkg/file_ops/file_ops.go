@@ -4,9 +4,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
 )
 
+const gitDirName = ".git"
+
 // FileOps handles file operations
 type FileOps struct{}
 
@@ -15,14 +21,18 @@ func NewFileOps() *FileOps {
 	return &FileOps{}
 }
 
-// FindAllFiles finds all files in the specified directory recursively
+// FindAllFiles finds all files in the specified directory recursively, skipping .git directories
+// and temporary files of interrupted writes
 func (f *FileOps) FindAllFiles(dir string) ([]string, error) {
 	var allFiles []string
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if !info.IsDir() {
+		if info.IsDir() && info.Name() == gitDirName {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && !IsTempFile(info.Name()) {
 			allFiles = append(allFiles, path)
 		}
 		return nil
@@ -33,13 +43,22 @@ func (f *FileOps) FindAllFiles(dir string) ([]string, error) {
 	return allFiles, nil
 }
 
+// ReadFile reads content of a file as is
+func (f *FileOps) ReadFile(filePath string) ([]byte, error) {
+	return os.ReadFile(filePath)
+}
+
 // ReadFileNormalized reads a file and normalizes line endings
 func (f *FileOps) ReadFileNormalized(filePath string) (string, error) {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return "", err
 	}
+	return NormalizeContent(content), nil
+}
 
+// NormalizeContent converts line endings to LF, trims trailing whitespace and ends non-empty content with a single newline
+func NormalizeContent(content []byte) string {
 	// Normalize line endings - convert to LF
 	normalized := strings.ReplaceAll(string(content), "\r\n", "\n")
 	normalized = strings.ReplaceAll(normalized, "\r", "\n")
@@ -53,16 +72,30 @@ func (f *FileOps) ReadFileNormalized(filePath string) (string, error) {
 		normalized += "\n"
 	}
 
-	return normalized, nil
+	return normalized
 }
 
-// WriteFile creates directory if needed and writes content to file
+// ApplyLineEndings normalizes content like NormalizeContent and converts its line endings to the policy,
+// LineEndingsPreserve keeps content as is and LineEndingsNative uses CRLF on Windows
+func ApplyLineEndings(content []byte, lineEndings models.LineEndings) string {
+	if lineEndings == models.LineEndingsPreserve {
+		return string(content)
+	}
+
+	normalized := NormalizeContent(content)
+	if lineEndings == models.LineEndingsCRLF || (lineEndings == models.LineEndingsNative && runtime.GOOS == "windows") {
+		return strings.ReplaceAll(normalized, "\n", "\r\n")
+	}
+	return normalized
+}
+
+// WriteFile creates directory if needed and atomically writes content to file
 func (f *FileOps) WriteFile(filePath, content string, perm os.FileMode) error {
 	if err := os.MkdirAll(filepath.Dir(filePath), perm); err != nil {
 		return fmt.Errorf("failed to create directory for %s: %w", filePath, err)
 	}
 
-	err := os.WriteFile(filePath, []byte(content), perm)
+	err := WriteFileAtomic(filePath, []byte(content), perm)
 	if err != nil {
 		return fmt.Errorf("failed to write file %s: %w", filePath, err)
 	}
@@ -81,18 +114,19 @@ func (f *FileOps) FileExists(filePath string) (bool, error) {
 	return false, err
 }
 
-// CopyFile copies file from source to destination
+// CopyFile atomically copies file from source to destination, content is copied as a stream
 func (f *FileOps) CopyFile(srcPath, dstPath string) error {
-	content, err := os.ReadFile(srcPath)
+	src, err := os.Open(srcPath)
 	if err != nil {
 		return fmt.Errorf("failed to read source file %s: %w", srcPath, err)
 	}
+	defer src.Close()
 
 	if mkdirErr := os.MkdirAll(filepath.Dir(dstPath), os.ModePerm); mkdirErr != nil {
 		return fmt.Errorf("failed to create directory for %s: %w", dstPath, mkdirErr)
 	}
 
-	err = os.WriteFile(dstPath, content, 0600)
+	err = CopyFileAtomic(dstPath, src, 0600)
 	if err != nil {
 		return fmt.Errorf("failed to write destination file %s: %w", dstPath, err)
 	}
@@ -105,6 +139,16 @@ func (f *FileOps) RemoveFile(filePath string) error {
 	return os.Remove(filePath)
 }
 
+// Chmod changes permissions of file
+func (f *FileOps) Chmod(filePath string, mode os.FileMode) error {
+	return os.Chmod(filePath, mode)
+}
+
+// Chtimes changes access and modification times of file
+func (f *FileOps) Chtimes(filePath string, atime, mtime time.Time) error {
+	return os.Chtimes(filePath, atime, mtime)
+}
+
 // MkdirAll creates directory with all necessary parent directories
 func (f *FileOps) MkdirAll(path string, perm os.FileMode) error {
 	return os.MkdirAll(path, perm)
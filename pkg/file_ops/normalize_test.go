@@ -0,0 +1,39 @@
+package file_ops_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
+)
+
+func TestApplyLineEndings(t *testing.T) {
+	content := []byte("line  \r\nbreak  \nend\r\n\r\n \t")
+	native := "line  \nbreak  \nend\n"
+	if runtime.GOOS == "windows" {
+		native = "line  \r\nbreak  \r\nend\r\n"
+	}
+
+	tests := []struct {
+		name        string
+		lineEndings models.LineEndings
+		want        string
+	}{
+		{name: "lf", lineEndings: models.LineEndingsLF, want: "line  \nbreak  \nend\n"},
+		{name: "crlf", lineEndings: models.LineEndingsCRLF, want: "line  \r\nbreak  \r\nend\r\n"},
+		{name: "native", lineEndings: models.LineEndingsNative, want: native},
+		{name: "preserve", lineEndings: models.LineEndingsPreserve, want: string(content)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(tt.want, file_ops.ApplyLineEndings(content, tt.lineEndings)); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
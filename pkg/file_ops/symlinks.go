@@ -0,0 +1,197 @@
+package file_ops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+)
+
+// FindFiles finds all files in the specified directory recursively like FindAllFiles, handling symlinks by mode:
+// SymlinkFollow lists linked files and walks linked directories, SymlinkPreserve lists links themselves
+// and SymlinkSkip leaves them out. Links pointing outside the directory are skipped and reported in the scan,
+// link loops and broken links are reported as errors.
+// Links into linkRoots, e.g. files linked by pull --link, are followed in every mode
+func (f *FileOps) FindFiles(dir string, symlinks models.SymlinkMode, linkRoots ...string) (*models.FileScan, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error finding files in %s: %w", dir, err)
+	}
+	root, err := filepath.EvalSymlinks(absDir)
+	if err != nil {
+		return nil, fmt.Errorf("error finding files in %s: %w", dir, err)
+	}
+
+	w := &symlinkWalker{dir: absDir, root: root, mode: symlinks, scan: &models.FileScan{}}
+	for _, linkRoot := range linkRoots {
+		if resolved, err := filepath.EvalSymlinks(linkRoot); err == nil {
+			w.linkRoots = append(w.linkRoots, resolved)
+		}
+	}
+	if err := w.walkDir(dir, root, map[string]bool{}); err != nil {
+		return nil, fmt.Errorf("error finding files in %s: %w", dir, err)
+	}
+	return w.scan, nil
+}
+
+// ReadLink returns target of symlink
+func (f *FileOps) ReadLink(filePath string) (string, error) {
+	return os.Readlink(filePath)
+}
+
+// WriteSymlink replaces file at path with symlink to target
+func (f *FileOps) WriteSymlink(target, filePath string) error {
+	if err := WriteSymlinkAtomic(target, filePath); err != nil {
+		return fmt.Errorf("failed to write symlink %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// WriteSymlinkAtomic creates symlink to target under a temporary name and renames it over path,
+// so that path is never missing while replaced
+func WriteSymlinkAtomic(target, path string) error {
+	tmpPath := filepath.Join(filepath.Dir(path), tempFilePrefix+filepath.Base(path))
+	_ = os.Remove(tmpPath)
+	if err := os.Symlink(target, tmpPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// symlinkWalker collects files of directory tree applying symlink mode
+type symlinkWalker struct {
+	dir       string   // Absolute directory as passed by caller
+	root      string   // Resolved directory, links must point inside it
+	linkRoots []string // Resolved directories links into which are always followed
+	mode      models.SymlinkMode
+	scan      *models.FileScan
+}
+
+// walkDir lists files of directory at path whose resolved location is realPath,
+// ancestors holds resolved directories being walked to detect loops
+func (w *symlinkWalker) walkDir(path, realPath string, ancestors map[string]bool) error {
+	if ancestors[realPath] {
+		return fmt.Errorf("symlink loop at %s", path)
+	}
+	ancestors[realPath] = true
+	defer delete(ancestors, realPath)
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		entryPath := filepath.Join(path, name)
+		switch {
+		case entry.Type()&os.ModeSymlink != 0:
+			if err := w.visitLink(entryPath, ancestors); err != nil {
+				return err
+			}
+		case entry.IsDir():
+			if name == gitDirName {
+				continue
+			}
+			if err := w.walkDir(entryPath, filepath.Join(realPath, name), ancestors); err != nil {
+				return err
+			}
+		case !IsTempFile(name):
+			w.scan.Files = append(w.scan.Files, entryPath)
+		}
+	}
+	return nil
+}
+
+// visitLink handles symlink at path according to walker mode
+func (w *symlinkWalker) visitLink(path string, ancestors map[string]bool) error {
+	if IsTempFile(filepath.Base(path)) {
+		return nil
+	}
+	w.scan.Links = true
+	if target, ok := w.linkRootTarget(path); ok {
+		return w.follow(path, target, ancestors)
+	}
+	if w.mode == models.SymlinkSkip {
+		return nil
+	}
+
+	if w.mode == models.SymlinkPreserve {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		if target, err = filepath.Abs(target); err != nil {
+			return err
+		}
+		if !isWithin(w.dir, target) && !isWithin(w.root, target) {
+			w.scan.Skipped = append(w.scan.Skipped, path)
+			return nil
+		}
+		w.scan.Files = append(w.scan.Files, path)
+		return nil
+	}
+
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("broken symlink %s: %w", path, err)
+	}
+	if target, err = filepath.Abs(target); err != nil {
+		return err
+	}
+	if !isWithin(w.root, target) {
+		w.scan.Skipped = append(w.scan.Skipped, path)
+		return nil
+	}
+	return w.follow(path, target, ancestors)
+}
+
+// linkRootTarget returns resolved target of symlink at path if it points into one of link roots
+func (w *symlinkWalker) linkRootTarget(path string) (string, bool) {
+	if len(w.linkRoots) == 0 {
+		return "", false
+	}
+
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", false
+	}
+	for _, linkRoot := range w.linkRoots {
+		if isWithin(linkRoot, target) {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+// follow lists linked file or walks linked directory at path resolved to target
+func (w *symlinkWalker) follow(path, target string, ancestors map[string]bool) error {
+	info, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return w.walkDir(path, target, ancestors)
+	}
+	w.scan.Files = append(w.scan.Files, path)
+	return nil
+}
+
+// isWithin checks if path is dir or is located inside it
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,161 @@
+package file_ops_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
+)
+
+// createLinkedTree creates rules tree with shared directory linked as linked and file link alias.mdc
+func createLinkedTree(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "shared"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rule.mdc"), []byte("rule"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared", "snippet.mdc"), []byte("snippet"), 0600))
+	require.NoError(t, os.Symlink("shared", filepath.Join(dir, "linked")))
+	require.NoError(t, os.Symlink("rule.mdc", filepath.Join(dir, "alias.mdc")))
+	return dir
+}
+
+func TestFileOps_FindFiles(t *testing.T) {
+	fileOps := file_ops.NewFileOps()
+
+	tests := []struct {
+		name     string
+		mode     models.SymlinkMode
+		expected []string
+	}{
+		{
+			name:     "follow",
+			mode:     models.SymlinkFollow,
+			expected: []string{"alias.mdc", "linked/snippet.mdc", "rule.mdc", "shared/snippet.mdc"},
+		},
+		{
+			name:     "preserve",
+			mode:     models.SymlinkPreserve,
+			expected: []string{"alias.mdc", "linked", "rule.mdc", "shared/snippet.mdc"},
+		},
+		{
+			name:     "skip",
+			mode:     models.SymlinkSkip,
+			expected: []string{"rule.mdc", "shared/snippet.mdc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := createLinkedTree(t)
+			scan, err := fileOps.FindFiles(dir, tt.mode)
+			require.NoError(t, err)
+			require.True(t, scan.Links)
+
+			var relative []string
+			for _, file := range scan.Files {
+				rel, err := filepath.Rel(dir, file)
+				require.NoError(t, err)
+				relative = append(relative, filepath.ToSlash(rel))
+			}
+			if diff := cmp.Diff(tt.expected, relative); diff != "" {
+				t.Errorf("Files mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("detects symlink loop", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "a"), 0755))
+		require.NoError(t, os.Symlink("..", filepath.Join(dir, "a", "up")))
+
+		_, err := fileOps.FindFiles(dir, models.SymlinkFollow)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "symlink loop")
+	})
+
+	t.Run("skips symlink outside directory", func(t *testing.T) {
+		t.Parallel()
+
+		outside := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.mdc"), []byte("secret"), 0600))
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "rule.mdc"), []byte("rule"), 0600))
+		link := filepath.Join(dir, "secret.mdc")
+		require.NoError(t, os.Symlink(filepath.Join(outside, "secret.mdc"), link))
+
+		for _, mode := range []models.SymlinkMode{models.SymlinkFollow, models.SymlinkPreserve} {
+			scan, err := fileOps.FindFiles(dir, mode)
+			require.NoError(t, err)
+			expected := &models.FileScan{
+				Files:   []string{filepath.Join(dir, "rule.mdc")},
+				Links:   true,
+				Skipped: []string{link},
+			}
+			if diff := cmp.Diff(expected, scan); diff != "" {
+				t.Errorf("Scan mismatch (-want +got):\n%s", diff)
+			}
+		}
+
+		scan, err := fileOps.FindFiles(dir, models.SymlinkSkip)
+		require.NoError(t, err)
+		require.Empty(t, scan.Skipped)
+	})
+
+	t.Run("reports tree without symlinks", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "rule.mdc"), []byte("rule"), 0600))
+
+		scan, err := fileOps.FindFiles(dir, models.SymlinkFollow)
+		require.NoError(t, err)
+		require.False(t, scan.Links)
+	})
+
+	t.Run("follows symlinks into link roots", func(t *testing.T) {
+		t.Parallel()
+
+		rulesDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(rulesDir, "rule.mdc"), []byte("rule"), 0600))
+		dir := t.TempDir()
+		linked := filepath.Join(dir, "rule.mdc")
+		require.NoError(t, os.Symlink(filepath.Join(rulesDir, "rule.mdc"), linked))
+
+		for _, mode := range []models.SymlinkMode{models.SymlinkFollow, models.SymlinkPreserve, models.SymlinkSkip} {
+			scan, err := fileOps.FindFiles(dir, mode, rulesDir)
+			require.NoError(t, err)
+			if diff := cmp.Diff([]string{linked}, scan.Files); diff != "" {
+				t.Errorf("Files mismatch (-want +got):\n%s", diff)
+			}
+		}
+	})
+}
+
+func TestFileOps_WriteSymlink(t *testing.T) {
+	t.Parallel()
+
+	fileOps := file_ops.NewFileOps()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "alias.mdc")
+	require.NoError(t, os.WriteFile(path, []byte("content"), 0600))
+
+	require.NoError(t, fileOps.WriteSymlink("rule.mdc", path))
+
+	target, err := fileOps.ReadLink(path)
+	require.NoError(t, err)
+	require.Equal(t, "rule.mdc", target)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
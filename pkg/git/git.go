@@ -1,10 +1,14 @@
 package git
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
 )
 
 const cursorDirName = ".cursor"
@@ -101,3 +105,84 @@ func (g *Git) pushIfRemoteExists() error {
 
 	return nil
 }
+
+// GetHeadCommit returns hash of HEAD commit of repository containing repoDir
+func (g *Git) GetHeadCommit(repoDir string) (string, error) {
+	out, err := g.runInDir(repoDir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD commit in %s: %w", repoDir, err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// HasUncommittedChanges checks if repoDir contains modified, staged or untracked files
+func (g *Git) HasUncommittedChanges(repoDir string) (bool, error) {
+	out, err := g.runInDir(repoDir, "status", "--porcelain", "--untracked-files=all", "--", ".")
+	if err != nil {
+		return false, fmt.Errorf("failed to get status of %s: %w", repoDir, err)
+	}
+
+	return len(bytes.TrimSpace(out)) > 0, nil
+}
+
+// GetChangedFiles returns files changed in repoDir between fromCommit and HEAD, paths are relative to repoDir
+func (g *Git) GetChangedFiles(repoDir, fromCommit string) ([]models.ChangedFile, error) {
+	out, err := g.runInDir(repoDir, "diff", "--name-status", "--no-renames", "--relative", "-z", fromCommit+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changes since %s in %s: %w", fromCommit, repoDir, err)
+	}
+
+	return parseNameStatus(out)
+}
+
+// parseNameStatus parses NUL-separated output of git diff --name-status -z
+func parseNameStatus(out []byte) ([]models.ChangedFile, error) {
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	if len(fields) == 1 && fields[0] == "" {
+		return []models.ChangedFile{}, nil
+	}
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("unexpected git diff output")
+	}
+
+	changes := make([]models.ChangedFile, 0, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		var operationType models.OperationType
+		switch fields[i] {
+		case "A":
+			operationType = models.OperationAdd
+		case "D":
+			operationType = models.OperationDelete
+		case "M", "T":
+			operationType = models.OperationUpdate
+		default:
+			return nil, fmt.Errorf("unexpected git change status %q for %s", fields[i], fields[i+1])
+		}
+
+		changes = append(changes, models.ChangedFile{
+			Type:         operationType,
+			RelativePath: filepath.FromSlash(fields[i+1]),
+		})
+	}
+
+	return changes, nil
+}
+
+// runInDir runs git command in directory and returns its standard output
+func (g *Git) runInDir(dir string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	return out, nil
+}
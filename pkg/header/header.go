@@ -24,12 +24,12 @@ func (h *Header) RemoveHeaderFromContent(content string) string {
 		return content
 	}
 
-	if lines[0] != headerSeparator {
+	if !isSeparator(lines[0]) {
 		return content
 	}
 
 	for i := 1; i < len(lines); i++ {
-		if lines[i] == headerSeparator {
+		if isSeparator(lines[i]) {
 			if i+1 < len(lines) {
 				remainingLines := lines[i+1:]
 				remainingLines = removeLeadingEmptyLines(remainingLines)
@@ -48,7 +48,7 @@ func (h *Header) RemoveHeaderFromContent(content string) string {
 // ExtractHeaderFromContent extracts YAML header from markdown content
 func (h *Header) ExtractHeaderFromContent(content string) string {
 	lines := strings.Split(content, "\n")
-	if len(lines) == 0 || lines[0] != headerSeparator {
+	if len(lines) == 0 || !isSeparator(lines[0]) {
 		return ""
 	}
 
@@ -57,7 +57,7 @@ func (h *Header) ExtractHeaderFromContent(content string) string {
 
 	for i := 1; i < len(lines) && i <= maxHeaderLines; i++ {
 		headerLines = append(headerLines, lines[i])
-		if lines[i] == headerSeparator {
+		if isSeparator(lines[i]) {
 			return strings.Join(headerLines, "\n") + "\n"
 		}
 	}
@@ -65,6 +65,11 @@ func (h *Header) ExtractHeaderFromContent(content string) string {
 	return ""
 }
 
+// isSeparator checks if line separates YAML header, lines may end with CR of CRLF line endings
+func isSeparator(line string) bool {
+	return strings.TrimSuffix(line, "\r") == headerSeparator
+}
+
 // removeLeadingEmptyLines removes leading empty lines from slice
 func removeLeadingEmptyLines(lines []string) []string {
 	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
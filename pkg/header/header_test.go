@@ -32,6 +32,11 @@ func TestHeader_RemoveHeaderFromContent(t *testing.T) {
 			content:  "---\nkey: value\n---\n\n\ncontent",
 			expected: "content",
 		},
+		{
+			name:     "content with header with crlf line endings",
+			content:  "---\r\nkey: value\r\n---\r\n\r\ncontent\r\n",
+			expected: "content\r\n",
+		},
 		{
 			name:     "empty content",
 			content:  "",
@@ -97,6 +102,11 @@ func TestHeader_ExtractHeaderFromContent(t *testing.T) {
 			content:  "---\nkey: value\ncontent",
 			expected: "",
 		},
+		{
+			name:     "header with crlf line endings",
+			content:  "---\r\nkey: value\r\n---\r\ncontent\r\n",
+			expected: "---\r\nkey: value\r\n---\r\n",
+		},
 	}
 
 	for _, tt := range tests {
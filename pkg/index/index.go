@@ -0,0 +1,285 @@
+package index
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
+	"github.com/yanodintsovmercuryo/cursync/pkg/header"
+)
+
+const (
+	indexVersion = 3
+	mdcExtension = ".mdc"
+
+	// racyWindow is how long before hashing a file must have been modified for its entry to be trusted,
+	// it covers coarse modification time resolution of file systems and small clock skew
+	racyWindow = 2 * time.Second
+)
+
+// Digest holds hashes of normalized and raw content of a text file or of raw content of a binary file
+type Digest struct {
+	Content string `json:"content"`
+	Body    string `json:"body,omitempty"` // hash of content without YAML header, only for .mdc text files
+	Raw     string `json:"raw,omitempty"`  // hash of raw content, only for text files
+	Binary  bool   `json:"binary,omitempty"`
+}
+
+// Entry holds stat information of a file and digest of its content at the time it was hashed
+type Entry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mod_time"`
+	Hashed  int64  `json:"hashed"`
+	Digest  Digest `json:"digest"`
+}
+
+// indexFile is the stored index of a directory keyed by slash-separated relative path
+type indexFile struct {
+	Version int              `json:"version"`
+	Dir     string           `json:"dir"`
+	Entries map[string]Entry `json:"entries"`
+}
+
+// root holds index of a directory opened by the current operation
+type root struct {
+	dir     string
+	entries map[string]Entry
+	dirty   bool
+}
+
+// Index keeps digests of file contents per directory so that unchanged files are compared without reading them,
+// an entry is used only while size and modification time of the file are unchanged and the file was modified
+// well before it was hashed, otherwise the file has to be read again
+type Index struct {
+	storeDir      string
+	headerService *header.Header
+	now           func() time.Time
+
+	mu    sync.Mutex
+	roots []*root
+}
+
+// NewIndex creates Index storing indexes of directories in storeDir
+func NewIndex(storeDir string) *Index {
+	return &Index{
+		storeDir:      storeDir,
+		headerService: header.NewHeader(),
+		now:           time.Now,
+	}
+}
+
+// DefaultDir returns default directory for content indexes in the user cache directory
+func DefaultDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	return filepath.Join(cacheDir, "cursync", "index")
+}
+
+// Open loads indexes of directories, files outside of them are neither looked up nor recorded.
+// Unreadable or outdated index of a directory is started over
+func (x *Index) Open(dirs ...string) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.roots = nil
+	for _, dir := range dirs {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve directory %s: %w", dir, err)
+		}
+
+		r := &root{dir: absDir, entries: make(map[string]Entry)}
+		data, err := os.ReadFile(x.storePath(absDir))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read index of %s: %w", dir, err)
+		}
+		if err == nil {
+			stored := &indexFile{}
+			if json.Unmarshal(data, stored) == nil && stored.Version == indexVersion && stored.Dir == absDir && stored.Entries != nil {
+				r.entries = stored.Entries
+			}
+		}
+		x.roots = append(x.roots, r)
+	}
+
+	return nil
+}
+
+// Save stores changed indexes of opened directories and closes them
+func (x *Index) Save() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	roots := x.roots
+	x.roots = nil
+	for _, r := range roots {
+		if !r.dirty {
+			continue
+		}
+		if err := os.MkdirAll(x.storeDir, 0755); err != nil {
+			return fmt.Errorf("failed to create index directory: %w", err)
+		}
+
+		data, err := json.Marshal(&indexFile{Version: indexVersion, Dir: r.dir, Entries: r.entries})
+		if err != nil {
+			return fmt.Errorf("failed to marshal index of %s: %w", r.dir, err)
+		}
+		if err := file_ops.WriteFileAtomic(x.storePath(r.dir), data, 0600); err != nil {
+			return fmt.Errorf("failed to write index of %s: %w", r.dir, err)
+		}
+	}
+
+	return nil
+}
+
+// Lookup returns digest of file recorded in index if the entry can still be trusted
+func (x *Index) Lookup(filePath string) (Digest, bool) {
+	x.mu.Lock()
+	r, key := x.find(filePath)
+	var entry Entry
+	ok := false
+	if r != nil {
+		entry, ok = r.entries[key]
+	}
+	x.mu.Unlock()
+	if !ok {
+		return Digest{}, false
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil || !entry.trusted(info, x.now()) {
+		x.forget(r, key)
+		return Digest{}, false
+	}
+	return entry.Digest, true
+}
+
+// Record stores digest of text file content, before is stat information taken before the content was read,
+// nothing is recorded if the file changed since then
+func (x *Index) Record(filePath string, before os.FileInfo, data []byte) {
+	x.record(filePath, before, func() Digest {
+		return x.digest(filePath, data)
+	})
+}
+
+// RecordBinary stores hash of raw content of binary file like Record
+func (x *Index) RecordBinary(filePath string, before os.FileInfo, hash string) {
+	x.record(filePath, before, func() Digest {
+		return Digest{Content: hash, Binary: true}
+	})
+}
+
+// record stores digest of file unless it changed since before was taken
+func (x *Index) record(filePath string, before os.FileInfo, digest func() Digest) {
+	x.mu.Lock()
+	r, key := x.find(filePath)
+	x.mu.Unlock()
+	if r == nil {
+		return
+	}
+
+	after, err := os.Stat(filePath)
+	if err != nil || after.Size() != before.Size() || !after.ModTime().Equal(before.ModTime()) {
+		x.forget(r, key)
+		return
+	}
+
+	entry := Entry{
+		Size:    after.Size(),
+		ModTime: after.ModTime().UnixNano(),
+		Hashed:  x.now().UnixNano(),
+		Digest:  digest(),
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	r.entries[key] = entry
+	r.dirty = true
+}
+
+// Forget removes file from index, used after the file is written
+func (x *Index) Forget(filePath string) {
+	x.mu.Lock()
+	r, key := x.find(filePath)
+	x.mu.Unlock()
+	if r != nil {
+		x.forget(r, key)
+	}
+}
+
+// forget removes entry from index of the directory
+func (x *Index) forget(r *root, key string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if _, ok := r.entries[key]; ok {
+		delete(r.entries, key)
+		r.dirty = true
+	}
+}
+
+// find returns innermost opened directory containing the file and key of the file in its index
+func (x *Index) find(filePath string) (*root, string) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, ""
+	}
+
+	var found *root
+	key := ""
+	for _, r := range x.roots {
+		rel, err := filepath.Rel(r.dir, absPath)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if found == nil || len(r.dir) > len(found.dir) {
+			found = r
+			key = filepath.ToSlash(rel)
+		}
+	}
+	return found, key
+}
+
+// digest hashes normalized and raw content of file, and for .mdc files also its content without YAML header
+func (x *Index) digest(filePath string, data []byte) Digest {
+	content := file_ops.NormalizeContent(data)
+	d := Digest{Content: hash(content), Raw: hash(string(data))}
+	if strings.EqualFold(filepath.Ext(filePath), mdcExtension) {
+		d.Body = hash(x.headerService.RemoveHeaderFromContent(content))
+	}
+	return d
+}
+
+// storePath returns path of the stored index of the directory
+func (x *Index) storePath(dir string) string {
+	sum := sha256.Sum256([]byte(dir))
+	return filepath.Join(x.storeDir, filepath.Base(dir)+"-"+hex.EncodeToString(sum[:])[:12]+".json")
+}
+
+// trusted checks if the file still has recorded size and modification time, and that it was modified
+// well before it was hashed, so a later change within the same modification time tick cannot go unnoticed.
+// Modification and hashing times in the future mean clocks disagree and the entry is not trusted
+func (e Entry) trusted(info os.FileInfo, now time.Time) bool {
+	if info.Size() != e.Size || info.ModTime().UnixNano() != e.ModTime {
+		return false
+	}
+	if e.Hashed > now.UnixNano() {
+		return false
+	}
+	return e.ModTime <= e.Hashed-int64(racyWindow)
+}
+
+// hash returns hex encoded SHA-256 of the content
+func hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,204 @@
+package index_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/index"
+)
+
+// writeFile writes file with modification time shifted from now and returns its stat information
+func writeFile(t *testing.T, path, content string, age time.Duration) os.FileInfo {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	modTime := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	return info
+}
+
+func TestIndex(t *testing.T) {
+	t.Run("looks up recorded digest of unchanged file", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		x := index.NewIndex(t.TempDir())
+		require.NoError(t, x.Open(dir))
+
+		file1 := filepath.Join(dir, "a.mdc")
+		file2 := filepath.Join(dir, "sub", "b.mdc")
+		require.NoError(t, os.MkdirAll(filepath.Dir(file2), 0755))
+		info1 := writeFile(t, file1, "---\nglobs: a\n---\nbody\n", time.Hour)
+		info2 := writeFile(t, file2, "---\nglobs: b\n---\r\nbody\r\n", time.Hour)
+		x.Record(file1, info1, []byte("---\nglobs: a\n---\nbody\n"))
+		x.Record(file2, info2, []byte("---\nglobs: b\n---\r\nbody\r\n"))
+
+		digest1, ok := x.Lookup(file1)
+		require.True(t, ok)
+		digest2, ok := x.Lookup(file2)
+		require.True(t, ok)
+
+		require.NotEqual(t, digest1.Content, digest2.Content)
+		require.NotEqual(t, digest1.Raw, digest2.Raw)
+		if diff := cmp.Diff(digest1.Body, digest2.Body); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("looks up recorded hash of binary file", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		x := index.NewIndex(t.TempDir())
+		require.NoError(t, x.Open(dir))
+
+		file := filepath.Join(dir, "logo.png")
+		info := writeFile(t, file, "\x89PNG\x00", time.Hour)
+		x.RecordBinary(file, info, "hash")
+
+		digest, ok := x.Lookup(file)
+		require.True(t, ok)
+		if diff := cmp.Diff(index.Digest{Content: "hash", Binary: true}, digest); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("persists index between operations", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		storeDir := t.TempDir()
+		file := filepath.Join(dir, "a.txt")
+		info := writeFile(t, file, "content", time.Hour)
+
+		x := index.NewIndex(storeDir)
+		require.NoError(t, x.Open(dir))
+		x.Record(file, info, []byte("content"))
+		want, ok := x.Lookup(file)
+		require.True(t, ok)
+		require.NoError(t, x.Save())
+
+		_, ok = x.Lookup(file)
+		require.False(t, ok, "closed index must not be used")
+
+		reopened := index.NewIndex(storeDir)
+		require.NoError(t, reopened.Open(dir))
+		got, ok := reopened.Lookup(file)
+		require.True(t, ok)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("does not trust changed files", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		x := index.NewIndex(t.TempDir())
+		require.NoError(t, x.Open(dir))
+
+		file := filepath.Join(dir, "a.txt")
+		info := writeFile(t, file, "content", time.Hour)
+		x.Record(file, info, []byte("content"))
+		writeFile(t, file, "changed", 2*time.Hour)
+
+		_, ok := x.Lookup(file)
+		require.False(t, ok)
+	})
+
+	t.Run("does not trust files modified shortly before hashing", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		x := index.NewIndex(t.TempDir())
+		require.NoError(t, x.Open(dir))
+
+		file := filepath.Join(dir, "a.txt")
+		info := writeFile(t, file, "content", 0)
+		x.Record(file, info, []byte("content"))
+
+		_, ok := x.Lookup(file)
+		require.False(t, ok)
+	})
+
+	t.Run("does not trust files modified in the future", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		x := index.NewIndex(t.TempDir())
+		require.NoError(t, x.Open(dir))
+
+		file := filepath.Join(dir, "a.txt")
+		info := writeFile(t, file, "content", -time.Hour)
+		x.Record(file, info, []byte("content"))
+
+		_, ok := x.Lookup(file)
+		require.False(t, ok)
+	})
+
+	t.Run("does not record file changed while it was read", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		x := index.NewIndex(t.TempDir())
+		require.NoError(t, x.Open(dir))
+
+		file := filepath.Join(dir, "a.txt")
+		before := writeFile(t, file, "content", time.Hour)
+		writeFile(t, file, "content", 2*time.Hour)
+		x.Record(file, before, []byte("content"))
+
+		_, ok := x.Lookup(file)
+		require.False(t, ok)
+	})
+
+	t.Run("forgets written files", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		x := index.NewIndex(t.TempDir())
+		require.NoError(t, x.Open(dir))
+
+		file := filepath.Join(dir, "a.txt")
+		info := writeFile(t, file, "content", time.Hour)
+		x.Record(file, info, []byte("content"))
+		x.Forget(file)
+
+		_, ok := x.Lookup(file)
+		require.False(t, ok)
+	})
+
+	t.Run("ignores files outside of opened directories", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		x := index.NewIndex(t.TempDir())
+		require.NoError(t, x.Open(filepath.Join(dir, "rules")))
+
+		file := filepath.Join(dir, "a.txt")
+		info := writeFile(t, file, "content", time.Hour)
+		x.Record(file, info, []byte("content"))
+
+		_, ok := x.Lookup(file)
+		require.False(t, ok)
+	})
+
+	t.Run("starts over corrupted index", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		storeDir := t.TempDir()
+		file := filepath.Join(dir, "a.txt")
+		info := writeFile(t, file, "content", time.Hour)
+
+		x := index.NewIndex(storeDir)
+		require.NoError(t, x.Open(dir))
+		x.Record(file, info, []byte("content"))
+		require.NoError(t, x.Save())
+
+		stored, err := filepath.Glob(filepath.Join(storeDir, "*.json"))
+		require.NoError(t, err)
+		require.Len(t, stored, 1)
+		require.NoError(t, os.WriteFile(stored[0], []byte("{broken"), 0600))
+
+		require.NoError(t, x.Open(dir))
+		_, ok := x.Lookup(file)
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,101 @@
+package manifest
+
+import "path/filepath"
+
+// FileEntry holds stat information of a file recorded at sync time
+type FileEntry struct {
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"mod_time"`
+}
+
+// Manifest holds the state of the project rules directory after the last pull
+type Manifest struct {
+	RulesDir         string               `json:"rules_dir"`
+	Commit           string               `json:"commit,omitempty"`
+	FilePatterns     string               `json:"file_patterns,omitempty"`
+	OverwriteHeaders bool                 `json:"overwrite_headers,omitempty"`
+	FileMode         uint32               `json:"file_mode,omitempty"`
+	Symlinks         string               `json:"symlinks,omitempty"`
+	DeleteMode       string               `json:"delete_mode,omitempty"`
+	LineEndings      string               `json:"line_endings,omitempty"`
+	AllTextFiles     bool                 `json:"all_text_files,omitempty"`
+	Compare          string               `json:"compare,omitempty"`
+	Linked           bool                 `json:"linked,omitempty"`       // files were pulled as symlinks by pull --link
+	SourceLinks      bool                 `json:"source_links,omitempty"` // rules directory contains symlinks
+	Files            map[string]FileEntry `json:"files"`
+	// Managed maps slash-separated relative paths of files synced by cursync to rules directory they come from
+	Managed map[string]string `json:"managed,omitempty"`
+}
+
+// MatchesSnapshot checks if files recorded in manifest are identical to the snapshot
+func (m *Manifest) MatchesSnapshot(snapshot map[string]FileEntry) bool {
+	if len(m.Files) != len(snapshot) {
+		return false
+	}
+
+	for relativePath, entry := range snapshot {
+		recorded, ok := m.Files[relativePath]
+		if !ok || recorded != entry {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ManagedFiles returns files managed on behalf of the rules directory, empty for nil manifest
+func (m *Manifest) ManagedFiles(rulesDir string) map[string]bool {
+	managed := make(map[string]bool)
+	if m == nil {
+		return managed
+	}
+	rulesDir = filepath.Clean(rulesDir)
+	for relativePath, source := range m.Managed {
+		if filepath.Clean(source) == rulesDir {
+			managed[relativePath] = true
+		}
+	}
+	return managed
+}
+
+// Owner returns rules directory the file is managed on behalf of and reports if the file is managed
+func (m *Manifest) Owner(relativePath string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	rulesDir, ok := m.Managed[relativePath]
+	return rulesDir, ok
+}
+
+// Manage records files as managed on behalf of the rules directory
+func (m *Manifest) Manage(rulesDir string, files ...string) {
+	if len(files) == 0 {
+		return
+	}
+	if m.Managed == nil {
+		m.Managed = make(map[string]string, len(files))
+	}
+	rulesDir = filepath.Clean(rulesDir)
+	for _, relativePath := range files {
+		m.Managed[relativePath] = rulesDir
+	}
+}
+
+// Unmanage forgets managed files
+func (m *Manifest) Unmanage(files ...string) {
+	for _, relativePath := range files {
+		delete(m.Managed, relativePath)
+	}
+	if len(m.Managed) == 0 {
+		m.Managed = nil
+	}
+}
+
+// RetainManaged forgets managed files missing in the snapshot
+func (m *Manifest) RetainManaged(snapshot map[string]FileEntry) {
+	for relativePath := range m.Managed {
+		if _, ok := snapshot[relativePath]; !ok {
+			m.Unmanage(relativePath)
+		}
+	}
+}
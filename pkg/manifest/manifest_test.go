@@ -0,0 +1,141 @@
+package manifest_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
+)
+
+func TestManifestLoadSave(t *testing.T) {
+	t.Parallel()
+
+	projectRoot := t.TempDir()
+	repo := manifest.NewManifestRepository()
+	m := &manifest.Manifest{
+		RulesDir:     "/path/to/rules",
+		Commit:       "abc123",
+		FilePatterns: "*.mdc",
+		Files: map[string]manifest.FileEntry{
+			"dir/file.mdc": {Size: 10, ModTime: 100},
+		},
+		Managed: map[string]string{"dir/file.mdc": "/path/to/rules"},
+	}
+
+	require.NoError(t, repo.Save(projectRoot, m))
+
+	loaded, err := repo.Load(projectRoot)
+	require.NoError(t, err)
+
+	if diff := cmp.Diff(m, loaded); diff != "" {
+		t.Errorf("Manifest mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestManifestLoadNonExistent(t *testing.T) {
+	t.Parallel()
+
+	repo := manifest.NewManifestRepository()
+	loaded, err := repo.Load(t.TempDir())
+	require.NoError(t, err)
+	require.Nil(t, loaded)
+}
+
+func TestManifestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "file.mdc"), []byte("content"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref"), 0600))
+
+	repo := manifest.NewManifestRepository()
+	snapshot, err := repo.Snapshot(dir)
+	require.NoError(t, err)
+
+	require.Len(t, snapshot, 1)
+	require.Equal(t, int64(len("content")), snapshot["sub/file.mdc"].Size)
+}
+
+func TestManifest_MatchesSnapshot(t *testing.T) {
+	t.Parallel()
+
+	m := &manifest.Manifest{
+		Files: map[string]manifest.FileEntry{
+			"file.mdc": {Size: 10, ModTime: 100},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		snapshot map[string]manifest.FileEntry
+		expected bool
+	}{
+		{
+			name:     "identical",
+			snapshot: map[string]manifest.FileEntry{"file.mdc": {Size: 10, ModTime: 100}},
+			expected: true,
+		},
+		{
+			name:     "modified file",
+			snapshot: map[string]manifest.FileEntry{"file.mdc": {Size: 10, ModTime: 101}},
+			expected: false,
+		},
+		{
+			name:     "added file",
+			snapshot: map[string]manifest.FileEntry{"file.mdc": {Size: 10, ModTime: 100}, "new.mdc": {Size: 1, ModTime: 1}},
+			expected: false,
+		},
+		{
+			name:     "removed file",
+			snapshot: map[string]manifest.FileEntry{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, m.MatchesSnapshot(tt.snapshot)); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestManifest_Managed(t *testing.T) {
+	t.Parallel()
+
+	var missing *manifest.Manifest
+	require.Empty(t, missing.ManagedFiles("/rules"))
+	_, ok := missing.Owner("a.mdc")
+	require.False(t, ok)
+
+	m := &manifest.Manifest{}
+	m.Manage("/rules", "a.mdc", "dir/b.mdc")
+	m.Manage("/other", "c.mdc")
+
+	if diff := cmp.Diff(map[string]bool{"a.mdc": true, "dir/b.mdc": true}, m.ManagedFiles("/rules")); diff != "" {
+		t.Errorf("ManagedFiles mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(m.ManagedFiles("/rules"), m.ManagedFiles("/rules/")); diff != "" {
+		t.Errorf("ManagedFiles with trailing slash mismatch (-want +got):\n%s", diff)
+	}
+
+	owner, ok := m.Owner("c.mdc")
+	require.True(t, ok)
+	require.Equal(t, "/other", owner)
+
+	m.RetainManaged(map[string]manifest.FileEntry{"a.mdc": {}, "c.mdc": {}})
+	if diff := cmp.Diff(map[string]string{"a.mdc": "/rules", "c.mdc": "/other"}, m.Managed); diff != "" {
+		t.Errorf("Managed mismatch (-want +got):\n%s", diff)
+	}
+
+	m.Unmanage("a.mdc", "c.mdc")
+	require.Nil(t, m.Managed)
+}
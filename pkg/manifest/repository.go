@@ -0,0 +1,101 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
+)
+
+const (
+	cursorDirName    = ".cursor"
+	manifestFileName = "cursync-manifest.json"
+	gitDirName       = ".git"
+)
+
+// ManifestRepository handles loading and saving of project manifests
+type ManifestRepository struct{}
+
+// NewManifestRepository creates a new ManifestRepository
+func NewManifestRepository() *ManifestRepository {
+	return &ManifestRepository{}
+}
+
+// GetManifestPath returns the path to the manifest file of the project
+func (r *ManifestRepository) GetManifestPath(projectRoot string) string {
+	return filepath.Join(projectRoot, cursorDirName, manifestFileName)
+}
+
+// Load loads manifest of the project, returns nil if project has no manifest yet
+func (r *ManifestRepository) Load(projectRoot string) (*Manifest, error) {
+	data, err := os.ReadFile(r.GetManifestPath(projectRoot))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	return m, nil
+}
+
+// Save saves manifest of the project
+func (r *ManifestRepository) Save(projectRoot string, m *Manifest) error {
+	manifestPath := r.GetManifestPath(projectRoot)
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	if err := file_ops.WriteFileAtomic(manifestPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// Snapshot collects stat information of all files in directory keyed by slash-separated relative path
+func (r *ManifestRepository) Snapshot(dir string) (map[string]FileEntry, error) {
+	snapshot := make(map[string]FileEntry)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == gitDirName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if file_ops.IsTempFile(info.Name()) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		snapshot[filepath.ToSlash(rel)] = FileEntry{
+			Size:    info.Size(),
+			ModTime: info.ModTime().UnixNano(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error taking snapshot of %s: %w", dir, err)
+	}
+
+	return snapshot, nil
+}
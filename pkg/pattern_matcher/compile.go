@@ -0,0 +1,143 @@
+package pattern_matcher
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+)
+
+// RegexPrefix marks patterns holding regular expression matched against slash-separated relative path
+const RegexPrefix = "re:"
+
+// CompilePattern converts gitignore-style pattern to regular expression matching slash-separated relative paths.
+// A leading "!" negates the pattern, a leading or middle "/" anchors it to the base directory,
+// a trailing "/" matches only directories and "**" matches any number of directories.
+// A pattern matching a directory matches every file inside it. Patterns prefixed with "re:" are used as is
+func CompilePattern(pattern string) (models.IgnorePattern, error) {
+	compiled := models.IgnorePattern{Pattern: pattern}
+
+	body := strings.TrimSpace(pattern)
+	if strings.HasPrefix(body, ExcludePrefix) {
+		compiled.IsNegation = true
+		body = strings.TrimPrefix(body, ExcludePrefix)
+	} else if strings.HasPrefix(body, `\!`) || strings.HasPrefix(body, `\#`) {
+		body = body[1:]
+	}
+
+	if IsRegex(body) {
+		compiled.Regex = strings.TrimPrefix(body, RegexPrefix)
+		if _, err := regexp.Compile(compiled.Regex); err != nil {
+			return compiled, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+		}
+		return compiled, nil
+	}
+
+	dirOnly := strings.HasSuffix(body, "/")
+	body = strings.TrimRight(body, "/")
+	if body == "" {
+		return compiled, fmt.Errorf("empty pattern %q", pattern)
+	}
+
+	anchored := strings.Contains(body, "/")
+	body = strings.TrimPrefix(body, "/")
+
+	globRegex, err := globToRegex(body)
+	if err != nil {
+		return compiled, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	prefix := "^"
+	if !anchored {
+		prefix = "^(?:.*/)?"
+	}
+	suffix := "(?:/.*)?$"
+	if dirOnly {
+		suffix = "/.*$"
+	}
+
+	compiled.Regex = prefix + globRegex + suffix
+	if _, err := regexp.Compile(compiled.Regex); err != nil {
+		return compiled, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return compiled, nil
+}
+
+// IsRegex checks if pattern, possibly negated, holds regular expression
+func IsRegex(pattern string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(pattern, ExcludePrefix), RegexPrefix)
+}
+
+// globToRegex converts glob to regular expression, "*" and "?" don't match "/",
+// "**" between slashes matches any number of directories
+func globToRegex(glob string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' && (i == 0 || glob[i-1] == '/') {
+				switch {
+				case i+2 == len(glob):
+					sb.WriteString(".*")
+					i++
+					continue
+				case glob[i+2] == '/':
+					sb.WriteString("(?:.*/)?")
+					i += 2
+					continue
+				}
+			}
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end, class, err := bracketToRegex(glob, i)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(class)
+			i = end
+		case '\\':
+			if i+1 == len(glob) {
+				return "", fmt.Errorf("trailing backslash")
+			}
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String(), nil
+}
+
+// bracketToRegex converts bracket expression starting at start to character class,
+// returns index of the closing bracket
+func bracketToRegex(glob string, start int) (int, string, error) {
+	var sb strings.Builder
+	sb.WriteString("[")
+
+	i := start + 1
+	if i < len(glob) && (glob[i] == '!' || glob[i] == '^') {
+		sb.WriteString("^/")
+		i++
+	}
+	for first := true; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == ']' && !first:
+			sb.WriteString("]")
+			return i, sb.String(), nil
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+		case c == '[' || c == ']' || c == '^':
+			sb.WriteString(`\` + string(c))
+		default:
+			sb.WriteByte(c)
+		}
+		first = false
+	}
+	return 0, "", fmt.Errorf("unclosed character class")
+}
@@ -0,0 +1,35 @@
+package pattern_matcher
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IgnoreFileName is the name of the file listing gitignore-style patterns of files excluded from sync
+const IgnoreFileName = ".cursyncignore"
+
+// ParseIgnoreFile returns patterns of ignore file content skipping blank lines and comments,
+// malformed patterns are reported with their line number
+func ParseIgnoreFile(content string) ([]string, error) {
+	patterns := []string{}
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := CompilePattern(line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, nil
+}
+
+// NewIgnoreMatcher compiles ignore patterns validated by ParseIgnoreFile, unlike NewMatcher
+// a path matched by no pattern is not ignored
+func NewIgnoreMatcher(patterns []string) *Matcher {
+	m := NewLenientMatcher(patterns)
+	// unmatched paths are selected only by a matcher without include patterns
+	m.hasIncludes = true
+	return m
+}
@@ -2,45 +2,140 @@ package pattern_matcher
 
 import (
 	"path/filepath"
+	"regexp"
 	"strings"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
 )
 
-// MatchesPattern checks if file path matches the pattern
-func MatchesPattern(filePath, pattern string) bool {
-	normalizedPath := filepath.ToSlash(filePath)
-	normalizedPattern := filepath.ToSlash(pattern)
+// ExcludePrefix marks negated patterns
+const ExcludePrefix = "!"
+
+// Matcher matches relative file paths against gitignore-style patterns
+type Matcher struct {
+	patterns    []models.IgnorePattern
+	regexps     []*regexp.Regexp
+	hasIncludes bool
+}
+
+// NewMatcher compiles patterns in order, later patterns take precedence over earlier ones
+func NewMatcher(patterns []string) (*Matcher, error) {
+	m := &Matcher{}
+	for _, pattern := range patterns {
+		compiled, err := CompilePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		m.add(compiled)
+	}
+	return m, nil
+}
 
-	// Check full path
-	if match, err := filepath.Match(normalizedPattern, normalizedPath); err == nil && match {
-		return true
+// NewLenientMatcher compiles patterns like NewMatcher skipping invalid ones
+func NewLenientMatcher(patterns []string) *Matcher {
+	m := &Matcher{}
+	for _, pattern := range patterns {
+		if compiled, err := CompilePattern(pattern); err == nil {
+			m.add(compiled)
+		}
 	}
+	return m
+}
 
-	// Check filename
-	filename := filepath.Base(normalizedPath)
-	if match, err := filepath.Match(normalizedPattern, filename); err == nil && match {
-		return true
+// add appends compiled pattern
+func (m *Matcher) add(compiled models.IgnorePattern) {
+	m.patterns = append(m.patterns, compiled)
+	m.regexps = append(m.regexps, regexp.MustCompile(compiled.Regex))
+	if !compiled.IsNegation {
+		m.hasIncludes = true
 	}
+}
 
-	// Check each path part separately
-	pathParts := strings.Split(normalizedPath, "/")
-	for _, part := range pathParts {
-		if part != "" {
-			if match, err := filepath.Match(normalizedPattern, part); err == nil && match {
-				return true
-			}
+// Matches checks if file path is selected by patterns: the last matching pattern wins,
+// a path matched by no pattern is selected only when there are no include patterns
+func (m *Matcher) Matches(filePath string) bool {
+	normalizedPath := strings.TrimPrefix(filepath.ToSlash(filePath), "/")
+
+	selected := !m.hasIncludes
+	for i, re := range m.regexps {
+		if re.MatchString(normalizedPath) {
+			selected = !m.patterns[i].IsNegation
 		}
 	}
+	return selected
+}
 
-	return false
+// Unmatched returns patterns that match none of file paths, in order of compilation
+func (m *Matcher) Unmatched(filePaths []string) []string {
+	unmatched := []string{}
+	for i, re := range m.regexps {
+		matched := false
+		for _, filePath := range filePaths {
+			if re.MatchString(strings.TrimPrefix(filepath.ToSlash(filePath), "/")) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, m.patterns[i].Pattern)
+		}
+	}
+	return unmatched
 }
 
-// MatchesAnyPattern checks if file path matches any of the provided patterns
-func MatchesAnyPattern(filePath string, patterns []string) bool {
-	for _, pattern := range patterns {
-		if MatchesPattern(filePath, pattern) {
-			return true
+// ExpandBraces expands brace alternatives of a pattern, e.g. "*.{md,mdc}" to "*.md" and "*.mdc",
+// regular expression and pattern without balanced braces are returned as is
+func ExpandBraces(pattern string) []string {
+	if IsRegex(pattern) {
+		return []string{pattern}
+	}
+
+	open := strings.IndexByte(pattern, '{')
+	if open < 0 {
+		return []string{pattern}
+	}
+
+	depth := 0
+	for i := open; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return expandAlternatives(pattern[:open], pattern[open+1:i], pattern[i+1:])
+			}
 		}
 	}
+	return []string{pattern}
+}
+
+// expandAlternatives combines prefix and suffix with each comma-separated alternative
+func expandAlternatives(prefix, alternatives, suffix string) []string {
+	result := []string{}
+	for _, alternative := range splitAlternatives(alternatives) {
+		result = append(result, ExpandBraces(prefix+alternative+suffix)...)
+	}
+	return result
+}
 
-	return false
+// splitAlternatives splits brace content by commas outside of nested braces keeping empty alternatives
+func splitAlternatives(alternatives string) []string {
+	result := []string{}
+	depth := 0
+	start := 0
+	for i := 0; i < len(alternatives); i++ {
+		switch alternatives[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, alternatives[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(result, alternatives[start:])
 }
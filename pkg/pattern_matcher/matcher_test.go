@@ -1,13 +1,15 @@
 package pattern_matcher_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/yanodintsovmercuryo/cursync/models"
 	"github.com/yanodintsovmercuryo/cursync/pkg/pattern_matcher"
 )
 
-func TestMatchesPattern(t *testing.T) {
+func TestMatcher_SinglePattern(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
@@ -52,13 +54,71 @@ func TestMatchesPattern(t *testing.T) {
 			pattern:  "sub",
 			expected: true,
 		},
+		{
+			name:     "star does not cross directories",
+			filePath: "team/sub/file.mdc",
+			pattern:  "team/*.mdc",
+			expected: false,
+		},
+		{
+			name:     "globstar crosses directories",
+			filePath: "team/sub/file.mdc",
+			pattern:  "team/**/*.mdc",
+			expected: true,
+		},
+		{
+			name:     "trailing globstar matches everything inside",
+			filePath: "team/sub/file.mdc",
+			pattern:  "team/**",
+			expected: true,
+		},
+		{
+			name:     "leading globstar matches at any depth",
+			filePath: "a/b/go/file.mdc",
+			pattern:  "**/go/*.mdc",
+			expected: true,
+		},
+		{
+			name:     "leading slash anchors to base directory",
+			filePath: "dir/go/file.mdc",
+			pattern:  "/go",
+			expected: false,
+		},
+		{
+			name:     "anchored pattern matches at base directory",
+			filePath: "go/file.mdc",
+			pattern:  "/go",
+			expected: true,
+		},
+		{
+			name:     "trailing slash matches directory",
+			filePath: "dir/go/file.mdc",
+			pattern:  "go/",
+			expected: true,
+		},
+		{
+			name:     "trailing slash does not match file",
+			filePath: "dir/go",
+			pattern:  "go/",
+			expected: false,
+		},
+		{
+			name:     "character class",
+			filePath: "file1.mdc",
+			pattern:  "file[0-9].mdc",
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			result := pattern_matcher.MatchesPattern(tt.filePath, tt.pattern)
+			matcher, err := pattern_matcher.NewMatcher([]string{tt.pattern})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result := matcher.Matches(tt.filePath)
 
 			if diff := cmp.Diff(tt.expected, result); diff != "" {
 				t.Fatalf("mismatch (-want +got):\n%s", diff)
@@ -67,7 +127,7 @@ func TestMatchesPattern(t *testing.T) {
 	}
 }
 
-func TestMatchesAnyPattern(t *testing.T) {
+func TestMatcher_IncludePatterns(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
@@ -95,9 +155,73 @@ func TestMatchesAnyPattern(t *testing.T) {
 			expected: false,
 		},
 		{
-			name:     "empty patterns",
+			name:     "empty patterns select every file",
 			filePath: "file.txt",
 			patterns: []string{},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			matcher, err := pattern_matcher.NewMatcher(tt.patterns)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result := matcher.Matches(tt.filePath)
+
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMatcher_Negations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filePath string
+		patterns []string
+		expected bool
+	}{
+		{
+			name:     "matches include pattern",
+			filePath: "team/file.mdc",
+			patterns: []string{"team", "!*.md"},
+			expected: true,
+		},
+		{
+			name:     "excluded by exclude pattern",
+			filePath: "team/file.md",
+			patterns: []string{"team", "!*.md"},
+			expected: false,
+		},
+		{
+			name:     "only exclude patterns",
+			filePath: "shared/file.mdc",
+			patterns: []string{"!draft_*"},
+			expected: true,
+		},
+		{
+			name:     "not matching include pattern",
+			filePath: "shared/file.mdc",
+			patterns: []string{"team"},
+			expected: false,
+		},
+		{
+			name:     "last matching pattern wins",
+			filePath: "team/draft_keep.mdc",
+			patterns: []string{"team/**", "!team/draft_*", "team/draft_keep.mdc"},
+			expected: true,
+		},
+		{
+			name:     "negation re-excludes",
+			filePath: "team/draft_other.mdc",
+			patterns: []string{"team/**", "!team/draft_*", "team/draft_keep.mdc"},
 			expected: false,
 		},
 	}
@@ -106,7 +230,11 @@ func TestMatchesAnyPattern(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			result := pattern_matcher.MatchesAnyPattern(tt.filePath, tt.patterns)
+			matcher, err := pattern_matcher.NewMatcher(tt.patterns)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result := matcher.Matches(tt.filePath)
 
 			if diff := cmp.Diff(tt.expected, result); diff != "" {
 				t.Fatalf("mismatch (-want +got):\n%s", diff)
@@ -114,3 +242,194 @@ func TestMatchesAnyPattern(t *testing.T) {
 		})
 	}
 }
+
+func TestNewMatcher_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := pattern_matcher.NewMatcher([]string{"*.mdc", "file[.mdc"})
+	if err == nil {
+		t.Fatal("expected error for malformed pattern")
+	}
+}
+
+func TestCompilePattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		pattern  string
+		expected models.IgnorePattern
+		wantErr  bool
+	}{
+		{
+			name:     "unanchored pattern",
+			pattern:  "*.mdc",
+			expected: models.IgnorePattern{Pattern: "*.mdc", Regex: `^(?:.*/)?[^/]*\.mdc(?:/.*)?$`},
+		},
+		{
+			name:     "negated anchored directory",
+			pattern:  "!/team/",
+			expected: models.IgnorePattern{Pattern: "!/team/", Regex: `^team/.*$`, IsNegation: true},
+		},
+		{
+			name:     "escaped negation",
+			pattern:  `\!important.mdc`,
+			expected: models.IgnorePattern{Pattern: `\!important.mdc`, Regex: `^(?:.*/)?!important\.mdc(?:/.*)?$`},
+		},
+		{
+			name:     "regular expression",
+			pattern:  `!re:^draft_\d+\.mdc$`,
+			expected: models.IgnorePattern{Pattern: `!re:^draft_\d+\.mdc$`, Regex: `^draft_\d+\.mdc$`, IsNegation: true},
+		},
+		{
+			name:    "invalid regular expression",
+			pattern: "re:(draft",
+			wantErr: true,
+		},
+		{
+			name:    "unclosed character class",
+			pattern: "[abc",
+			wantErr: true,
+		},
+		{
+			name:    "empty pattern",
+			pattern: "!",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := pattern_matcher.CompilePattern(tt.pattern)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tt.pattern)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExpandBraces(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		pattern  string
+		expected []string
+	}{
+		{
+			name:     "no braces",
+			pattern:  "*.mdc",
+			expected: []string{"*.mdc"},
+		},
+		{
+			name:     "single group",
+			pattern:  "*.{md,mdc}",
+			expected: []string{"*.md", "*.mdc"},
+		},
+		{
+			name:     "multiple and nested groups",
+			pattern:  "{a,b{1,2}}/*.{md,mdc}",
+			expected: []string{"a/*.md", "a/*.mdc", "b1/*.md", "b1/*.mdc", "b2/*.md", "b2/*.mdc"},
+		},
+		{
+			name:     "regular expression",
+			pattern:  "re:^a{1,2}$",
+			expected: []string{"re:^a{1,2}$"},
+		},
+		{
+			name:     "unbalanced braces",
+			pattern:  "*.{md,mdc",
+			expected: []string{"*.{md,mdc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := pattern_matcher.ExpandBraces(tt.pattern)
+
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseIgnoreFile(t *testing.T) {
+	t.Parallel()
+
+	content := "# shared repository files\nREADME.md  \n\n!docs/keep.md\nscripts/\n"
+	expected := []string{"README.md", "!docs/keep.md", "scripts/"}
+
+	result, err := pattern_matcher.ParseIgnoreFile(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseIgnoreFile_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := pattern_matcher.ParseIgnoreFile("README.md\n\ndocs/[a\n")
+	if err == nil {
+		t.Fatal("expected error for malformed pattern")
+	}
+	if diff := cmp.Diff(true, strings.Contains(err.Error(), "line 3")); diff != "" {
+		t.Fatalf("expected line number in %q", err)
+	}
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	t.Parallel()
+
+	matcher := pattern_matcher.NewIgnoreMatcher([]string{"docs/", "!docs/keep.md"})
+
+	tests := []struct {
+		filePath string
+		expected bool
+	}{
+		{filePath: "docs/readme.md", expected: true},
+		{filePath: "docs/keep.md", expected: false},
+		{filePath: "rule.mdc", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filePath, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, matcher.Matches(tt.filePath)); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMatcher_Unmatched(t *testing.T) {
+	t.Parallel()
+
+	matcher, err := pattern_matcher.NewMatcher([]string{"*.mdc", "re:^team/", "!draft_*"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := matcher.Unmatched([]string{"team/rule.mdc"})
+	if diff := cmp.Diff([]string{"!draft_*"}, result); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
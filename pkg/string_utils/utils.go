@@ -20,6 +20,31 @@ func SplitTrimFilter(input, separator string) []string {
 	return result
 }
 
+// SplitOutsideBraces splits string by separator ignoring separators inside braces,
+// trims spaces and filters empty strings
+func SplitOutsideBraces(input string, separator rune) []string {
+	result := []string{}
+	depth := 0
+	start := 0
+	for i, r := range input {
+		switch {
+		case r == '{':
+			depth++
+		case r == '}' && depth > 0:
+			depth--
+		case r == separator && depth == 0:
+			if trimmed := strings.TrimSpace(input[start:i]); trimmed != "" {
+				result = append(result, trimmed)
+			}
+			start = i + len(string(separator))
+		}
+	}
+	if trimmed := strings.TrimSpace(input[start:]); trimmed != "" {
+		result = append(result, trimmed)
+	}
+	return result
+}
+
 // RemoveDuplicates preserves order while removing duplicates
 func RemoveDuplicates(items []string) []string {
 	if len(items) == 0 {
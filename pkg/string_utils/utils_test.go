@@ -61,6 +61,54 @@ func TestSplitTrimFilter(t *testing.T) {
 	}
 }
 
+func TestSplitOutsideBraces(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "simple list",
+			input:    "a, b,c",
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "keeps separators inside braces",
+			input:    "*.{md,mdc}, docs/{a,{b,c}}/*.md",
+			expected: []string{"*.{md,mdc}", "docs/{a,{b,c}}/*.md"},
+		},
+		{
+			name:     "unbalanced closing brace",
+			input:    "a},b",
+			expected: []string{"a}", "b"},
+		},
+		{
+			name:     "empty input",
+			input:    "",
+			expected: []string{},
+		},
+		{
+			name:     "only separators",
+			input:    " , ,",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := string_utils.SplitOutsideBraces(tt.input, ',')
+
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestRemoveDuplicates(t *testing.T) {
 	t.Parallel()
 
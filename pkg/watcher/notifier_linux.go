@@ -0,0 +1,128 @@
+//go:build linux
+
+package watcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
+)
+
+const watchMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_ATTRIB | syscall.IN_DELETE_SELF
+
+// notifier reports changes of directory trees using inotify
+type notifier struct {
+	fd    int            // Inotify descriptor, Fd of file is not used as it switches descriptor to blocking mode
+	file  *os.File       // Inotify descriptor read through runtime poller, so that Close interrupts Read
+	roots map[int]string // Root directory of each watch descriptor
+	paths map[int]string // Watched directory of each watch descriptor
+}
+
+// newNotifier creates inotify instance watching all directories of the trees
+func newNotifier(dirs []string) (*notifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &notifier{
+		fd:    fd,
+		file:  os.NewFile(uintptr(fd), "inotify"),
+		roots: make(map[int]string),
+		paths: make(map[int]string),
+	}
+	for _, dir := range dirs {
+		if err := n.addTree(dir, dir); err != nil {
+			n.file.Close()
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// addTree watches directory and its subdirectories, skipping .git directories
+func (n *notifier) addTree(root, dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if info.Name() == gitDirName {
+			return filepath.SkipDir
+		}
+
+		wd, err := syscall.InotifyAddWatch(n.fd, path, watchMask)
+		if err != nil {
+			return err
+		}
+		n.roots[wd] = root
+		n.paths[wd] = path
+		return nil
+	})
+}
+
+// run reads inotify events until ctx is done
+func (n *notifier) run(ctx context.Context, changes chan<- string) {
+	defer close(changes)
+
+	go func() {
+		<-ctx.Done()
+		n.file.Close()
+	}()
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		count, err := n.file.Read(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrClosed) || ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		n.handle(buf[:count], changes)
+	}
+}
+
+// handle reports roots of events in buffer and watches created directories
+func (n *notifier) handle(buf []byte, changes chan<- string) {
+	for offset := 0; offset+syscall.SizeofInotifyEvent <= len(buf); {
+		event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		nameStart := offset + syscall.SizeofInotifyEvent
+		name := strings.TrimRight(string(buf[nameStart:nameStart+int(event.Len)]), "\x00")
+		offset = nameStart + int(event.Len)
+
+		if event.Mask&syscall.IN_Q_OVERFLOW != 0 {
+			for _, root := range n.roots {
+				changes <- root
+			}
+			continue
+		}
+
+		if event.Mask&syscall.IN_IGNORED != 0 {
+			delete(n.roots, int(event.Wd))
+			delete(n.paths, int(event.Wd))
+			continue
+		}
+
+		root, ok := n.roots[int(event.Wd)]
+		if !ok || name == gitDirName || file_ops.IsTempFile(name) {
+			continue
+		}
+		if event.Mask&syscall.IN_ISDIR != 0 && event.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+			_ = n.addTree(root, filepath.Join(n.paths[int(event.Wd)], name))
+		}
+		changes <- root
+	}
+}
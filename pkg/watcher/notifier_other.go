@@ -0,0 +1,21 @@
+//go:build !linux
+
+package watcher
+
+import (
+	"context"
+	"errors"
+)
+
+// notifier is not available on this platform, directories are polled instead
+type notifier struct{}
+
+// newNotifier reports that inotify is not available
+func newNotifier(dirs []string) (*notifier, error) {
+	return nil, errors.New("inotify is not available")
+}
+
+// run is never called as newNotifier always fails
+func (n *notifier) run(ctx context.Context, changes chan<- string) {
+	close(changes)
+}
@@ -0,0 +1,160 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
+)
+
+const gitDirName = ".git"
+
+// Watcher reports changes of files in directory trees
+type Watcher struct {
+	pollInterval time.Duration
+	pollOnly     bool
+}
+
+// NewWatcher creates Watcher polling directories every pollInterval where inotify is not available
+func NewWatcher(pollInterval time.Duration) *Watcher {
+	return &Watcher{pollInterval: pollInterval}
+}
+
+// SetPolling makes Watcher poll directories even where inotify is available,
+// e.g. on network and container mounts where inotify misses changes
+func (w *Watcher) SetPolling(pollOnly bool) {
+	w.pollOnly = pollOnly
+}
+
+// Watch sends root directory to the returned channel whenever a file under it is created, changed or removed,
+// .git directories and temporary files of atomic writes are ignored. Inotify is used where available and
+// directories are polled otherwise. The channel is closed when ctx is done
+func (w *Watcher) Watch(ctx context.Context, dirs ...string) (<-chan string, error) {
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("failed to watch %s: not a directory", dir)
+		}
+	}
+
+	changes := make(chan string)
+	events := make(chan string)
+	go forward(changes, events)
+	if !w.pollOnly {
+		if n, err := newNotifier(dirs); err == nil {
+			go n.run(ctx, changes)
+			return events, nil
+		}
+	}
+
+	snapshots := make([]map[string]fileState, len(dirs))
+	for i, dir := range dirs {
+		snapshots[i] = snapshot(dir)
+	}
+	go w.poll(ctx, dirs, snapshots, changes)
+	return events, nil
+}
+
+// forward passes changes to events until changes is closed. Changes of a directory made while its previous
+// change waits to be received are merged into it, so that frequent changes of one directory never crowd out
+// changes of another one
+func forward(changes <-chan string, events chan<- string) {
+	defer close(events)
+
+	var queue []string
+	waiting := make(map[string]bool)
+	for {
+		var out chan<- string
+		var next string
+		if len(queue) > 0 {
+			out = events
+			next = queue[0]
+		}
+
+		select {
+		case dir, ok := <-changes:
+			if !ok {
+				return
+			}
+			if !waiting[dir] {
+				waiting[dir] = true
+				queue = append(queue, dir)
+			}
+		case out <- next:
+			delete(waiting, next)
+			queue = queue[1:]
+		}
+	}
+}
+
+// fileState holds stat information compared between polls
+type fileState struct {
+	size    int64
+	modTime time.Time
+	mode    os.FileMode
+}
+
+// poll compares snapshots of directories every poll interval until ctx is done
+func (w *Watcher) poll(ctx context.Context, dirs []string, snapshots []map[string]fileState, changes chan<- string) {
+	defer close(changes)
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for i, dir := range dirs {
+				current := snapshot(dir)
+				if !equalSnapshots(snapshots[i], current) {
+					snapshots[i] = current
+					changes <- dir
+				}
+			}
+		}
+	}
+}
+
+// snapshot collects state of all files in directory, unreadable entries are left out
+func snapshot(dir string) map[string]fileState {
+	files := make(map[string]fileState)
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() && info.Name() == gitDirName {
+			return filepath.SkipDir
+		}
+		if file_ops.IsTempFile(info.Name()) {
+			return nil
+		}
+		if info.IsDir() {
+			// Directory mtime changes with temporary files, so only its presence is compared
+			files[path] = fileState{mode: info.Mode()}
+			return nil
+		}
+		files[path] = fileState{size: info.Size(), modTime: info.ModTime(), mode: info.Mode()}
+		return nil
+	})
+	return files
+}
+
+// equalSnapshots checks if both snapshots hold the same files in the same state
+func equalSnapshots(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		if other, ok := b[path]; !ok || !other.modTime.Equal(state.modTime) || other.size != state.size || other.mode != state.mode {
+			return false
+		}
+	}
+	return true
+}
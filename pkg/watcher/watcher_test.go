@@ -0,0 +1,158 @@
+package watcher_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/watcher"
+)
+
+const (
+	testPollInterval = 10 * time.Millisecond
+	eventTimeout     = 2 * time.Second
+	quietPeriod      = 200 * time.Millisecond
+)
+
+// expectEvent waits for change of dir
+func expectEvent(t *testing.T, events <-chan string, dir string) {
+	t.Helper()
+	select {
+	case got := <-events:
+		require.Equal(t, dir, got)
+	case <-time.After(eventTimeout):
+		t.Fatalf("Expected change of %s", dir)
+	}
+}
+
+// drainEvents discards changes reported until events are quiet, one write may be reported several times
+func drainEvents(events <-chan string) {
+	for {
+		select {
+		case <-events:
+		case <-time.After(quietPeriod):
+			return
+		}
+	}
+}
+
+// collectEvents returns directories reported until events are quiet
+func collectEvents(events <-chan string) map[string]bool {
+	dirs := make(map[string]bool)
+	for {
+		select {
+		case dir := <-events:
+			dirs[dir] = true
+		case <-time.After(quietPeriod):
+			return dirs
+		}
+	}
+}
+
+// expectNoEvent checks that no change is reported for a while
+func expectNoEvent(t *testing.T, events <-chan string) {
+	t.Helper()
+	select {
+	case got := <-events:
+		t.Fatalf("Unexpected change of %s", got)
+	case <-time.After(quietPeriod):
+	}
+}
+
+func TestWatcher_Watch(t *testing.T) {
+	modes := map[string]bool{
+		"default": false,
+		"polling": true,
+	}
+
+	for name, polling := range modes {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := watcher.NewWatcher(testPollInterval)
+			w.SetPolling(polling)
+
+			projectDir := t.TempDir()
+			rulesDir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(rulesDir, ".git"), 0755))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			events, err := w.Watch(ctx, projectDir, rulesDir)
+			require.NoError(t, err)
+
+			require.NoError(t, os.WriteFile(filepath.Join(projectDir, "rule.mdc"), []byte("rule"), 0600))
+			expectEvent(t, events, projectDir)
+			drainEvents(events)
+
+			require.NoError(t, os.WriteFile(filepath.Join(rulesDir, ".git", "index"), []byte("index"), 0600))
+			require.NoError(t, os.WriteFile(filepath.Join(rulesDir, ".cursync-tmp-rule.mdc-1"), []byte("tmp"), 0600))
+			expectNoEvent(t, events)
+
+			require.NoError(t, os.MkdirAll(filepath.Join(rulesDir, "nested"), 0755))
+			expectEvent(t, events, rulesDir)
+			drainEvents(events)
+			require.NoError(t, os.WriteFile(filepath.Join(rulesDir, "nested", "rule.mdc"), []byte("rule"), 0600))
+			expectEvent(t, events, rulesDir)
+
+			cancel()
+			select {
+			case _, ok := <-events:
+				for ok {
+					_, ok = <-events
+				}
+			case <-time.After(eventTimeout):
+				t.Fatal("Expected events channel to be closed")
+			}
+		})
+	}
+
+	t.Run("missing directory", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := watcher.NewWatcher(testPollInterval).Watch(context.Background(), filepath.Join(t.TempDir(), "missing"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to watch")
+	})
+}
+
+func TestWatcher_WatchBusyDirectory(t *testing.T) {
+	modes := map[string]bool{
+		"default": false,
+		"polling": true,
+	}
+
+	for name, polling := range modes {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := watcher.NewWatcher(testPollInterval)
+			w.SetPolling(polling)
+
+			projectDir := t.TempDir()
+			rulesDir := t.TempDir()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			events, err := w.Watch(ctx, projectDir, rulesDir)
+			require.NoError(t, err)
+
+			// changes are not received while the project changes, the change of rules must not be lost
+			for i := range 5 {
+				require.NoError(t, os.WriteFile(filepath.Join(projectDir, "rule.mdc"), []byte{byte(i)}, 0600))
+				time.Sleep(3 * testPollInterval)
+			}
+			require.NoError(t, os.WriteFile(filepath.Join(rulesDir, "rule.mdc"), []byte("rule"), 0600))
+			time.Sleep(quietPeriod)
+
+			want := map[string]bool{projectDir: true, rulesDir: true}
+			if diff := cmp.Diff(want, collectEvents(events)); diff != "" {
+				t.Errorf("Changed directories mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+	"github.com/yanodintsovmercuryo/cursync/models"
+)
+
+// CreateBackupOptions creates BackupOptions with retention limits of backups made by pull and push
+func (s *CfgService) CreateBackupOptions(ctx *cli.Context) (*models.BackupOptions, error) {
+	resolved, err := s.ResolveOptions(ctx, OptionBackupCount, OptionBackupMaxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	maxCount, err := models.ParseBackupCount(resolved.Get(OptionBackupCount).String())
+	if err != nil {
+		return nil, err
+	}
+	maxAge, err := models.ParseBackupAge(resolved.Get(OptionBackupMaxAge).String())
+	if err != nil {
+		return nil, err
+	}
+	return &models.BackupOptions{MaxCount: maxCount, MaxAge: maxAge}, nil
+}
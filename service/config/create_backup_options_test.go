@@ -0,0 +1,65 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/config"
+)
+
+func TestCfgService_CreateBackupOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("uses defaults when not configured", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		result, err := f.cfgService.CreateBackupOptions(createCLIContext(t, map[string]interface{}{}))
+		require.NoError(t, err)
+
+		expected := &models.BackupOptions{MaxCount: 20, MaxAge: 30 * 24 * time.Hour}
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("uses config values", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{BackupCount: "0", BackupMaxAge: "12h"})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		result, err := f.cfgService.CreateBackupOptions(createCLIContext(t, map[string]interface{}{}))
+		require.NoError(t, err)
+
+		expected := &models.BackupOptions{MaxCount: 0, MaxAge: 12 * time.Hour}
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("error for invalid config value", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{BackupMaxAge: "month"})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		result, err := f.cfgService.CreateBackupOptions(createCLIContext(t, map[string]interface{}{}))
+		require.Error(t, err)
+		require.Nil(t, result)
+	})
+}
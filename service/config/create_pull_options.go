@@ -6,12 +6,27 @@ import (
 )
 
 // CreatePullOptions creates SyncOptions for pull command
-func (s *CfgService) CreatePullOptions(ctx *cli.Context) *models.SyncOptions {
-	cfg := s.configRepository.LoadOrDefault()
-	return &models.SyncOptions{
-		RulesDir:         s.getStringValue(ctx, FlagRulesDir, cfg),
+func (s *CfgService) CreatePullOptions(ctx *cli.Context) (*models.SyncOptions, error) {
+	names := []string{FlagRulesDir, FlagOverwriteHeaders, OptionPullInclude, OptionPullExclude}
+	resolved, err := s.ResolveOptions(ctx, append(names, syncOptionNames...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Bool(FlagExplain) {
+		s.printExplanation(resolved)
+	}
+
+	options := &models.SyncOptions{
+		RulesDir:         resolved.Get(FlagRulesDir).String(),
 		GitWithoutPush:   false,
-		OverwriteHeaders: s.getBoolValue(ctx, FlagOverwriteHeaders, cfg),
-		FilePatterns:     s.getStringValue(ctx, FlagFilePatterns, cfg),
+		OverwriteHeaders: resolved.Get(FlagOverwriteHeaders).Bool(),
+		FilePatterns:     resolved.Get(OptionPullInclude).Strings(),
+		ExcludePatterns:  resolved.Get(OptionPullExclude).Strings(),
+		Link:             ctx.Bool(FlagLink),
+	}
+	if err := parseSyncOptions(resolved, options); err != nil {
+		return nil, err
 	}
+	return options, nil
 }
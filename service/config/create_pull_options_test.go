@@ -1,9 +1,14 @@
 package config_test
 
 import (
+	"errors"
+	"os"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
 	"github.com/yanodintsovmercuryo/cursync/models"
 	"github.com/yanodintsovmercuryo/cursync/pkg/config"
 	cfgService "github.com/yanodintsovmercuryo/cursync/service/config"
@@ -18,10 +23,9 @@ func TestCfgService_CreatePullOptions(t *testing.T) {
 		defer finish()
 
 		cfg := &config.Config{}
-		f.configRepositoryMock.EXPECT().
-			LoadOrDefault().
-			Return(cfg).
-			Times(1)
+		f.expectGlobalConfig(cfg)
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
 
 		ctx := createCLIContext(t, map[string]interface{}{
 			cfgService.FlagRulesDir:         "/custom/rules",
@@ -29,13 +33,17 @@ func TestCfgService_CreatePullOptions(t *testing.T) {
 			cfgService.FlagOverwriteHeaders: true,
 		})
 
-		result := f.cfgService.CreatePullOptions(ctx)
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.NoError(t, err)
 
 		expected := &models.SyncOptions{
 			RulesDir:         "/custom/rules",
-			FilePatterns:     "*.mdc",
+			FilePatterns:     []string{"*.mdc"},
 			OverwriteHeaders: true,
 			GitWithoutPush:   false,
+			DeleteMode:       models.DeleteManaged,
+			Symlinks:         models.SymlinkFollow,
+			Normalization:    models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
 		}
 
 		if diff := cmp.Diff(expected, result); diff != "" {
@@ -50,23 +58,26 @@ func TestCfgService_CreatePullOptions(t *testing.T) {
 
 		cfg := &config.Config{
 			RulesDir:         "/default/rules",
-			FilePatterns:     "default.mdc",
+			FilePatterns:     []string{"default.mdc"},
 			OverwriteHeaders: true,
 		}
-		f.configRepositoryMock.EXPECT().
-			LoadOrDefault().
-			Return(cfg).
-			Times(1)
+		f.expectGlobalConfig(cfg)
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
 
 		ctx := createCLIContext(t, map[string]interface{}{})
 
-		result := f.cfgService.CreatePullOptions(ctx)
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.NoError(t, err)
 
 		expected := &models.SyncOptions{
 			RulesDir:         "/default/rules",
-			FilePatterns:     "default.mdc",
+			FilePatterns:     []string{"default.mdc"},
 			OverwriteHeaders: true,
 			GitWithoutPush:   false,
+			DeleteMode:       models.DeleteManaged,
+			Symlinks:         models.SymlinkFollow,
+			Normalization:    models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
 		}
 
 		if diff := cmp.Diff(expected, result); diff != "" {
@@ -81,29 +92,449 @@ func TestCfgService_CreatePullOptions(t *testing.T) {
 
 		cfg := &config.Config{
 			RulesDir:         "/default/rules",
-			FilePatterns:     "default.mdc",
+			FilePatterns:     []string{"default.mdc"},
 			OverwriteHeaders: false,
 		}
-		f.configRepositoryMock.EXPECT().
-			LoadOrDefault().
-			Return(cfg).
-			Times(1)
+		f.expectGlobalConfig(cfg)
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
 
 		ctx := createCLIContext(t, map[string]interface{}{
 			cfgService.FlagRulesDir: "/override/rules",
 		})
 
-		result := f.cfgService.CreatePullOptions(ctx)
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.NoError(t, err)
 
 		expected := &models.SyncOptions{
 			RulesDir:         "/override/rules",
-			FilePatterns:     "default.mdc",
+			FilePatterns:     []string{"default.mdc"},
+			OverwriteHeaders: false,
+			GitWithoutPush:   false,
+			DeleteMode:       models.DeleteManaged,
+			Symlinks:         models.SymlinkFollow,
+			Normalization:    models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
+		}
+
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("project config values override global config values", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{
+			RulesDir:         "/default/rules",
+			FilePatterns:     []string{"default.mdc"},
+			OverwriteHeaders: true,
+		}
+		f.expectGlobalConfig(cfg)
+		overwriteHeaders := false
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{
+			Overrides: config.Overrides{
+				RulesDir:         "/test/project/rules",
+				OverwriteHeaders: &overwriteHeaders,
+			},
+		})
+
+		ctx := createCLIContext(t, map[string]interface{}{})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.NoError(t, err)
+
+		expected := &models.SyncOptions{
+			RulesDir:         "/test/project/rules",
+			FilePatterns:     []string{"default.mdc"},
 			OverwriteHeaders: false,
 			GitWithoutPush:   false,
+			DeleteMode:       models.DeleteManaged,
+			Symlinks:         models.SymlinkFollow,
+			Normalization:    models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
+		}
+
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("flag values override project config values", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{
+			Overrides: config.Overrides{
+				RulesDir:     "/test/project/rules",
+				FilePatterns: []string{"project.mdc"},
+			},
+		})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagFilePatterns: "*.mdc",
+		})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.NoError(t, err)
+
+		expected := &models.SyncOptions{
+			RulesDir:      "/test/project/rules",
+			FilePatterns:  []string{"*.mdc"},
+			DeleteMode:    models.DeleteManaged,
+			Symlinks:      models.SymlinkFollow,
+			Normalization: models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
+		}
+
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("uses global config outside of project", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{RulesDir: "/default/rules"})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(gomock.Any()).
+			Return("", errors.New("not in project")).
+			Times(1)
+
+		ctx := createCLIContext(t, map[string]interface{}{})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.NoError(t, err)
+
+		expected := &models.SyncOptions{
+			RulesDir:      "/default/rules",
+			DeleteMode:    models.DeleteManaged,
+			Symlinks:      models.SymlinkFollow,
+			Normalization: models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
+		}
+
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("environment values override project config values", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{RulesDir: "/default/rules", FilePatterns: []string{"default.mdc"}})
+		overwriteHeaders := true
+		f.expectEnvConfig(&config.EnvConfig{
+			Overrides: config.Overrides{
+				RulesDir:         "/env/rules",
+				OverwriteHeaders: &overwriteHeaders,
+			},
+		})
+		f.expectProjectConfig(&config.ProjectConfig{
+			Overrides: config.Overrides{RulesDir: "/test/project/rules"},
+		})
+
+		ctx := createCLIContext(t, map[string]interface{}{})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.NoError(t, err)
+
+		expected := &models.SyncOptions{
+			RulesDir:         "/env/rules",
+			FilePatterns:     []string{"default.mdc"},
+			OverwriteHeaders: true,
+			DeleteMode:       models.DeleteManaged,
+			Symlinks:         models.SymlinkFollow,
+			Normalization:    models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
+		}
+
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("environment default profile overrides config default profile", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{
+			DefaultProfile: "frontend",
+			Profiles: map[string]*config.Profile{
+				"frontend": {Overrides: config.Overrides{RulesDir: "/frontend/rules"}},
+				"backend":  {Overrides: config.Overrides{RulesDir: "/backend/rules"}},
+			},
+		})
+		f.expectEnvConfig(&config.EnvConfig{DefaultProfile: "backend"})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.NoError(t, err)
+
+		expected := &models.SyncOptions{
+			RulesDir:      "/backend/rules",
+			DeleteMode:    models.DeleteManaged,
+			Symlinks:      models.SymlinkFollow,
+			Normalization: models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
+		}
+
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("ignores push scope", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{
+			FilePatterns: []string{"*.mdc"},
+			Pull:         config.Scope{Exclude: []string{"local_*"}},
+			Push:         config.Scope{Include: []string{"team/**"}},
+		})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.NoError(t, err)
+
+		expected := &models.SyncOptions{
+			FilePatterns:    []string{"*.mdc"},
+			ExcludePatterns: []string{"local_*"},
+			DeleteMode:      models.DeleteManaged,
+			Symlinks:        models.SymlinkFollow,
+			Normalization:   models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
 		}
 
 		if diff := cmp.Diff(expected, result); diff != "" {
 			t.Fatalf("mismatch (-want +got):\n%s", diff)
 		}
 	})
+
+	t.Run("no-delete flag overrides delete mode of project config", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{Delete: "all"})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{
+			Overrides: config.Overrides{Delete: "managed"},
+		})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagNoDelete: true,
+		})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(models.DeleteNone, result.DeleteMode); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("uses delete mode of project config", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{Delete: "none"})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{
+			Overrides: config.Overrides{Delete: "managed"},
+		})
+
+		ctx := createCLIContext(t, map[string]interface{}{})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(models.DeleteManaged, result.DeleteMode); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("returns error for invalid delete flag", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagDelete: "some",
+		})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid delete mode")
+		require.Nil(t, result)
+	})
+
+	t.Run("uses file mode of project config", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{FileMode: "0600"})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{
+			Overrides: config.Overrides{FileMode: "0755"},
+		})
+
+		ctx := createCLIContext(t, map[string]interface{}{})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(os.FileMode(0755), result.FileMode); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("returns error for invalid file mode flag", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagFileMode: "rw-r--r--",
+		})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid file mode")
+		require.Nil(t, result)
+	})
+
+	t.Run("returns error for invalid symlinks flag", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagSymlinks: "copy",
+		})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid symlink mode")
+		require.Nil(t, result)
+	})
+
+	t.Run("returns error for invalid jobs flag", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagJobs: "0",
+		})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid jobs")
+		require.Nil(t, result)
+	})
+
+	t.Run("line endings flag overrides project config", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{Overrides: config.Overrides{LineEndings: "preserve", Compare: "exact"}})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagLineEndings: "crlf",
+		})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.NoError(t, err)
+		if diff := cmp.Diff(models.Normalization{LineEndings: models.LineEndingsCRLF, AllTextFiles: true, Compare: models.CompareExact}, result.Normalization); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("returns error for invalid compare flag", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagCompare: "loose",
+		})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid compare mode")
+		require.Nil(t, result)
+	})
+
+	t.Run("link flag enables link mode", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagLink: true,
+		})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.NoError(t, err)
+		require.True(t, result.Link)
+	})
+
+	t.Run("returns error for invalid environment value", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		envErr := errors.New("invalid boolean value")
+		f.expectGlobalConfig(&config.Config{})
+		f.configRepositoryMock.EXPECT().
+			LoadEnv().
+			Return(nil, envErr).
+			Times(1)
+
+		ctx := createCLIContext(t, map[string]interface{}{})
+
+		result, err := f.cfgService.CreatePullOptions(ctx)
+		require.ErrorIs(t, err, envErr)
+		require.Nil(t, result)
+	})
 }
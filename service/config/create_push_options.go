@@ -6,12 +6,26 @@ import (
 )
 
 // CreatePushOptions creates SyncOptions for push command
-func (s *CfgService) CreatePushOptions(ctx *cli.Context) *models.SyncOptions {
-	cfg := s.configRepository.LoadOrDefault()
-	return &models.SyncOptions{
-		RulesDir:         s.getStringValue(ctx, FlagRulesDir, cfg),
-		GitWithoutPush:   s.getBoolValue(ctx, FlagGitWithoutPush, cfg),
-		OverwriteHeaders: s.getBoolValue(ctx, FlagOverwriteHeaders, cfg),
-		FilePatterns:     s.getStringValue(ctx, FlagFilePatterns, cfg),
+func (s *CfgService) CreatePushOptions(ctx *cli.Context) (*models.SyncOptions, error) {
+	names := []string{FlagRulesDir, FlagGitWithoutPush, FlagOverwriteHeaders, OptionPushInclude, OptionPushExclude}
+	resolved, err := s.ResolveOptions(ctx, append(names, syncOptionNames...)...)
+	if err != nil {
+		return nil, err
 	}
+
+	if ctx.Bool(FlagExplain) {
+		s.printExplanation(resolved)
+	}
+
+	options := &models.SyncOptions{
+		RulesDir:         resolved.Get(FlagRulesDir).String(),
+		GitWithoutPush:   resolved.Get(FlagGitWithoutPush).Bool(),
+		OverwriteHeaders: resolved.Get(FlagOverwriteHeaders).Bool(),
+		FilePatterns:     resolved.Get(OptionPushInclude).Strings(),
+		ExcludePatterns:  resolved.Get(OptionPushExclude).Strings(),
+	}
+	if err := parseSyncOptions(resolved, options); err != nil {
+		return nil, err
+	}
+	return options, nil
 }
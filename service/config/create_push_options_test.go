@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
 	"github.com/yanodintsovmercuryo/cursync/models"
 	"github.com/yanodintsovmercuryo/cursync/pkg/config"
 	cfgService "github.com/yanodintsovmercuryo/cursync/service/config"
@@ -18,10 +19,9 @@ func TestCfgService_CreatePushOptions(t *testing.T) {
 		defer finish()
 
 		cfg := &config.Config{}
-		f.configRepositoryMock.EXPECT().
-			LoadOrDefault().
-			Return(cfg).
-			Times(1)
+		f.expectGlobalConfig(cfg)
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
 
 		ctx := createCLIContext(t, map[string]interface{}{
 			cfgService.FlagRulesDir:         "/custom/rules",
@@ -30,13 +30,17 @@ func TestCfgService_CreatePushOptions(t *testing.T) {
 			cfgService.FlagGitWithoutPush:   true,
 		})
 
-		result := f.cfgService.CreatePushOptions(ctx)
+		result, err := f.cfgService.CreatePushOptions(ctx)
+		require.NoError(t, err)
 
 		expected := &models.SyncOptions{
 			RulesDir:         "/custom/rules",
-			FilePatterns:     "*.mdc",
+			FilePatterns:     []string{"*.mdc"},
 			OverwriteHeaders: true,
 			GitWithoutPush:   true,
+			DeleteMode:       models.DeleteManaged,
+			Symlinks:         models.SymlinkFollow,
+			Normalization:    models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
 		}
 
 		if diff := cmp.Diff(expected, result); diff != "" {
@@ -51,28 +55,255 @@ func TestCfgService_CreatePushOptions(t *testing.T) {
 
 		cfg := &config.Config{
 			RulesDir:         "/default/rules",
-			FilePatterns:     "default.mdc",
+			FilePatterns:     []string{"default.mdc"},
 			OverwriteHeaders: true,
 			GitWithoutPush:   true,
 		}
-		f.configRepositoryMock.EXPECT().
-			LoadOrDefault().
-			Return(cfg).
-			Times(1)
+		f.expectGlobalConfig(cfg)
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
 
 		ctx := createCLIContext(t, map[string]interface{}{})
 
-		result := f.cfgService.CreatePushOptions(ctx)
+		result, err := f.cfgService.CreatePushOptions(ctx)
+		require.NoError(t, err)
 
 		expected := &models.SyncOptions{
 			RulesDir:         "/default/rules",
-			FilePatterns:     "default.mdc",
+			FilePatterns:     []string{"default.mdc"},
 			OverwriteHeaders: true,
 			GitWithoutPush:   true,
+			DeleteMode:       models.DeleteManaged,
+			Symlinks:         models.SymlinkFollow,
+			Normalization:    models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
 		}
 
 		if diff := cmp.Diff(expected, result); diff != "" {
 			t.Fatalf("mismatch (-want +got):\n%s", diff)
 		}
 	})
+	t.Run("uses profile selected by flag", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		gitWithoutPush := false
+		cfg := &config.Config{
+			RulesDir:       "/default/rules",
+			FilePatterns:   []string{"default.mdc"},
+			GitWithoutPush: true,
+			DefaultProfile: "frontend",
+			Profiles: map[string]*config.Profile{
+				"frontend": {Overrides: config.Overrides{RulesDir: "/frontend/rules"}},
+				"backend":  {Overrides: config.Overrides{RulesDir: "/backend/rules", GitWithoutPush: &gitWithoutPush}},
+			},
+		}
+		f.expectGlobalConfig(cfg)
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagProfile: "backend",
+		})
+
+		result, err := f.cfgService.CreatePushOptions(ctx)
+		require.NoError(t, err)
+
+		expected := &models.SyncOptions{
+			RulesDir:       "/backend/rules",
+			FilePatterns:   []string{"default.mdc"},
+			GitWithoutPush: false,
+			DeleteMode:     models.DeleteManaged,
+			Symlinks:       models.SymlinkFollow,
+			Normalization:  models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
+		}
+
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("uses default profile below project config", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{
+			DefaultProfile: "frontend",
+			Profiles: map[string]*config.Profile{
+				"frontend": {Overrides: config.Overrides{RulesDir: "/frontend/rules", FilePatterns: []string{"frontend/*.mdc"}}},
+			},
+		}
+		f.expectGlobalConfig(cfg)
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{
+			Overrides: config.Overrides{FilePatterns: []string{"project.mdc"}},
+		})
+
+		ctx := createCLIContext(t, map[string]interface{}{})
+
+		result, err := f.cfgService.CreatePushOptions(ctx)
+		require.NoError(t, err)
+
+		expected := &models.SyncOptions{
+			RulesDir:      "/frontend/rules",
+			FilePatterns:  []string{"project.mdc"},
+			DeleteMode:    models.DeleteManaged,
+			Symlinks:      models.SymlinkFollow,
+			Normalization: models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
+		}
+
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("push scope replaces shared file patterns", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{
+			FilePatterns: []string{"*.mdc"},
+			Push:         config.Scope{Include: []string{"team/**"}},
+		})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagExclude: "team/draft_*, team/{tmp,old}/**",
+		})
+
+		result, err := f.cfgService.CreatePushOptions(ctx)
+		require.NoError(t, err)
+
+		expected := &models.SyncOptions{
+			FilePatterns:    []string{"team/**"},
+			ExcludePatterns: []string{"team/draft_*", "team/{tmp,old}/**"},
+			DeleteMode:      models.DeleteManaged,
+			Symlinks:        models.SymlinkFollow,
+			Normalization:   models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
+		}
+
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("returns error for unknown profile", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagProfile: "missing",
+		})
+
+		result, err := f.cfgService.CreatePushOptions(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unknown profile")
+		require.Nil(t, result)
+	})
+
+	t.Run("delete flag overrides environment delete mode", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{})
+		f.expectEnvConfig(&config.EnvConfig{
+			Overrides: config.Overrides{Delete: "none"},
+		})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagDelete: "managed",
+		})
+
+		result, err := f.cfgService.CreatePushOptions(ctx)
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(models.DeleteManaged, result.DeleteMode); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("file mode flag overrides global file mode", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{FileMode: "0600"})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagFileMode: "preserve",
+		})
+
+		result, err := f.cfgService.CreatePushOptions(ctx)
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(models.FileModePreserve, result.FileMode); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("symlinks flag overrides project symlink mode", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{
+			Overrides: config.Overrides{Symlinks: "skip"},
+		})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagSymlinks: "preserve",
+		})
+
+		result, err := f.cfgService.CreatePushOptions(ctx)
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(models.SymlinkPreserve, result.Symlinks); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("uses jobs of global config", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{Jobs: "8"})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{})
+
+		result, err := f.cfgService.CreatePushOptions(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 8, result.Jobs)
+	})
+
+	t.Run("uses line endings of env config", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{LineEndings: "lf"})
+		f.expectEnvConfig(&config.EnvConfig{Overrides: config.Overrides{LineEndings: "native"}})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{})
+
+		result, err := f.cfgService.CreatePushOptions(ctx)
+		require.NoError(t, err)
+		require.Equal(t, models.LineEndingsNative, result.Normalization.LineEndings)
+	})
 }
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+	"github.com/yanodintsovmercuryo/cursync/models"
+)
+
+// CreateWatchOptions creates WatchOptions for watch command
+func (s *CfgService) CreateWatchOptions(ctx *cli.Context) (*models.WatchOptions, error) {
+	direction, err := models.ParseWatchDirection(ctx.String(FlagDirection))
+	if err != nil {
+		return nil, err
+	}
+
+	debounce := ctx.Duration(FlagDebounce)
+	if debounce < 0 {
+		return nil, fmt.Errorf("invalid debounce %s, must not be negative", debounce)
+	}
+
+	pullOptions, err := s.CreatePullOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pushOptions, err := s.CreatePushOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WatchOptions{
+		Pull:      pullOptions,
+		Push:      pushOptions,
+		Direction: direction,
+		Debounce:  debounce,
+	}, nil
+}
@@ -0,0 +1,96 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/config"
+	cfgService "github.com/yanodintsovmercuryo/cursync/service/config"
+)
+
+func TestCfgService_CreateWatchOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("combines pull and push options", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{
+			RulesDir: "/default/rules",
+			Pull:     config.Scope{Include: []string{"pull.mdc"}},
+			Push:     config.Scope{Include: []string{"push.mdc"}},
+		}
+		// Pull and push options are resolved separately
+		for range 2 {
+			f.expectGlobalConfig(cfg)
+			f.expectEnvConfig(&config.EnvConfig{})
+			f.expectProjectConfig(&config.ProjectConfig{})
+		}
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagDirection:      "push",
+			cfgService.FlagDebounce:       "2s",
+			cfgService.FlagGitWithoutPush: true,
+		})
+
+		result, err := f.cfgService.CreateWatchOptions(ctx)
+		require.NoError(t, err)
+
+		expected := &models.WatchOptions{
+			Pull: &models.SyncOptions{
+				RulesDir:      "/default/rules",
+				FilePatterns:  []string{"pull.mdc"},
+				DeleteMode:    models.DeleteManaged,
+				Symlinks:      models.SymlinkFollow,
+				Normalization: models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
+			},
+			Push: &models.SyncOptions{
+				RulesDir:       "/default/rules",
+				GitWithoutPush: true,
+				FilePatterns:   []string{"push.mdc"},
+				DeleteMode:     models.DeleteManaged,
+				Symlinks:       models.SymlinkFollow,
+				Normalization:  models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareNormalized},
+			},
+			Direction: models.WatchPush,
+			Debounce:  2 * time.Second,
+		}
+
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("rejects invalid direction", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagDirection: "sideways",
+		})
+
+		_, err := f.cfgService.CreateWatchOptions(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid watch direction")
+	})
+
+	t.Run("rejects negative debounce", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagDebounce: "-1s",
+		})
+
+		_, err := f.cfgService.CreateWatchOptions(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid debounce")
+	})
+}
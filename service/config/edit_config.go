@@ -0,0 +1,74 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+// EditConfig opens a copy of global config in the editor and saves it only if it is valid,
+// invalid copy is kept so that changes are not lost
+func (s *CfgService) EditConfig(ctx *cli.Context) error {
+	configPath, err := s.configRepository.GetConfigPath()
+	if err != nil {
+		return err
+	}
+
+	original, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	editPath, err := writeTempConfig(original)
+	if err != nil {
+		return err
+	}
+
+	if err := s.editor.Edit(editPath); err != nil {
+		os.Remove(editPath)
+		return err
+	}
+
+	edited, err := os.ReadFile(editPath)
+	if err != nil {
+		return fmt.Errorf("failed to read edited config: %w", err)
+	}
+
+	cfg, err := s.configRepository.LoadFile(editPath)
+	if err == nil {
+		err = s.configRepository.Validate(cfg)
+	}
+	if err != nil {
+		return fmt.Errorf("%w\nconfig was not saved, edited copy is kept in %s", err, editPath)
+	}
+	defer os.Remove(editPath)
+
+	if bytes.Equal(original, edited) {
+		fmt.Println("No changes.")
+		return nil
+	}
+
+	if err := s.configRepository.SaveRaw(edited); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Saved %s\n", configPath)
+	return nil
+}
+
+// writeTempConfig writes config content to a temporary file and returns its path
+func writeTempConfig(data []byte) (string, error) {
+	file, err := os.CreateTemp("", "cursync-*.toml")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary config: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write temporary config: %w", err)
+	}
+	return file.Name(), nil
+}
@@ -0,0 +1,109 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/config"
+)
+
+func TestCfgService_EditConfig(t *testing.T) {
+	t.Parallel()
+
+	const (
+		originalContent = "rules_dir = '/test/rules'\n"
+		editedContent   = "rules_dir = '/test/other-rules'\n"
+	)
+
+	setUpConfigFile := func(t *testing.T, f *fixture) {
+		t.Helper()
+		configPath := filepath.Join(t.TempDir(), "cursync.toml")
+		require.NoError(t, os.WriteFile(configPath, []byte(originalContent), 0600))
+
+		f.configRepositoryMock.EXPECT().
+			GetConfigPath().
+			Return(configPath, nil).
+			Times(1)
+	}
+
+	expectEdit := func(f *fixture, content string) {
+		f.editorMock.EXPECT().
+			Edit(gomock.Any()).
+			DoAndReturn(func(path string) error {
+				return os.WriteFile(path, []byte(content), 0600)
+			}).
+			Times(1)
+	}
+
+	t.Run("saves valid changes", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		setUpConfigFile(t, f)
+		expectEdit(f, editedContent)
+
+		cfg := &config.Config{RulesDir: "/test/other-rules"}
+		f.configRepositoryMock.EXPECT().
+			LoadFile(gomock.Any()).
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Validate(cfg).
+			Return(nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			SaveRaw([]byte(editedContent)).
+			Return(nil).
+			Times(1)
+
+		err := f.cfgService.EditConfig(createCLIContext(t, map[string]interface{}{}))
+		require.NoError(t, err)
+	})
+
+	t.Run("does not save invalid changes", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		setUpConfigFile(t, f)
+		expectEdit(f, "rules_dir = ")
+
+		parseErr := errors.New("failed to parse config file")
+		f.configRepositoryMock.EXPECT().
+			LoadFile(gomock.Any()).
+			Return(nil, parseErr).
+			Times(1)
+
+		err := f.cfgService.EditConfig(createCLIContext(t, map[string]interface{}{}))
+		require.ErrorIs(t, err, parseErr)
+		require.ErrorContains(t, err, "edited copy is kept")
+	})
+
+	t.Run("does not save unchanged config", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		setUpConfigFile(t, f)
+		expectEdit(f, originalContent)
+
+		cfg := &config.Config{RulesDir: "/test/rules"}
+		f.configRepositoryMock.EXPECT().
+			LoadFile(gomock.Any()).
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Validate(cfg).
+			Return(nil).
+			Times(1)
+
+		err := f.cfgService.EditConfig(createCLIContext(t, map[string]interface{}{}))
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// flagValues provides access to command line flags
+type flagValues interface {
+	IsSet(name string) bool
+	String(name string) string
+	Bool(name string) bool
+}
+
+// ResolvedOption holds effective value of an option and where it came from
+type ResolvedOption struct {
+	Name   string
+	Value  interface{}
+	Source string
+	Origin string
+}
+
+// ResolvedOptions holds effective values of options in order of resolution
+type ResolvedOptions []ResolvedOption
+
+// String returns effective value of string option or empty string
+func (o ResolvedOption) String() string {
+	val, _ := o.Value.(string)
+	return val
+}
+
+// Strings returns effective value of list option or nil
+func (o ResolvedOption) Strings() []string {
+	val, _ := o.Value.([]string)
+	return val
+}
+
+// Bool returns effective value of bool option or false
+func (o ResolvedOption) Bool() bool {
+	val, _ := o.Value.(bool)
+	return val
+}
+
+// Get returns resolved option by name
+func (o ResolvedOptions) Get(name string) ResolvedOption {
+	for _, option := range o {
+		if option.Name == name {
+			return option
+		}
+	}
+	return ResolvedOption{Name: name, Source: SourceDefault}
+}
+
+// ResolveOptions resolves effective values of named options, selected profile comes first
+func (s *CfgService) ResolveOptions(ctx *cli.Context, names ...string) (ResolvedOptions, error) {
+	return s.resolveOptions(ctx, names...)
+}
+
+// resolveOptions resolves effective values of named options using given flags
+func (s *CfgService) resolveOptions(flags flagValues, names ...string) (ResolvedOptions, error) {
+	sources, err := s.loadOptionSources(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := ResolvedOptions{sources.profileSource}
+	for _, name := range names {
+		var option ResolvedOption
+		switch name {
+		case OptionPullInclude, OptionPullExclude, OptionPushInclude, OptionPushExclude:
+			option = s.resolvePatterns(flags, name, sources)
+		case FlagDelete:
+			option, err = s.resolveDelete(flags, sources)
+		default:
+			option, err = s.resolveKey(flags, name, sources)
+		}
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, option)
+	}
+	return resolved, nil
+}
+
+// ExplainConfig displays effective value of each option and where it came from,
+// only --profile flag of cfg command is taken into account
+func (s *CfgService) ExplainConfig(ctx *cli.Context) error {
+	names := []string{FlagRulesDir, OptionPullInclude, OptionPullExclude, OptionPushInclude, OptionPushExclude, FlagOverwriteHeaders, FlagGitWithoutPush}
+	resolved, err := s.resolveOptions(profileOnlyFlags{ctx: ctx}, append(names, syncOptionNames...)...)
+	if err != nil {
+		return err
+	}
+
+	s.printExplanation(resolved)
+	return nil
+}
+
+// printExplanation displays resolved options with their sources
+func (s *CfgService) printExplanation(resolved ResolvedOptions) {
+	for _, option := range resolved {
+		value := fmt.Sprintf("%v", option.Value)
+		if list, ok := option.Value.([]string); ok {
+			value = strings.Join(list, ", ")
+		}
+		if value == "" {
+			value = "(not set)"
+		}
+
+		source := option.Source
+		if option.Origin != "" {
+			source = fmt.Sprintf("%s %s", option.Source, option.Origin)
+		}
+
+		fmt.Printf("%s: %s (%s)\n", option.Name, value, source)
+	}
+}
+
+// profileOnlyFlags exposes only --profile flag, other flags of cfg command set values instead of overriding them
+type profileOnlyFlags struct {
+	ctx *cli.Context
+}
+
+func (f profileOnlyFlags) IsSet(name string) bool {
+	return name == FlagProfile && f.ctx.IsSet(name)
+}
+
+func (f profileOnlyFlags) String(name string) string {
+	return f.ctx.String(name)
+}
+
+func (f profileOnlyFlags) Bool(name string) bool {
+	return f.ctx.Bool(name)
+}
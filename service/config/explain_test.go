@@ -0,0 +1,157 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/config"
+	cfgService "github.com/yanodintsovmercuryo/cursync/service/config"
+)
+
+func TestCfgService_ResolveOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reports source of each option", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		overwriteHeaders := true
+		f.expectGlobalConfig(&config.Config{
+			FilePatterns:   []string{"global.mdc"},
+			Push:           config.Scope{Exclude: []string{"team/draft_*"}},
+			GitWithoutPush: true,
+			DefaultProfile: "backend",
+			Profiles: map[string]*config.Profile{
+				"backend": {Overrides: config.Overrides{Push: config.Scope{Include: []string{"team/**"}}}},
+			},
+		})
+		f.expectEnvConfig(&config.EnvConfig{
+			Overrides: config.Overrides{OverwriteHeaders: &overwriteHeaders},
+		})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagRulesDir: "/custom/rules",
+		})
+
+		result, err := f.cfgService.ResolveOptions(ctx,
+			cfgService.FlagRulesDir,
+			cfgService.OptionPullInclude,
+			cfgService.OptionPushInclude,
+			cfgService.OptionPushExclude,
+			cfgService.FlagOverwriteHeaders,
+			cfgService.FlagGitWithoutPush,
+		)
+		require.NoError(t, err)
+
+		expected := cfgService.ResolvedOptions{
+			{Name: cfgService.FlagProfile, Value: "backend", Source: cfgService.SourceGlobal, Origin: testConfigPath},
+			{Name: cfgService.FlagRulesDir, Value: "/custom/rules", Source: cfgService.SourceFlag, Origin: "--rules-dir"},
+			{Name: cfgService.OptionPullInclude, Value: []string{"global.mdc"}, Source: cfgService.SourceGlobal, Origin: testConfigPath},
+			{Name: cfgService.OptionPushInclude, Value: []string{"team/**"}, Source: cfgService.SourceProfile, Origin: "backend"},
+			{Name: cfgService.OptionPushExclude, Value: []string{"team/draft_*"}, Source: cfgService.SourceGlobal, Origin: testConfigPath},
+			{Name: cfgService.FlagOverwriteHeaders, Value: true, Source: cfgService.SourceEnv, Origin: config.EnvOverwriteHeaders},
+			{Name: cfgService.FlagGitWithoutPush, Value: true, Source: cfgService.SourceGlobal, Origin: testConfigPath},
+		}
+
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("reports project file and built-in defaults", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{
+			Overrides: config.Overrides{RulesDir: "/test/project/rules"},
+		})
+
+		ctx := createCLIContext(t, map[string]interface{}{})
+
+		result, err := f.cfgService.ResolveOptions(ctx, cfgService.FlagRulesDir, cfgService.FlagOverwriteHeaders)
+		require.NoError(t, err)
+
+		expected := cfgService.ResolvedOptions{
+			{Name: cfgService.FlagProfile, Value: "", Source: cfgService.SourceDefault},
+			{Name: cfgService.FlagRulesDir, Value: "/test/project/rules", Source: cfgService.SourceProject, Origin: testProjectConfigPath},
+			{Name: cfgService.FlagOverwriteHeaders, Value: false, Source: cfgService.SourceDefault},
+		}
+
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("returns error when global config cannot be parsed", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		loadErr := errors.New("failed to parse config file")
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(nil, loadErr).
+			Times(1)
+
+		ctx := createCLIContext(t, map[string]interface{}{})
+
+		result, err := f.cfgService.ResolveOptions(ctx, cfgService.FlagRulesDir)
+		require.ErrorIs(t, err, loadErr)
+		require.Nil(t, result)
+	})
+
+	t.Run("returns error when project config cannot be parsed", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		loadErr := errors.New("failed to parse project config file")
+		f.expectGlobalConfig(&config.Config{})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(gomock.Any()).
+			Return(testProjectRoot, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			GetProjectConfigPath(testProjectRoot).
+			Return(testProjectConfigPath).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			LoadProject(testProjectRoot).
+			Return(nil, loadErr).
+			Times(1)
+
+		ctx := createCLIContext(t, map[string]interface{}{})
+
+		result, err := f.cfgService.ResolveOptions(ctx, cfgService.FlagRulesDir)
+		require.ErrorIs(t, err, loadErr)
+		require.Nil(t, result)
+	})
+
+	t.Run("explain does not treat cfg flags as overrides", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectGlobalConfig(&config.Config{RulesDir: "/default/rules"})
+		f.expectEnvConfig(&config.EnvConfig{})
+		f.expectProjectConfig(&config.ProjectConfig{})
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagRulesDir: "/custom/rules",
+			cfgService.FlagExplain:  true,
+		})
+
+		err := f.cfgService.ExplainConfig(ctx)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+	"github.com/yanodintsovmercuryo/cursync/pkg/config"
+)
+
+// GetConfig displays value of a configuration key globally or within the profile selected by flag,
+// nothing is displayed for a key not set within the profile. Without flag the value set within default_profile
+// takes precedence over the global one
+func (s *CfgService) GetConfig(ctx *cli.Context) error {
+	args, err := commandArgs(ctx, 1, "get <key>")
+	if err != nil {
+		return err
+	}
+
+	key, err := config.LookupKey(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := s.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	profile := targetProfile(ctx, cfg, key)
+	if profile != "" {
+		all, err := s.configRepository.GetAllProfile(cfg, profile)
+		if err != nil {
+			return err
+		}
+		if value, ok := all[key.Name]; ok {
+			fmt.Println(value)
+			return nil
+		}
+		if ctx.String(FlagProfile) != "" {
+			return nil
+		}
+	}
+
+	value, err := s.configRepository.Get(cfg, key.Name)
+	if err != nil {
+		return err
+	}
+	fmt.Println(value)
+	return nil
+}
@@ -0,0 +1,123 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/config"
+	cfgService "github.com/yanodintsovmercuryo/cursync/service/config"
+)
+
+func TestCfgService_GetConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("gets global value", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{RulesDir: "/test/rules"}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Get(cfg, "rules_dir").
+			Return("/test/rules", nil).
+			Times(1)
+
+		err := f.cfgService.GetConfig(createCLIContext(t, map[string]interface{}{}, "rules-dir"))
+		require.NoError(t, err)
+	})
+
+	t.Run("gets value within profile", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			GetAllProfile(cfg, "backend").
+			Return(map[string]interface{}{"rules_dir": "/backend/rules"}, nil).
+			Times(1)
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagProfile: "backend",
+		}, "rules_dir")
+
+		err := f.cfgService.GetConfig(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("gets value within default profile", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{DefaultProfile: "default"}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			GetAllProfile(cfg, "default").
+			Return(map[string]interface{}{"rules_dir": "/default/rules"}, nil).
+			Times(1)
+
+		err := f.cfgService.GetConfig(createCLIContext(t, map[string]interface{}{}, "rules_dir"))
+		require.NoError(t, err)
+	})
+
+	t.Run("gets global value not set within default profile", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{DefaultProfile: "default", RulesDir: "/test/rules"}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			GetAllProfile(cfg, "default").
+			Return(map[string]interface{}{}, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Get(cfg, "rules_dir").
+			Return("/test/rules", nil).
+			Times(1)
+
+		err := f.cfgService.GetConfig(createCLIContext(t, map[string]interface{}{}, "rules_dir"))
+		require.NoError(t, err)
+	})
+
+	t.Run("returns error when config cannot be loaded", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		loadErr := errors.New("failed to parse config file")
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(nil, loadErr).
+			Times(1)
+
+		err := f.cfgService.GetConfig(createCLIContext(t, map[string]interface{}{}, "rules_dir"))
+		require.ErrorIs(t, err, loadErr)
+	})
+
+	t.Run("rejects unknown key", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		err := f.cfgService.GetConfig(createCLIContext(t, map[string]interface{}{}, "unknown"))
+		require.ErrorContains(t, err, "unknown config key")
+	})
+}
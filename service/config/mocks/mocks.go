@@ -55,3 +55,97 @@ func (mr *MockoutputServiceMockRecorder) PrintErrorf(format any, args ...any) *g
 	varargs := append([]any{format}, args...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrintErrorf", reflect.TypeOf((*MockoutputService)(nil).PrintErrorf), varargs...)
 }
+
+// PrintWarningf mocks base method.
+func (m *MockoutputService) PrintWarningf(format string, args ...any) {
+	m.ctrl.T.Helper()
+	varargs := []any{format}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "PrintWarningf", varargs...)
+}
+
+// PrintWarningf indicates an expected call of PrintWarningf.
+func (mr *MockoutputServiceMockRecorder) PrintWarningf(format any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{format}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrintWarningf", reflect.TypeOf((*MockoutputService)(nil).PrintWarningf), varargs...)
+}
+
+// MockgitOps is a mock of gitOps interface.
+type MockgitOps struct {
+	ctrl     *gomock.Controller
+	recorder *MockgitOpsMockRecorder
+	isgomock struct{}
+}
+
+// MockgitOpsMockRecorder is the mock recorder for MockgitOps.
+type MockgitOpsMockRecorder struct {
+	mock *MockgitOps
+}
+
+// NewMockgitOps creates a new mock instance.
+func NewMockgitOps(ctrl *gomock.Controller) *MockgitOps {
+	mock := &MockgitOps{ctrl: ctrl}
+	mock.recorder = &MockgitOpsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockgitOps) EXPECT() *MockgitOpsMockRecorder {
+	return m.recorder
+}
+
+// GetGitRootDir mocks base method.
+func (m *MockgitOps) GetGitRootDir(startDir string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGitRootDir", startDir)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGitRootDir indicates an expected call of GetGitRootDir.
+func (mr *MockgitOpsMockRecorder) GetGitRootDir(startDir any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGitRootDir", reflect.TypeOf((*MockgitOps)(nil).GetGitRootDir), startDir)
+}
+
+// Mockeditor is a mock of editor interface.
+type Mockeditor struct {
+	ctrl     *gomock.Controller
+	recorder *MockeditorMockRecorder
+	isgomock struct{}
+}
+
+// MockeditorMockRecorder is the mock recorder for Mockeditor.
+type MockeditorMockRecorder struct {
+	mock *Mockeditor
+}
+
+// NewMockeditor creates a new mock instance.
+func NewMockeditor(ctrl *gomock.Controller) *Mockeditor {
+	mock := &Mockeditor{ctrl: ctrl}
+	mock.recorder = &MockeditorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockeditor) EXPECT() *MockeditorMockRecorder {
+	return m.recorder
+}
+
+// Edit mocks base method.
+func (m *Mockeditor) Edit(path string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Edit", path)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Edit indicates an expected call of Edit.
+func (mr *MockeditorMockRecorder) Edit(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Edit", reflect.TypeOf((*Mockeditor)(nil).Edit), path)
+}
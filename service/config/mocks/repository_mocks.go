@@ -69,6 +69,50 @@ func (mr *MockConfigRepositoryInterfaceMockRecorder) GetAll(cfg any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).GetAll), cfg)
 }
 
+// GetAllProfile mocks base method.
+func (m *MockConfigRepositoryInterface) GetAllProfile(cfg *config.Config, profile string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllProfile", cfg, profile)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllProfile indicates an expected call of GetAllProfile.
+func (mr *MockConfigRepositoryInterfaceMockRecorder) GetAllProfile(cfg, profile any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllProfile", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).GetAllProfile), cfg, profile)
+}
+
+// GetConfigPath mocks base method.
+func (m *MockConfigRepositoryInterface) GetConfigPath() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigPath")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfigPath indicates an expected call of GetConfigPath.
+func (mr *MockConfigRepositoryInterfaceMockRecorder) GetConfigPath() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigPath", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).GetConfigPath))
+}
+
+// GetProjectConfigPath mocks base method.
+func (m *MockConfigRepositoryInterface) GetProjectConfigPath(projectRoot string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProjectConfigPath", projectRoot)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetProjectConfigPath indicates an expected call of GetProjectConfigPath.
+func (mr *MockConfigRepositoryInterfaceMockRecorder) GetProjectConfigPath(projectRoot any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProjectConfigPath", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).GetProjectConfigPath), projectRoot)
+}
+
 // Load mocks base method.
 func (m *MockConfigRepositoryInterface) Load() (*config.Config, error) {
 	m.ctrl.T.Helper()
@@ -84,18 +128,49 @@ func (mr *MockConfigRepositoryInterfaceMockRecorder) Load() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).Load))
 }
 
-// LoadOrDefault mocks base method.
-func (m *MockConfigRepositoryInterface) LoadOrDefault() *config.Config {
+// LoadEnv mocks base method.
+func (m *MockConfigRepositoryInterface) LoadEnv() (*config.EnvConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadEnv")
+	ret0, _ := ret[0].(*config.EnvConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadEnv indicates an expected call of LoadEnv.
+func (mr *MockConfigRepositoryInterfaceMockRecorder) LoadEnv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadEnv", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).LoadEnv))
+}
+
+// LoadFile mocks base method.
+func (m *MockConfigRepositoryInterface) LoadFile(configPath string) (*config.Config, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoadOrDefault")
+	ret := m.ctrl.Call(m, "LoadFile", configPath)
 	ret0, _ := ret[0].(*config.Config)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// LoadOrDefault indicates an expected call of LoadOrDefault.
-func (mr *MockConfigRepositoryInterfaceMockRecorder) LoadOrDefault() *gomock.Call {
+// LoadFile indicates an expected call of LoadFile.
+func (mr *MockConfigRepositoryInterfaceMockRecorder) LoadFile(configPath any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOrDefault", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).LoadOrDefault))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadFile", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).LoadFile), configPath)
+}
+
+// LoadProject mocks base method.
+func (m *MockConfigRepositoryInterface) LoadProject(projectRoot string) (*config.ProjectConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadProject", projectRoot)
+	ret0, _ := ret[0].(*config.ProjectConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadProject indicates an expected call of LoadProject.
+func (mr *MockConfigRepositoryInterfaceMockRecorder) LoadProject(projectRoot any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadProject", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).LoadProject), projectRoot)
 }
 
 // Save mocks base method.
@@ -112,6 +187,20 @@ func (mr *MockConfigRepositoryInterfaceMockRecorder) Save(cfg any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).Save), cfg)
 }
 
+// SaveRaw mocks base method.
+func (m *MockConfigRepositoryInterface) SaveRaw(data []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRaw", data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRaw indicates an expected call of SaveRaw.
+func (mr *MockConfigRepositoryInterfaceMockRecorder) SaveRaw(data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRaw", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).SaveRaw), data)
+}
+
 // Set mocks base method.
 func (m *MockConfigRepositoryInterface) Set(cfg *config.Config, key string, value any) error {
 	m.ctrl.T.Helper()
@@ -125,3 +214,73 @@ func (mr *MockConfigRepositoryInterfaceMockRecorder) Set(cfg, key, value any) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).Set), cfg, key, value)
 }
+
+// SetProfile mocks base method.
+func (m *MockConfigRepositoryInterface) SetProfile(cfg *config.Config, profile, key string, value any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProfile", cfg, profile, key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetProfile indicates an expected call of SetProfile.
+func (mr *MockConfigRepositoryInterfaceMockRecorder) SetProfile(cfg, profile, key, value any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProfile", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).SetProfile), cfg, profile, key, value)
+}
+
+// Unset mocks base method.
+func (m *MockConfigRepositoryInterface) Unset(cfg *config.Config, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unset", cfg, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unset indicates an expected call of Unset.
+func (mr *MockConfigRepositoryInterfaceMockRecorder) Unset(cfg, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unset", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).Unset), cfg, key)
+}
+
+// UnsetProfile mocks base method.
+func (m *MockConfigRepositoryInterface) UnsetProfile(cfg *config.Config, profile, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsetProfile", cfg, profile, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnsetProfile indicates an expected call of UnsetProfile.
+func (mr *MockConfigRepositoryInterfaceMockRecorder) UnsetProfile(cfg, profile, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsetProfile", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).UnsetProfile), cfg, profile, key)
+}
+
+// Validate mocks base method.
+func (m *MockConfigRepositoryInterface) Validate(cfg *config.Config) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", cfg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockConfigRepositoryInterfaceMockRecorder) Validate(cfg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).Validate), cfg)
+}
+
+// ValidateProject mocks base method.
+func (m *MockConfigRepositoryInterface) ValidateProject(projectCfg *config.ProjectConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateProject", projectCfg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateProject indicates an expected call of ValidateProject.
+func (mr *MockConfigRepositoryInterfaceMockRecorder) ValidateProject(projectCfg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateProject", reflect.TypeOf((*MockConfigRepositoryInterface)(nil).ValidateProject), projectCfg)
+}
@@ -17,12 +17,3 @@ func NewConfigRepository() *ConfigRepository {
 func (m *ConfigRepository) Load() (*config.Config, error) {
 	return config.Load()
 }
-
-// LoadOrDefault loads configuration or returns empty config on error
-func (m *ConfigRepository) LoadOrDefault() *config.Config {
-	cfg, err := config.Load()
-	if err != nil {
-		return &config.Config{}
-	}
-	return cfg
-}
@@ -4,7 +4,11 @@ package config
 //go:generate mockgen -destination=mocks/repository_mocks.go -package=mocks github.com/yanodintsovmercuryo/cursync/pkg/config ConfigRepositoryInterface
 
 import (
-	"github.com/urfave/cli/v2"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
 	"github.com/yanodintsovmercuryo/cursync/pkg/config"
 )
 
@@ -12,8 +16,38 @@ import (
 const (
 	FlagRulesDir         = "rules-dir"
 	FlagFilePatterns     = "file-patterns"
+	FlagExclude          = "exclude"
 	FlagOverwriteHeaders = "overwrite-headers"
 	FlagGitWithoutPush   = "git-without-push"
+	FlagDelete           = "delete"
+	FlagNoDelete         = "no-delete"
+	FlagFileMode         = "file-mode"
+	FlagSymlinks         = "symlinks"
+	FlagLink             = "link"
+	FlagJobs             = "jobs"
+	FlagLineEndings      = "line-endings"
+	FlagCompare          = "compare"
+	FlagDirection        = "direction"
+	FlagDebounce         = "debounce"
+	FlagPoll             = "poll"
+	FlagProfile          = "profile"
+	FlagExplain          = "explain"
+	FlagConfig           = "config"
+	FlagForce            = "force"
+)
+
+// Pattern option names, include patterns are set by --file-patterns and exclude patterns by --exclude
+const (
+	OptionPullInclude = "pull.include"
+	OptionPullExclude = "pull.exclude"
+	OptionPushInclude = "push.include"
+	OptionPushExclude = "push.exclude"
+)
+
+// Backup option names, backup limits are set only in global config
+const (
+	OptionBackupCount  = "backup-count"
+	OptionBackupMaxAge = "backup-max-age"
 )
 
 // Flag aliases constants
@@ -24,56 +58,247 @@ const (
 	FlagAliasGitWithoutPush   = "w"
 )
 
-// Config keys constants (for config.Set/Get)
-const (
-	ConfigKeyRulesDir         = "rules-dir"
-	ConfigKeyFilePatterns     = "file-patterns"
-	ConfigKeyOverwriteHeaders = "overwrite-headers"
-	ConfigKeyGitWithoutPush   = "git-without-push"
-)
-
 // CfgService handles configuration and options creation
 type CfgService struct {
 	configRepository config.ConfigRepositoryInterface
 	output           outputService
+	gitOps           gitOps
+	editor           editor
 }
 
 type outputService interface {
 	PrintErrorf(format string, args ...interface{})
+	PrintWarningf(format string, args ...interface{})
+}
+
+type gitOps interface {
+	GetGitRootDir(startDir string) (string, error)
+}
+
+type editor interface {
+	Edit(path string) error
 }
 
 // NewCfgService creates a new CfgService
-func NewCfgService(configRepository config.ConfigRepositoryInterface, output outputService) *CfgService {
+func NewCfgService(configRepository config.ConfigRepositoryInterface, output outputService, gitOps gitOps, editor editor) *CfgService {
 	return &CfgService{
 		configRepository: configRepository,
 		output:           output,
+		gitOps:           gitOps,
+		editor:           editor,
 	}
 }
 
-// getStringValue returns flag value or config value or empty string
-func (s *CfgService) getStringValue(ctx *cli.Context, flagName string, cfg *config.Config) string {
-	if ctx.IsSet(flagName) {
-		return ctx.String(flagName)
+// Option sources in order of precedence
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "environment"
+	SourceProject = "project file"
+	SourceProfile = "profile"
+	SourceGlobal  = "global file"
+	SourceDefault = "default"
+)
+
+// optionLayer holds configuration values of a single source
+type optionLayer struct {
+	source    string
+	origin    string
+	overrides *config.Overrides
+}
+
+// optionSources holds configuration layers used to resolve option values not set by flags
+type optionSources struct {
+	layers        []optionLayer
+	cfg           *config.Config
+	cfgPath       string
+	profile       string
+	profileSource ResolvedOption
+}
+
+// loadOptionSources loads environment, project config, selected profile and global config in order of precedence
+func (s *CfgService) loadOptionSources(flags flagValues) (*optionSources, error) {
+	cfg, err := s.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cfgPath, err := s.configRepository.GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := s.configRepository.LoadEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	projectCfg, projectCfgPath, err := s.loadProjectConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	sources := &optionSources{
+		layers: []optionLayer{
+			{source: SourceEnv, overrides: &env.Overrides},
+			{source: SourceProject, origin: projectCfgPath, overrides: &projectCfg.Overrides},
+		},
+		cfg:     cfg,
+		cfgPath: cfgPath,
 	}
-	switch flagName {
-	case FlagRulesDir:
-		return cfg.RulesDir
-	case FlagFilePatterns:
-		return cfg.FilePatterns
+
+	sources.profileSource = resolveProfile(flags, env, cfg, cfgPath)
+	sources.profile = sources.profileSource.String()
+	if sources.profile != "" {
+		profile, err := cfg.GetProfile(sources.profile)
+		if err != nil {
+			return nil, err
+		}
+		sources.layers = append(sources.layers, optionLayer{source: SourceProfile, origin: sources.profile, overrides: &profile.Overrides})
 	}
-	return ""
+
+	return sources, nil
 }
 
-// getBoolValue returns flag value or config value or false
-func (s *CfgService) getBoolValue(ctx *cli.Context, flagName string, cfg *config.Config) bool {
-	if ctx.IsSet(flagName) {
-		return ctx.Bool(flagName)
+// loadConfig loads global config and reports migration of an outdated config file
+func (s *CfgService) loadConfig() (*config.Config, error) {
+	cfg, err := s.configRepository.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if m := cfg.Migration; m != nil {
+		s.output.PrintWarningf("Config migrated from version %d to %d, original saved to %s", m.FromVersion, m.ToVersion, m.BackupPath)
 	}
-	switch flagName {
-	case FlagOverwriteHeaders:
-		return cfg.OverwriteHeaders
-	case FlagGitWithoutPush:
-		return cfg.GitWithoutPush
+	return cfg, nil
+}
+
+// resolveProfile selects profile by flag, environment variable or default_profile of global config
+func resolveProfile(flags flagValues, env *config.EnvConfig, cfg *config.Config, cfgPath string) ResolvedOption {
+	switch {
+	case flags.IsSet(FlagProfile):
+		return ResolvedOption{Name: FlagProfile, Value: flags.String(FlagProfile), Source: SourceFlag, Origin: "--" + FlagProfile}
+	case env.DefaultProfile != "":
+		return ResolvedOption{Name: FlagProfile, Value: env.DefaultProfile, Source: SourceEnv, Origin: config.EnvDefaultProfile}
+	case cfg.DefaultProfile != "":
+		return ResolvedOption{Name: FlagProfile, Value: cfg.DefaultProfile, Source: SourceGlobal, Origin: cfgPath}
+	}
+	return ResolvedOption{Name: FlagProfile, Value: "", Source: SourceDefault}
+}
+
+// loadProjectConfig loads config of the current project and returns its path,
+// returns empty config outside of a project
+func (s *CfgService) loadProjectConfig() (*config.ProjectConfig, string, error) {
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return &config.ProjectConfig{}, "", nil
+	}
+
+	projectRoot, err := s.gitOps.GetGitRootDir(currentDir)
+	if err != nil {
+		return &config.ProjectConfig{}, "", nil
+	}
+
+	projectCfgPath := s.configRepository.GetProjectConfigPath(projectRoot)
+	projectCfg, err := s.configRepository.LoadProject(projectRoot)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load project config %s: %w", projectCfgPath, err)
+	}
+	return projectCfg, projectCfgPath, nil
+}
+
+// resolveKey returns value of the config key named like the flag: flag value, first value set in layers,
+// global config value or default of the key
+func (s *CfgService) resolveKey(flags flagValues, flagName string, sources *optionSources) (ResolvedOption, error) {
+	key, err := config.LookupKey(flagName)
+	if err != nil {
+		return ResolvedOption{}, fmt.Errorf("unknown option: %s", flagName)
+	}
+
+	if flags.IsSet(flagName) {
+		var value interface{} = flags.String(flagName)
+		switch key.Type {
+		case config.ValueTypeBool:
+			value = flags.Bool(flagName)
+		case config.ValueTypeList:
+			value = config.ParseList(flags.String(flagName))
+		}
+		return ResolvedOption{Name: flagName, Value: value, Source: SourceFlag, Origin: "--" + flagName}, nil
+	}
+	for _, layer := range sources.layers {
+		if value, ok := key.GetOverride(layer.overrides); ok {
+			return ResolvedOption{Name: flagName, Value: value, Source: layer.source, Origin: layer.originOf(key.Env)}, nil
+		}
+	}
+
+	if value := key.Get(sources.cfg); key.IsSet(value) {
+		return ResolvedOption{Name: flagName, Value: value, Source: SourceGlobal, Origin: sources.cfgPath}, nil
+	}
+	return ResolvedOption{Name: flagName, Value: key.DefaultValue(), Source: SourceDefault}, nil
+}
+
+// resolveDelete returns delete mode set by --no-delete flag or resolved like other keys
+func (s *CfgService) resolveDelete(flags flagValues, sources *optionSources) (ResolvedOption, error) {
+	if flags.IsSet(FlagNoDelete) && flags.Bool(FlagNoDelete) {
+		return ResolvedOption{Name: FlagDelete, Value: string(models.DeleteNone), Source: SourceFlag, Origin: "--" + FlagNoDelete}, nil
+	}
+	return s.resolveKey(flags, FlagDelete, sources)
+}
+
+// resolvePatterns returns patterns set by flag, first patterns set in layers, global config patterns or empty list,
+// include patterns of a direction take precedence over file_patterns of the same layer
+func (s *CfgService) resolvePatterns(flags flagValues, name string, sources *optionSources) ResolvedOption {
+	direction, kind, _ := strings.Cut(name, ".")
+	flagName := FlagFilePatterns
+	if kind == "exclude" {
+		flagName = FlagExclude
+	}
+
+	if flags.IsSet(flagName) {
+		return ResolvedOption{Name: name, Value: config.ParseList(flags.String(flagName)), Source: SourceFlag, Origin: "--" + flagName}
+	}
+	for _, layer := range sources.layers {
+		if value, keyName := patternsOf(layer.overrides, direction, kind); len(value) > 0 {
+			return ResolvedOption{Name: name, Value: value, Source: layer.source, Origin: layer.originOf(envVarOfKey(keyName))}
+		}
+	}
+
+	global := &config.Overrides{FilePatterns: sources.cfg.FilePatterns, Pull: sources.cfg.Pull, Push: sources.cfg.Push}
+	if value, _ := patternsOf(global, direction, kind); len(value) > 0 {
+		return ResolvedOption{Name: name, Value: value, Source: SourceGlobal, Origin: sources.cfgPath}
+	}
+	return ResolvedOption{Name: name, Value: []string(nil), Source: SourceDefault}
+}
+
+// patternsOf returns patterns of the given direction and kind with name of the config key they come from,
+// include patterns fall back to file_patterns
+func patternsOf(o *config.Overrides, direction, kind string) ([]string, string) {
+	scope := o.Pull
+	if direction == "push" {
+		scope = o.Push
+	}
+
+	if kind == "exclude" {
+		return scope.Exclude, direction + ".exclude"
+	}
+	if len(scope.Include) > 0 {
+		return scope.Include, direction + ".include"
+	}
+	return o.FilePatterns, "file_patterns"
+}
+
+// envVarOfKey returns environment variable overriding config key
+func envVarOfKey(keyName string) string {
+	key, err := config.LookupKey(keyName)
+	if err != nil {
+		return ""
+	}
+	return key.Env
+}
+
+// originOf returns environment variable name for environment layer or origin of the layer
+func (l optionLayer) originOf(envVar string) string {
+	if l.source == SourceEnv {
+		return envVar
 	}
-	return false
+	return l.origin
 }
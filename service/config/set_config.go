@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"github.com/yanodintsovmercuryo/cursync/pkg/config"
+)
+
+// SetConfig sets value of a configuration key globally or within the profile selected by flag or default_profile
+func (s *CfgService) SetConfig(ctx *cli.Context) error {
+	args, err := commandArgs(ctx, 2, "set <key> <value>")
+	if err != nil {
+		return err
+	}
+
+	key, err := config.LookupKey(args[0])
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(args[1]) == "" {
+		return fmt.Errorf("empty value for %s, use 'cursync cfg unset %s' to clear it", key.Name, key.Name)
+	}
+
+	value, err := key.ParseValue(args[1])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := s.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	profile := targetProfile(ctx, cfg, key)
+	if profile == "" {
+		err = s.configRepository.Set(cfg, key.Name, value)
+	} else {
+		err = s.configRepository.SetProfile(cfg, profile, key.Name, value)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.configRepository.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Set %s to: %v\n", profileDisplayName(profile, key.Name), value)
+	return nil
+}
+
+// commandArgs returns positional arguments of cfg subcommand or usage error
+func commandArgs(ctx *cli.Context, count int, usage string) ([]string, error) {
+	if ctx.NArg() != count {
+		return nil, fmt.Errorf("usage: cursync cfg %s", usage)
+	}
+	return ctx.Args().Slice(), nil
+}
+
+// targetProfile returns profile cfg subcommand changes the key in: profile selected by flag, or default_profile
+// for keys profiles can set, since values of the default profile override global ones
+func targetProfile(ctx *cli.Context, cfg *config.Config, key config.Key) string {
+	if profile := ctx.String(FlagProfile); profile != "" {
+		return profile
+	}
+	if key.Profile {
+		return cfg.DefaultProfile
+	}
+	return ""
+}
+
+// profileDisplayName returns name of configuration value for output
+func profileDisplayName(profile, name string) string {
+	if profile == "" {
+		return name
+	}
+	return "profile." + profile + "." + name
+}
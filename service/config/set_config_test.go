@@ -0,0 +1,242 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/config"
+	cfgService "github.com/yanodintsovmercuryo/cursync/service/config"
+	"github.com/yanodintsovmercuryo/cursync/service/config/mocks"
+)
+
+func TestCfgService_SetConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sets string value", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Set(cfg, "rules_dir", "/test/rules").
+			Return(nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Save(cfg).
+			Return(nil).
+			Times(1)
+
+		err := f.cfgService.SetConfig(createCLIContext(t, map[string]interface{}{}, "rules-dir", "/test/rules"))
+		require.NoError(t, err)
+	})
+
+	t.Run("sets bool value false", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{OverwriteHeaders: true}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Set(cfg, "overwrite_headers", false).
+			Return(nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Save(cfg).
+			Return(nil).
+			Times(1)
+
+		err := f.cfgService.SetConfig(createCLIContext(t, map[string]interface{}{}, "overwrite_headers", "0"))
+		require.NoError(t, err)
+	})
+
+	t.Run("sets value within profile", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			SetProfile(cfg, "backend", "git_without_push", true).
+			Return(nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Save(cfg).
+			Return(nil).
+			Times(1)
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagProfile: "backend",
+		}, "git_without_push", "true")
+
+		err := f.cfgService.SetConfig(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("sets value within default profile", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{DefaultProfile: "default"}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			SetProfile(cfg, "default", "rules_dir", "/test/rules").
+			Return(nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Save(cfg).
+			Return(nil).
+			Times(1)
+
+		err := f.cfgService.SetConfig(createCLIContext(t, map[string]interface{}{}, "rules_dir", "/test/rules"))
+		require.NoError(t, err)
+	})
+
+	t.Run("sets key profiles cannot set globally", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{DefaultProfile: "default"}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Set(cfg, "backup_count", "5").
+			Return(nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Save(cfg).
+			Return(nil).
+			Times(1)
+
+		err := f.cfgService.SetConfig(createCLIContext(t, map[string]interface{}{}, "backup_count", "5"))
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects invalid bool value", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		err := f.cfgService.SetConfig(createCLIContext(t, map[string]interface{}{}, "overwrite_headers", "yes"))
+		require.ErrorContains(t, err, "invalid boolean value")
+	})
+
+	t.Run("rejects invalid pattern", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		err := f.cfgService.SetConfig(createCLIContext(t, map[string]interface{}{}, "file_patterns", "[a"))
+		require.ErrorContains(t, err, "invalid pattern")
+	})
+
+	t.Run("rejects empty value", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		err := f.cfgService.SetConfig(createCLIContext(t, map[string]interface{}{}, "rules_dir", ""))
+		require.ErrorContains(t, err, "cfg unset rules_dir")
+	})
+
+	t.Run("rejects unknown key", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		err := f.cfgService.SetConfig(createCLIContext(t, map[string]interface{}{}, "unknown", "value"))
+		require.ErrorContains(t, err, "unknown config key")
+	})
+
+	t.Run("rejects missing value", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		err := f.cfgService.SetConfig(createCLIContext(t, map[string]interface{}{}, "rules_dir"))
+		require.ErrorContains(t, err, "usage")
+	})
+
+	t.Run("returns error when save fails", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{}
+		saveErr := errors.New("save error")
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Set(cfg, "rules_dir", "/test/rules").
+			Return(nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Save(cfg).
+			Return(saveErr).
+			Times(1)
+
+		err := f.cfgService.SetConfig(createCLIContext(t, map[string]interface{}{}, "rules_dir", "/test/rules"))
+		require.ErrorIs(t, err, saveErr)
+	})
+}
+
+func TestCfgService_SetConfigAfterMigration(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "cursync.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte("version = 2\nrules_dir = \"/tmp/a\"\n"), 0600))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	outputMock := mocks.NewMockoutputService(ctrl)
+	gitOpsMock := mocks.NewMockgitOps(ctrl)
+	repo := config.NewConfigRepository()
+	repo.SetConfigPath(configPath)
+	service := cfgService.NewCfgService(repo, outputMock, gitOpsMock, mocks.NewMockeditor(ctrl))
+
+	outputMock.EXPECT().
+		PrintWarningf(gomock.Any(), 2, config.CurrentVersion, configPath+".v2.bak").
+		Times(1)
+	gitOpsMock.EXPECT().
+		GetGitRootDir(gomock.Any()).
+		Return("", errors.New("not a git repository")).
+		Times(1)
+
+	err := service.SetConfig(createCLIContext(t, map[string]interface{}{}, "rules_dir", "/tmp/b"))
+	require.NoError(t, err)
+
+	resolved, err := service.ResolveOptions(createCLIContext(t, map[string]interface{}{}), cfgService.FlagRulesDir)
+	require.NoError(t, err)
+
+	expected := cfgService.ResolvedOption{Name: cfgService.FlagRulesDir, Value: "/tmp/b", Source: cfgService.SourceProfile, Origin: "default"}
+	if diff := cmp.Diff(expected, resolved.Get(cfgService.FlagRulesDir)); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -2,31 +2,54 @@ package config
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"github.com/yanodintsovmercuryo/cursync/pkg/config"
 )
 
-// ShowConfig displays current configuration
-func (s *CfgService) ShowConfig() error {
-	cfg, err := s.configRepository.Load()
+// ShowConfig displays current configuration with values of default_profile, or configuration of the profile
+// selected by flag
+func (s *CfgService) ShowConfig(ctx *cli.Context) error {
+	cfg, err := s.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if profile := ctx.String(FlagProfile); profile != "" {
+		return s.showProfile(cfg, profile)
+	}
+
+	env, err := s.configRepository.LoadEnv()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return fmt.Errorf("failed to load config from environment: %w", err)
 	}
 
 	all := s.configRepository.GetAll(cfg)
 	hasAnyValue := false
-	if val, ok := all["rules_dir"].(string); ok && val != "" {
-		fmt.Printf("rules-dir: %s\n", val)
-		hasAnyValue = true
+	for _, key := range config.Keys() {
+		if val := all[key.Name]; key.IsSet(val) {
+			fmt.Printf("%s: %s\n", key.DisplayName(), key.FormatValue(val))
+			hasAnyValue = true
+		}
 	}
-	if val, ok := all["file_patterns"].(string); ok && val != "" {
-		fmt.Printf("file-patterns: %s\n", val)
-		hasAnyValue = true
+	if cfg.DefaultProfile != "" {
+		defaultValues, err := s.configRepository.GetAllProfile(cfg, cfg.DefaultProfile)
+		if err != nil {
+			return err
+		}
+		for _, key := range config.Keys() {
+			if val, ok := defaultValues[key.Name]; ok {
+				fmt.Printf("%s: %s (profile %s)\n", key.DisplayName(), key.FormatValue(val), cfg.DefaultProfile)
+				hasAnyValue = true
+			}
+		}
 	}
-	if val, ok := all["overwrite_headers"].(bool); ok && val {
-		fmt.Printf("overwrite-headers: true\n")
+	if names := cfg.ProfileNames(); len(names) > 0 {
+		fmt.Printf("profiles: %s\n", strings.Join(names, ", "))
 		hasAnyValue = true
 	}
-	if val, ok := all["git_without_push"].(bool); ok && val {
-		fmt.Printf("git-without-push: true\n")
+	if s.showEnv(env) {
 		hasAnyValue = true
 	}
 	if !hasAnyValue {
@@ -35,3 +58,35 @@ func (s *CfgService) ShowConfig() error {
 
 	return nil
 }
+
+// showEnv displays values set by environment variables, returns false if there are none
+func (s *CfgService) showEnv(env *config.EnvConfig) bool {
+	hasAnyValue := false
+	for _, key := range config.Keys() {
+		if val, ok := key.GetOverride(&env.Overrides); ok {
+			fmt.Printf("%s: %s (from %s)\n", key.DisplayName(), key.FormatValue(val), key.Env)
+			hasAnyValue = true
+		}
+	}
+	if env.DefaultProfile != "" {
+		fmt.Printf("default-profile: %s (from %s)\n", env.DefaultProfile, config.EnvDefaultProfile)
+		hasAnyValue = true
+	}
+	return hasAnyValue
+}
+
+// showProfile displays values set within a profile
+func (s *CfgService) showProfile(cfg *config.Config, profile string) error {
+	all, err := s.configRepository.GetAllProfile(cfg, profile)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range config.Keys() {
+		if val, ok := all[key.Name]; ok {
+			fmt.Printf("%s: %s\n", key.DisplayName(), key.FormatValue(val))
+		}
+	}
+
+	return nil
+}
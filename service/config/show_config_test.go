@@ -5,7 +5,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
 	"github.com/yanodintsovmercuryo/cursync/pkg/config"
+	cfgService "github.com/yanodintsovmercuryo/cursync/service/config"
 )
 
 func TestCfgService_ShowConfig(t *testing.T) {
@@ -18,7 +21,7 @@ func TestCfgService_ShowConfig(t *testing.T) {
 
 		cfg := &config.Config{
 			RulesDir:         "/test/rules",
-			FilePatterns:     "*.mdc",
+			FilePatterns:     []string{"*.mdc"},
 			OverwriteHeaders: true,
 			GitWithoutPush:   false,
 		}
@@ -26,6 +29,10 @@ func TestCfgService_ShowConfig(t *testing.T) {
 			Load().
 			Return(cfg, nil).
 			Times(1)
+		f.configRepositoryMock.EXPECT().
+			LoadEnv().
+			Return(&config.EnvConfig{}, nil).
+			Times(1)
 		f.configRepositoryMock.EXPECT().
 			GetAll(cfg).
 			Return(map[string]interface{}{
@@ -36,7 +43,72 @@ func TestCfgService_ShowConfig(t *testing.T) {
 			}).
 			Times(1)
 
-		err := f.cfgService.ShowConfig()
+		err := f.cfgService.ShowConfig(createCLIContext(t, map[string]interface{}{}))
+		require.NoError(t, err)
+	})
+
+	t.Run("displays values of default profile", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{
+			DefaultProfile: "default",
+			Profiles: map[string]*config.Profile{
+				"default": {Overrides: config.Overrides{RulesDir: "/default/rules"}},
+			},
+		}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			LoadEnv().
+			Return(&config.EnvConfig{}, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			GetAll(cfg).
+			Return(map[string]interface{}{"default_profile": "default"}).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			GetAllProfile(cfg, "default").
+			Return(map[string]interface{}{"rules_dir": "/default/rules"}, nil).
+			Times(1)
+
+		err := f.cfgService.ShowConfig(createCLIContext(t, map[string]interface{}{}))
+		require.NoError(t, err)
+	})
+
+	t.Run("reports config migration", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{
+			Version: config.CurrentVersion,
+			Migration: &config.Migration{
+				FromVersion: 0,
+				ToVersion:   config.CurrentVersion,
+				BackupPath:  testConfigPath + ".v0.bak",
+			},
+		}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.outputMock.EXPECT().
+			PrintWarningf(gomock.Any(), 0, config.CurrentVersion, testConfigPath+".v0.bak").
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			LoadEnv().
+			Return(&config.EnvConfig{}, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			GetAll(cfg).
+			Return(map[string]interface{}{}).
+			Times(1)
+
+		err := f.cfgService.ShowConfig(createCLIContext(t, map[string]interface{}{}))
 		require.NoError(t, err)
 	})
 
@@ -50,6 +122,10 @@ func TestCfgService_ShowConfig(t *testing.T) {
 			Load().
 			Return(cfg, nil).
 			Times(1)
+		f.configRepositoryMock.EXPECT().
+			LoadEnv().
+			Return(&config.EnvConfig{}, nil).
+			Times(1)
 		f.configRepositoryMock.EXPECT().
 			GetAll(cfg).
 			Return(map[string]interface{}{
@@ -60,7 +136,7 @@ func TestCfgService_ShowConfig(t *testing.T) {
 			}).
 			Times(1)
 
-		err := f.cfgService.ShowConfig()
+		err := f.cfgService.ShowConfig(createCLIContext(t, map[string]interface{}{}))
 		require.NoError(t, err)
 	})
 
@@ -75,8 +151,53 @@ func TestCfgService_ShowConfig(t *testing.T) {
 			Return(nil, loadErr).
 			Times(1)
 
-		err := f.cfgService.ShowConfig()
+		err := f.cfgService.ShowConfig(createCLIContext(t, map[string]interface{}{}))
 		require.Error(t, err)
 		require.ErrorIs(t, err, loadErr)
 	})
+	t.Run("displays profile values", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			GetAllProfile(cfg, "backend").
+			Return(map[string]interface{}{
+				"rules_dir":        "/backend/rules",
+				"git_without_push": false,
+			}, nil).
+			Times(1)
+
+		err := f.cfgService.ShowConfig(createCLIContext(t, map[string]interface{}{
+			cfgService.FlagProfile: "backend",
+		}))
+		require.NoError(t, err)
+	})
+	t.Run("displays values from environment", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			LoadEnv().
+			Return(&config.EnvConfig{Overrides: config.Overrides{RulesDir: "/env/rules"}}, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			GetAll(cfg).
+			Return(map[string]interface{}{}).
+			Times(1)
+
+		err := f.cfgService.ShowConfig(createCLIContext(t, map[string]interface{}{}))
+		require.NoError(t, err)
+	})
 }
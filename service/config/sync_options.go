@@ -0,0 +1,35 @@
+package config
+
+import (
+	"github.com/yanodintsovmercuryo/cursync/models"
+)
+
+// syncOptionNames lists options resolved and parsed the same way for pull and push
+var syncOptionNames = []string{FlagDelete, FlagFileMode, FlagSymlinks, FlagJobs, FlagLineEndings, FlagCompare}
+
+// parseSyncOptions parses options listed in syncOptionNames into options
+func parseSyncOptions(resolved ResolvedOptions, options *models.SyncOptions) error {
+	var err error
+	if options.DeleteMode, err = models.ParseDeleteMode(resolved.Get(FlagDelete).String()); err != nil {
+		return err
+	}
+	if options.FileMode, err = models.ParseFileMode(resolved.Get(FlagFileMode).String()); err != nil {
+		return err
+	}
+	if options.Symlinks, err = models.ParseSymlinkMode(resolved.Get(FlagSymlinks).String()); err != nil {
+		return err
+	}
+	if options.Jobs, err = models.ParseJobs(resolved.Get(FlagJobs).String()); err != nil {
+		return err
+	}
+	lineEndings := resolved.Get(FlagLineEndings)
+	if options.Normalization.LineEndings, err = models.ParseLineEndings(lineEndings.String()); err != nil {
+		return err
+	}
+	// Other text files were always copied verbatim, so the default policy keeps doing so
+	options.Normalization.AllTextFiles = lineEndings.Source != SourceDefault
+	if options.Normalization.Compare, err = models.ParseCompareMode(resolved.Get(FlagCompare).String()); err != nil {
+		return err
+	}
+	return nil
+}
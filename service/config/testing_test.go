@@ -7,6 +7,7 @@ import (
 	"github.com/urfave/cli/v2"
 	"go.uber.org/mock/gomock"
 
+	"github.com/yanodintsovmercuryo/cursync/pkg/config"
 	cfgService "github.com/yanodintsovmercuryo/cursync/service/config"
 	"github.com/yanodintsovmercuryo/cursync/service/config/mocks"
 )
@@ -16,24 +17,73 @@ type fixture struct {
 
 	configRepositoryMock *mocks.MockConfigRepositoryInterface
 	outputMock           *mocks.MockoutputService
+	gitOpsMock           *mocks.MockgitOps
+	editorMock           *mocks.Mockeditor
 }
 
+const (
+	testProjectRoot       = "/test/project"
+	testProjectConfigPath = "/test/project/.cursync.toml"
+	testConfigPath        = "/test/home/.config/cursync.toml"
+)
+
 func setUp(t *testing.T) (*fixture, func()) {
 	t.Helper()
 	ctrl := gomock.NewController(t)
 	configRepositoryMock := mocks.NewMockConfigRepositoryInterface(ctrl)
 	outputMock := mocks.NewMockoutputService(ctrl)
+	gitOpsMock := mocks.NewMockgitOps(ctrl)
+	editorMock := mocks.NewMockeditor(ctrl)
 
-	cfgService := cfgService.NewCfgService(configRepositoryMock, outputMock)
+	cfgService := cfgService.NewCfgService(configRepositoryMock, outputMock, gitOpsMock, editorMock)
 
 	return &fixture{
 		cfgService:           cfgService,
 		configRepositoryMock: configRepositoryMock,
 		outputMock:           outputMock,
+		gitOpsMock:           gitOpsMock,
+		editorMock:           editorMock,
 	}, ctrl.Finish
 }
 
-func createCLIContext(t *testing.T, flags map[string]interface{}) *cli.Context {
+// expectGlobalConfig sets up loading of global config
+func (f *fixture) expectGlobalConfig(cfg *config.Config) {
+	f.configRepositoryMock.EXPECT().
+		Load().
+		Return(cfg, nil).
+		Times(1)
+	f.configRepositoryMock.EXPECT().
+		GetConfigPath().
+		Return(testConfigPath, nil).
+		Times(1)
+}
+
+// expectEnvConfig sets up loading of config from environment variables
+func (f *fixture) expectEnvConfig(env *config.EnvConfig) {
+	f.configRepositoryMock.EXPECT().
+		LoadEnv().
+		Return(env, nil).
+		Times(1)
+}
+
+// expectProjectConfig sets up lookup of project root and loading of project config
+func (f *fixture) expectProjectConfig(projectCfg *config.ProjectConfig) {
+	f.gitOpsMock.EXPECT().
+		GetGitRootDir(gomock.Any()).
+		Return(testProjectRoot, nil).
+		Times(1)
+	f.configRepositoryMock.EXPECT().
+		GetProjectConfigPath(testProjectRoot).
+		Return(testProjectConfigPath).
+		Times(1)
+	f.configRepositoryMock.EXPECT().
+		LoadProject(testProjectRoot).
+		Return(projectCfg, nil).
+		Times(1)
+}
+
+// createCLIContext creates context with given flags followed by positional arguments
+func createCLIContext(t *testing.T, flags map[string]interface{}, positional ...string) *cli.Context {
 	t.Helper()
 	app := &cli.App{
 		Flags: []cli.Flag{
@@ -41,6 +91,19 @@ func createCLIContext(t *testing.T, flags map[string]interface{}) *cli.Context {
 			&cli.StringFlag{Name: cfgService.FlagFilePatterns, Aliases: []string{cfgService.FlagAliasFilePatterns}},
 			&cli.StringFlag{Name: cfgService.FlagOverwriteHeaders, Aliases: []string{cfgService.FlagAliasOverwriteHeaders}},
 			&cli.StringFlag{Name: cfgService.FlagGitWithoutPush, Aliases: []string{cfgService.FlagAliasGitWithoutPush}},
+			&cli.StringFlag{Name: cfgService.FlagExclude},
+			&cli.StringFlag{Name: cfgService.FlagDelete},
+			&cli.StringFlag{Name: cfgService.FlagFileMode},
+			&cli.StringFlag{Name: cfgService.FlagSymlinks},
+			&cli.StringFlag{Name: cfgService.FlagJobs},
+			&cli.StringFlag{Name: cfgService.FlagLineEndings},
+			&cli.StringFlag{Name: cfgService.FlagCompare},
+			&cli.BoolFlag{Name: cfgService.FlagNoDelete},
+			&cli.StringFlag{Name: cfgService.FlagProfile},
+			&cli.BoolFlag{Name: cfgService.FlagExplain},
+			&cli.BoolFlag{Name: cfgService.FlagLink},
+			&cli.StringFlag{Name: cfgService.FlagDirection},
+			&cli.DurationFlag{Name: cfgService.FlagDebounce},
 		},
 	}
 
@@ -62,6 +125,8 @@ func createCLIContext(t *testing.T, flags map[string]interface{}) *cli.Context {
 		}
 	}
 
+	args = append(args, positional...)
+
 	set := flag.NewFlagSet("test", 0)
 	for _, f := range app.Flags {
 		f.Apply(set)
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+	"github.com/yanodintsovmercuryo/cursync/pkg/config"
+)
+
+// UnsetConfig clears value of a configuration key globally or within the profile selected by flag or default_profile
+func (s *CfgService) UnsetConfig(ctx *cli.Context) error {
+	args, err := commandArgs(ctx, 1, "unset <key>")
+	if err != nil {
+		return err
+	}
+
+	key, err := config.LookupKey(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := s.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	profile := targetProfile(ctx, cfg, key)
+	if profile == "" {
+		err = s.configRepository.Unset(cfg, key.Name)
+	} else {
+		err = s.configRepository.UnsetProfile(cfg, profile, key.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.configRepository.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Cleared %s\n", profileDisplayName(profile, key.Name))
+	return nil
+}
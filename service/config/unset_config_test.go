@@ -0,0 +1,89 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/config"
+	cfgService "github.com/yanodintsovmercuryo/cursync/service/config"
+)
+
+func TestCfgService_UnsetConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("clears global value", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{GitWithoutPush: true}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Unset(cfg, "git_without_push").
+			Return(nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Save(cfg).
+			Return(nil).
+			Times(1)
+
+		err := f.cfgService.UnsetConfig(createCLIContext(t, map[string]interface{}{}, "git-without-push"))
+		require.NoError(t, err)
+	})
+
+	t.Run("clears value within profile", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{}
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			UnsetProfile(cfg, "backend", "rules_dir").
+			Return(nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Save(cfg).
+			Return(nil).
+			Times(1)
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagProfile: "backend",
+		}, "rules_dir")
+
+		err := f.cfgService.UnsetConfig(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("does not save when profile is unknown", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{}
+		unsetErr := errors.New("unknown profile: backend")
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			UnsetProfile(cfg, "backend", "rules_dir").
+			Return(unsetErr).
+			Times(1)
+
+		ctx := createCLIContext(t, map[string]interface{}{
+			cfgService.FlagProfile: "backend",
+		}, "rules_dir")
+
+		err := f.cfgService.UnsetConfig(ctx)
+		require.ErrorIs(t, err, unsetErr)
+	})
+}
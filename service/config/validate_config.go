@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// ValidateConfig checks global config and config of the current project
+func (s *CfgService) ValidateConfig(ctx *cli.Context) error {
+	configPath, err := s.configRepository.GetConfigPath()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := s.loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := s.configRepository.Validate(cfg); err != nil {
+		return fmt.Errorf("invalid config %s:\n%w", configPath, err)
+	}
+
+	projectCfg, projectCfgPath, err := s.loadProjectConfig()
+	if err != nil {
+		return err
+	}
+	if err := s.configRepository.ValidateProject(projectCfg); err != nil {
+		return fmt.Errorf("invalid project config %s:\n%w", projectCfgPath, err)
+	}
+
+	fmt.Println("Configuration is valid.")
+	return nil
+}
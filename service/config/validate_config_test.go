@@ -0,0 +1,67 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/config"
+)
+
+func TestCfgService_ValidateConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("validates global and project config", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{RulesDir: "/test/rules"}
+		projectCfg := &config.ProjectConfig{}
+		f.configRepositoryMock.EXPECT().
+			GetConfigPath().
+			Return(testConfigPath, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Validate(cfg).
+			Return(nil).
+			Times(1)
+		f.expectProjectConfig(projectCfg)
+		f.configRepositoryMock.EXPECT().
+			ValidateProject(projectCfg).
+			Return(nil).
+			Times(1)
+
+		err := f.cfgService.ValidateConfig(createCLIContext(t, map[string]interface{}{}))
+		require.NoError(t, err)
+	})
+
+	t.Run("returns validation error of global config", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		cfg := &config.Config{RulesDir: "/missing"}
+		validateErr := errors.New("rules_dir: directory /missing does not exist")
+		f.configRepositoryMock.EXPECT().
+			GetConfigPath().
+			Return(testConfigPath, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Load().
+			Return(cfg, nil).
+			Times(1)
+		f.configRepositoryMock.EXPECT().
+			Validate(cfg).
+			Return(validateErr).
+			Times(1)
+
+		err := f.cfgService.ValidateConfig(createCLIContext(t, map[string]interface{}{}))
+		require.ErrorIs(t, err, validateErr)
+	})
+}
@@ -3,6 +3,9 @@ package comparator
 import (
 	"path/filepath"
 	"strings"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
 )
 
 const mdcExtension = ".mdc"
@@ -12,48 +15,149 @@ func isMdcFile(filePath string) bool {
 	return strings.EqualFold(filepath.Ext(filePath), mdcExtension)
 }
 
-// AreEqual compares files using header-aware comparison only for .mdc files
-func (c *Comparator) AreEqual(file1, file2 string, overwriteHeaders bool) (bool, error) {
-	if isMdcFile(file1) && isMdcFile(file2) {
-		if overwriteHeaders {
-			return c.areEqualNormalized(file1, file2)
-		}
+// AreEqual compares source file with destination file like AreContentsEqual and binary files byte-for-byte,
+// files are read only if the content index cannot answer
+func (c *Comparator) AreEqual(file1, file2 string, overwriteHeaders bool, normalization models.Normalization) (bool, error) {
+	if equal, known := c.IndexedEqual(file1, file2, overwriteHeaders, normalization); known {
+		return equal, nil
+	}
 
-		return c.areEqualNormalizedWithoutHeaders(file1, file2)
+	binary1, err := c.fileOps.IsBinaryFile(file1)
+	if err != nil {
+		return false, err
 	}
 
-	return c.areEqualNormalized(file1, file2)
-}
+	binary2, err := c.fileOps.IsBinaryFile(file2)
+	if err != nil {
+		return false, err
+	}
+
+	if binary1 || binary2 {
+		// Bytes of binary and text files never match
+		if binary1 != binary2 {
+			return false, nil
+		}
+		return c.AreBinaryEqual(file1, file2)
+	}
 
-// areEqualNormalized compares two files after normalizing their content
-func (c *Comparator) areEqualNormalized(file1, file2 string) (bool, error) {
-	content1, err := c.fileOps.ReadFileNormalized(file1)
+	data1, err := c.ReadFile(file1)
 	if err != nil {
 		return false, err
 	}
 
-	content2, err := c.fileOps.ReadFileNormalized(file2)
+	data2, err := c.ReadFile(file2)
 	if err != nil {
 		return false, err
 	}
 
-	return content1 == content2, nil
+	return c.AreContentsEqual(file1, data1, file2, data2, overwriteHeaders, normalization), nil
+}
+
+// IndexedEqual compares files by digests recorded in the content index, reports if both files are indexed
+// and their digests can answer
+func (c *Comparator) IndexedEqual(file1, file2 string, overwriteHeaders bool, normalization models.Normalization) (equal, known bool) {
+	// Raw hashes tell if destination has bytes of the copy only if the copy is source content as is
+	exact := normalization.Compare == models.CompareExact
+	if exact && (!isVerbatim(file1, normalization) || (isMdcFile(file1) && !overwriteHeaders)) {
+		return false, false
+	}
+
+	digest1, ok := c.index.Lookup(file1)
+	if !ok {
+		return false, false
+	}
+	digest2, ok := c.index.Lookup(file2)
+	if !ok {
+		return false, false
+	}
+
+	if digest1.Binary || digest2.Binary {
+		return digest1.Binary == digest2.Binary && digest1.Content == digest2.Content, true
+	}
+	if exact {
+		return digest1.Raw != "" && digest1.Raw == digest2.Raw, true
+	}
+	if isMdcFile(file1) && isMdcFile(file2) && !overwriteHeaders {
+		return digest1.Body == digest2.Body, true
+	}
+	return digest1.Content == digest2.Content, true
 }
 
-// areEqualNormalizedWithoutHeaders compares two files ignoring YAML headers
-func (c *Comparator) areEqualNormalizedWithoutHeaders(file1, file2 string) (bool, error) {
-	content1, err := c.fileOps.ReadFileNormalized(file1)
+// AreBinaryEqual compares raw contents of files by hashes computed while streaming them
+func (c *Comparator) AreBinaryEqual(file1, file2 string) (bool, error) {
+	hash1, err := c.HashFile(file1)
 	if err != nil {
 		return false, err
 	}
 
-	content2, err := c.fileOps.ReadFileNormalized(file2)
+	hash2, err := c.HashFile(file2)
 	if err != nil {
 		return false, err
 	}
 
-	contentWithoutHeader1 := c.headerService.RemoveHeaderFromContent(content1)
-	contentWithoutHeader2 := c.headerService.RemoveHeaderFromContent(content2)
+	return hash1 == hash2, nil
+}
+
+// HashFile hashes raw content of binary file as a stream and records the hash in the content index
+func (c *Comparator) HashFile(filePath string) (string, error) {
+	before, statErr := c.fileOps.Stat(filePath)
+	hash, err := c.fileOps.HashFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	if statErr == nil {
+		c.index.RecordBinary(filePath, before, hash)
+	}
+	return hash, nil
+}
+
+// ReadFile reads raw content of text file and records its digest in the content index
+func (c *Comparator) ReadFile(filePath string) ([]byte, error) {
+	before, statErr := c.fileOps.Stat(filePath)
+	data, err := c.fileOps.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if statErr == nil {
+		c.index.Record(filePath, before, data)
+	}
+	return data, nil
+}
+
+// AreContentsEqual compares already read contents of source and destination files.
+// Normalized comparison ignores line endings, trailing whitespace and YAML headers of .mdc files unless headers
+// are overwritten, exact comparison checks that destination has the bytes a copy of source would write
+func (c *Comparator) AreContentsEqual(file1 string, data1 []byte, file2 string, data2 []byte, overwriteHeaders bool, normalization models.Normalization) bool {
+	if normalization.Compare == models.CompareExact {
+		return c.Expected(file1, data1, data2, overwriteHeaders, normalization) == string(data2)
+	}
+
+	content1 := file_ops.NormalizeContent(data1)
+	content2 := file_ops.NormalizeContent(data2)
+	if isMdcFile(file1) && isMdcFile(file2) && !overwriteHeaders {
+		return c.headerService.RemoveHeaderFromContent(content1) == c.headerService.RemoveHeaderFromContent(content2)
+	}
+	return content1 == content2
+}
+
+// Expected returns content a copy of source text file writes over destination: .mdc files keep YAML header
+// of destination unless headers are overwritten and get line endings of the policy, other text files get them
+// only if the policy is set explicitly and are copied as is otherwise
+func (c *Comparator) Expected(srcPath string, srcData, dstData []byte, overwriteHeaders bool, normalization models.Normalization) string {
+	if !isMdcFile(srcPath) && !normalization.AllTextFiles {
+		return string(srcData)
+	}
+
+	content := string(srcData)
+	if isMdcFile(srcPath) && !overwriteHeaders {
+		if existingHeader := c.headerService.ExtractHeaderFromContent(string(dstData)); existingHeader != "" {
+			content = existingHeader + c.headerService.RemoveHeaderFromContent(content)
+		}
+	}
+	return file_ops.ApplyLineEndings([]byte(content), normalization.LineEndings)
+}
 
-	return contentWithoutHeader1 == contentWithoutHeader2, nil
+// isVerbatim checks if line endings of the file are kept as in source by copies made with the normalization
+func isVerbatim(filePath string, normalization models.Normalization) bool {
+	return normalization.LineEndings == models.LineEndingsPreserve || (!isMdcFile(filePath) && !normalization.AllTextFiles)
 }
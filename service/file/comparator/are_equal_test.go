@@ -8,6 +8,9 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/index"
 )
 
 const (
@@ -28,17 +31,13 @@ func TestComparator_AreEqual(t *testing.T) {
 		file2 := testFile2Mdc
 		content := testContent
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file1).
-			Return(content, nil).
-			Times(1)
+		f.expectNotIndexed(file1)
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file2).
-			Return(content, nil).
-			Times(1)
+		f.expectRead(file1, content)
+
+		f.expectRead(file2, content)
 
-		result, err := f.comparator.AreEqual(file1, file2, true)
+		result, err := f.comparator.AreEqual(file1, file2, true, models.Normalization{})
 		require.NoError(t, err)
 
 		if diff := cmp.Diff(true, result); diff != "" {
@@ -56,17 +55,13 @@ func TestComparator_AreEqual(t *testing.T) {
 		content1 := "---\nheader\n---\ncontent"
 		content2 := "---\nother\n---\ncontent"
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file1).
-			Return(content1, nil).
-			Times(1)
+		f.expectNotIndexed(file1)
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file2).
-			Return(content2, nil).
-			Times(1)
+		f.expectRead(file1, content1)
 
-		result, err := f.comparator.AreEqual(file1, file2, false)
+		f.expectRead(file2, content2)
+
+		result, err := f.comparator.AreEqual(file1, file2, false, models.Normalization{})
 		require.NoError(t, err)
 
 		if diff := cmp.Diff(true, result); diff != "" {
@@ -83,17 +78,13 @@ func TestComparator_AreEqual(t *testing.T) {
 		file2 := testFile2Txt
 		content := testContent
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file1).
-			Return(content, nil).
-			Times(1)
+		f.expectNotIndexed(file1)
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file2).
-			Return(content, nil).
-			Times(1)
+		f.expectRead(file1, content)
+
+		f.expectRead(file2, content)
 
-		result, err := f.comparator.AreEqual(file1, file2, false)
+		result, err := f.comparator.AreEqual(file1, file2, false, models.Normalization{})
 		require.NoError(t, err)
 
 		if diff := cmp.Diff(true, result); diff != "" {
@@ -111,17 +102,13 @@ func TestComparator_AreEqual(t *testing.T) {
 		content1 := "content1"
 		content2 := "content2"
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file1).
-			Return(content1, nil).
-			Times(1)
+		f.expectNotIndexed(file1)
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file2).
-			Return(content2, nil).
-			Times(1)
+		f.expectRead(file1, content1)
+
+		f.expectRead(file2, content2)
 
-		result, err := f.comparator.AreEqual(file1, file2, false)
+		result, err := f.comparator.AreEqual(file1, file2, false, models.Normalization{})
 		require.NoError(t, err)
 
 		if diff := cmp.Diff(false, result); diff != "" {
@@ -138,12 +125,11 @@ func TestComparator_AreEqual(t *testing.T) {
 		file2 := testFile2Txt
 		expectedErr := errors.New("read error")
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file1).
-			Return("", expectedErr).
-			Times(1)
+		f.expectNotIndexed(file1)
+
+		f.expectReadError(file1, expectedErr)
 
-		result, err := f.comparator.AreEqual(file1, file2, false)
+		result, err := f.comparator.AreEqual(file1, file2, false, models.Normalization{})
 		require.ErrorIs(t, err, expectedErr)
 
 		if diff := cmp.Diff(false, result); diff != "" {
@@ -158,20 +144,15 @@ func TestComparator_AreEqual(t *testing.T) {
 
 		file1 := testFile1Txt
 		file2 := testFile2Txt
-		content1 := "content1"
 		expectedErr := errors.New("read error")
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file1).
-			Return(content1, nil).
-			Times(1)
+		f.expectNotIndexed(file1)
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file2).
-			Return("", expectedErr).
-			Times(1)
+		f.expectText(file1)
+
+		f.expectReadError(file2, expectedErr)
 
-		result, err := f.comparator.AreEqual(file1, file2, false)
+		result, err := f.comparator.AreEqual(file1, file2, false, models.Normalization{})
 		require.ErrorIs(t, err, expectedErr)
 
 		if diff := cmp.Diff(false, result); diff != "" {
@@ -190,17 +171,13 @@ func TestComparator_AreEqual_MdcExtension(t *testing.T) {
 		file2 := "file2.txt"
 		content := testContent
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file1).
-			Return(content, nil).
-			Times(1)
+		f.expectNotIndexed(file1)
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file2).
-			Return(content, nil).
-			Times(1)
+		f.expectRead(file1, content)
+
+		f.expectRead(file2, content)
 
-		result, err := f.comparator.AreEqual(file1, file2, false)
+		result, err := f.comparator.AreEqual(file1, file2, false, models.Normalization{})
 		require.NoError(t, err)
 
 		if diff := cmp.Diff(true, result); diff != "" {
@@ -217,17 +194,13 @@ func TestComparator_AreEqual_MdcExtension(t *testing.T) {
 		file2 := testFile2Mdc
 		content := testContent
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file1).
-			Return(content, nil).
-			Times(1)
+		f.expectNotIndexed(file1)
 
-		f.fileOpsMock.EXPECT().
-			ReadFileNormalized(file2).
-			Return(content, nil).
-			Times(1)
+		f.expectRead(file1, content)
 
-		result, err := f.comparator.AreEqual(file1, file2, false)
+		f.expectRead(file2, content)
+
+		result, err := f.comparator.AreEqual(file1, file2, false, models.Normalization{})
 		require.NoError(t, err)
 
 		// Check that both files have .mdc extension (case-insensitive)
@@ -239,3 +212,290 @@ func TestComparator_AreEqual_MdcExtension(t *testing.T) {
 		}
 	})
 }
+
+func TestComparator_AreEqual_Index(t *testing.T) {
+	t.Run("compares indexed files without reading them", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectIndexed(testFile1Txt, index.Digest{Content: "hash1"})
+		f.expectIndexed(testFile2Txt, index.Digest{Content: "hash2"})
+
+		result, err := f.comparator.AreEqual(testFile1Txt, testFile2Txt, false, models.Normalization{})
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(false, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("compares indexed mdc files without headers", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectIndexed(testFile1Mdc, index.Digest{Content: "hash1", Body: "body"})
+		f.expectIndexed(testFile2Mdc, index.Digest{Content: "hash2", Body: "body"})
+
+		result, err := f.comparator.AreEqual(testFile1Mdc, testFile2Mdc, false, models.Normalization{})
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(true, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("compares indexed mdc files with headers when overwriting them", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectIndexed(testFile1Mdc, index.Digest{Content: "hash1", Body: "body"})
+		f.expectIndexed(testFile2Mdc, index.Digest{Content: "hash2", Body: "body"})
+
+		result, err := f.comparator.AreEqual(testFile1Mdc, testFile2Mdc, true, models.Normalization{})
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(false, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("reads both files if one is not indexed", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectIndexed(testFile1Txt, index.Digest{Content: "hash1"})
+		f.expectNotIndexed(testFile2Txt)
+		f.expectRead(testFile1Txt, testContent)
+		f.expectRead(testFile2Txt, "other")
+
+		result, err := f.comparator.AreEqual(testFile1Txt, testFile2Txt, false, models.Normalization{})
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(false, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestComparator_AreEqual_Binary(t *testing.T) {
+	t.Run("compares binary files by hashes", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectNotIndexed(testFile1Mdc)
+		f.expectHash(testFile1Mdc, "hash")
+		f.expectHash(testFile2Mdc, "hash")
+
+		result, err := f.comparator.AreEqual(testFile1Mdc, testFile2Mdc, false, models.Normalization{})
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(true, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("binary and text files differ", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectNotIndexed(testFile1Txt)
+		f.fileOpsMock.EXPECT().
+			IsBinaryFile(testFile1Txt).
+			Return(true, nil).
+			Times(1)
+		f.expectText(testFile2Txt)
+
+		result, err := f.comparator.AreEqual(testFile1Txt, testFile2Txt, false, models.Normalization{})
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(false, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("compares indexed binary files by raw hashes", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectIndexed(testFile1Mdc, index.Digest{Content: "hash1", Binary: true})
+		f.expectIndexed(testFile2Mdc, index.Digest{Content: "hash2", Body: "hash1"})
+
+		result, err := f.comparator.AreEqual(testFile1Mdc, testFile2Mdc, false, models.Normalization{})
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(false, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("error hashing file", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		expectedErr := errors.New("read error")
+		f.expectNotIndexed(testFile1Txt)
+		f.fileOpsMock.EXPECT().
+			IsBinaryFile(testFile1Txt).
+			Return(true, nil).
+			Times(1)
+		f.fileOpsMock.EXPECT().
+			IsBinaryFile(testFile2Txt).
+			Return(true, nil).
+			Times(1)
+		f.fileOpsMock.EXPECT().
+			Stat(testFile1Txt).
+			Return(nil, expectedErr).
+			Times(1)
+		f.fileOpsMock.EXPECT().
+			HashFile(testFile1Txt).
+			Return("", expectedErr).
+			Times(1)
+
+		_, err := f.comparator.AreEqual(testFile1Txt, testFile2Txt, false, models.Normalization{})
+		require.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestComparator_AreEqual_Normalization(t *testing.T) {
+	exact := models.Normalization{LineEndings: models.LineEndingsLF, Compare: models.CompareExact}
+
+	t.Run("exact comparison detects trailing whitespace", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectNotIndexed(testFile1Txt)
+		f.expectRead(testFile1Txt, "line  \n")
+		f.expectRead(testFile2Txt, "line\n")
+
+		result, err := f.comparator.AreEqual(testFile1Txt, testFile2Txt, false, exact)
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(false, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("exact comparison checks bytes a copy of mdc file writes", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		crlf := models.Normalization{LineEndings: models.LineEndingsCRLF, Compare: models.CompareExact}
+		f.expectRead(testFile1Mdc, "---\nheader1\n---\ncontent\n")
+		f.expectRead(testFile2Mdc, "---\r\nheader2\r\n---\r\ncontent\r\n")
+
+		result, err := f.comparator.AreEqual(testFile1Mdc, testFile2Mdc, false, crlf)
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(true, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("exact comparison checks bytes a copy of text file writes with explicit policy", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		crlf := models.Normalization{LineEndings: models.LineEndingsCRLF, AllTextFiles: true, Compare: models.CompareExact}
+		f.expectRead(testFile1Txt, "line\n")
+		f.expectRead(testFile2Txt, "line\r\n")
+
+		result, err := f.comparator.AreEqual(testFile1Txt, testFile2Txt, false, crlf)
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(true, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("exact comparison of indexed files uses raw hashes", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectIndexed(testFile1Txt, index.Digest{Content: "hash", Raw: "raw1"})
+		f.expectIndexed(testFile2Txt, index.Digest{Content: "hash", Raw: "raw2"})
+
+		result, err := f.comparator.AreEqual(testFile1Txt, testFile2Txt, false, exact)
+		require.NoError(t, err)
+
+		if diff := cmp.Diff(false, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestComparator_Expected(t *testing.T) {
+	f, finish := setUp(t)
+	defer finish()
+
+	tests := []struct {
+		name             string
+		srcPath          string
+		src              string
+		dst              string
+		overwriteHeaders bool
+		normalization    models.Normalization
+		want             string
+	}{
+		{
+			name:          "mdc file keeps destination header",
+			srcPath:       testFile1Mdc,
+			src:           "---\nheader1\n---\r\ncontent  \r\n\r\n",
+			dst:           "---\nheader2\n---\nold\n",
+			normalization: models.Normalization{LineEndings: models.LineEndingsLF},
+			want:          "---\nheader2\n---\ncontent  \n",
+		},
+		{
+			name:             "mdc file with crlf line endings",
+			srcPath:          testFile1Mdc,
+			src:              "---\nheader1\n---\ncontent\n",
+			overwriteHeaders: true,
+			normalization:    models.Normalization{LineEndings: models.LineEndingsCRLF},
+			want:             "---\r\nheader1\r\n---\r\ncontent\r\n",
+		},
+		{
+			name:          "mdc file preserved as is",
+			srcPath:       testFile1Mdc,
+			src:           "---\r\nheader1\r\n---\r\ncontent  \r\n\r\n",
+			dst:           "---\nheader2\n---\nold\n",
+			normalization: models.Normalization{LineEndings: models.LineEndingsPreserve},
+			want:          "---\nheader2\n---\ncontent  \r\n\r\n",
+		},
+		{
+			name:          "other text file copied as is by default",
+			srcPath:       testFile1Txt,
+			src:           "line  \r\n\r\n",
+			normalization: models.Normalization{LineEndings: models.LineEndingsLF},
+			want:          "line  \r\n\r\n",
+		},
+		{
+			name:          "other text file gets explicit line endings without header handling",
+			srcPath:       testFile1Txt,
+			src:           "---\nheader1\n---\nline  \n\n",
+			dst:           "---\nheader2\n---\nold\n",
+			normalization: models.Normalization{LineEndings: models.LineEndingsCRLF, AllTextFiles: true},
+			want:          "---\r\nheader1\r\n---\r\nline  \r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := f.comparator.Expected(tt.srcPath, []byte(tt.src), []byte(tt.dst), tt.overwriteHeaders, tt.normalization)
+
+			if diff := cmp.Diff(tt.want, result); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
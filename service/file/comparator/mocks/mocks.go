@@ -10,8 +10,10 @@
 package mocks
 
 import (
+	os "os"
 	reflect "reflect"
 
+	index "github.com/yanodintsovmercuryo/cursync/pkg/index"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,19 +41,64 @@ func (m *MockfileOps) EXPECT() *MockfileOpsMockRecorder {
 	return m.recorder
 }
 
-// ReadFileNormalized mocks base method.
-func (m *MockfileOps) ReadFileNormalized(filePath string) (string, error) {
+// HashFile mocks base method.
+func (m *MockfileOps) HashFile(filePath string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReadFileNormalized", filePath)
+	ret := m.ctrl.Call(m, "HashFile", filePath)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ReadFileNormalized indicates an expected call of ReadFileNormalized.
-func (mr *MockfileOpsMockRecorder) ReadFileNormalized(filePath any) *gomock.Call {
+// HashFile indicates an expected call of HashFile.
+func (mr *MockfileOpsMockRecorder) HashFile(filePath any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFileNormalized", reflect.TypeOf((*MockfileOps)(nil).ReadFileNormalized), filePath)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashFile", reflect.TypeOf((*MockfileOps)(nil).HashFile), filePath)
+}
+
+// IsBinaryFile mocks base method.
+func (m *MockfileOps) IsBinaryFile(filePath string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBinaryFile", filePath)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBinaryFile indicates an expected call of IsBinaryFile.
+func (mr *MockfileOpsMockRecorder) IsBinaryFile(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBinaryFile", reflect.TypeOf((*MockfileOps)(nil).IsBinaryFile), filePath)
+}
+
+// ReadFile mocks base method.
+func (m *MockfileOps) ReadFile(filePath string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFile", filePath)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFile indicates an expected call of ReadFile.
+func (mr *MockfileOpsMockRecorder) ReadFile(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFile", reflect.TypeOf((*MockfileOps)(nil).ReadFile), filePath)
+}
+
+// Stat mocks base method.
+func (m *MockfileOps) Stat(filePath string) (os.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stat", filePath)
+	ret0, _ := ret[0].(os.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stat indicates an expected call of Stat.
+func (mr *MockfileOpsMockRecorder) Stat(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockfileOps)(nil).Stat), filePath)
 }
 
 // MockheaderService is a mock of headerService interface.
@@ -105,3 +152,66 @@ func (mr *MockheaderServiceMockRecorder) RemoveHeaderFromContent(content any) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveHeaderFromContent", reflect.TypeOf((*MockheaderService)(nil).RemoveHeaderFromContent), content)
 }
+
+// MockcontentIndex is a mock of contentIndex interface.
+type MockcontentIndex struct {
+	ctrl     *gomock.Controller
+	recorder *MockcontentIndexMockRecorder
+	isgomock struct{}
+}
+
+// MockcontentIndexMockRecorder is the mock recorder for MockcontentIndex.
+type MockcontentIndexMockRecorder struct {
+	mock *MockcontentIndex
+}
+
+// NewMockcontentIndex creates a new mock instance.
+func NewMockcontentIndex(ctrl *gomock.Controller) *MockcontentIndex {
+	mock := &MockcontentIndex{ctrl: ctrl}
+	mock.recorder = &MockcontentIndexMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcontentIndex) EXPECT() *MockcontentIndexMockRecorder {
+	return m.recorder
+}
+
+// Lookup mocks base method.
+func (m *MockcontentIndex) Lookup(filePath string) (index.Digest, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lookup", filePath)
+	ret0, _ := ret[0].(index.Digest)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Lookup indicates an expected call of Lookup.
+func (mr *MockcontentIndexMockRecorder) Lookup(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lookup", reflect.TypeOf((*MockcontentIndex)(nil).Lookup), filePath)
+}
+
+// Record mocks base method.
+func (m *MockcontentIndex) Record(filePath string, before os.FileInfo, data []byte) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", filePath, before, data)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockcontentIndexMockRecorder) Record(filePath, before, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockcontentIndex)(nil).Record), filePath, before, data)
+}
+
+// RecordBinary mocks base method.
+func (m *MockcontentIndex) RecordBinary(filePath string, before os.FileInfo, hash string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordBinary", filePath, before, hash)
+}
+
+// RecordBinary indicates an expected call of RecordBinary.
+func (mr *MockcontentIndexMockRecorder) RecordBinary(filePath, before, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordBinary", reflect.TypeOf((*MockcontentIndex)(nil).RecordBinary), filePath, before, hash)
+}
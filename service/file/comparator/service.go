@@ -2,11 +2,17 @@
 package comparator
 
 import (
+	"os"
+
 	"github.com/yanodintsovmercuryo/cursync/pkg/header"
+	"github.com/yanodintsovmercuryo/cursync/pkg/index"
 )
 
 type fileOps interface {
-	ReadFileNormalized(filePath string) (string, error)
+	ReadFile(filePath string) ([]byte, error)
+	Stat(filePath string) (os.FileInfo, error)
+	IsBinaryFile(filePath string) (bool, error)
+	HashFile(filePath string) (string, error)
 }
 
 type headerService interface {
@@ -14,18 +20,26 @@ type headerService interface {
 	ExtractHeaderFromContent(content string) string
 }
 
+type contentIndex interface {
+	Lookup(filePath string) (index.Digest, bool)
+	Record(filePath string, before os.FileInfo, data []byte)
+	RecordBinary(filePath string, before os.FileInfo, hash string)
+}
+
 // Comparator handles file comparison
 type Comparator struct {
 	fileOps       fileOps
 	headerService headerService
+	index         contentIndex
 }
 
 // NewComparator creates a new Comparator instance
-func NewComparator(fileOps fileOps) *Comparator {
+func NewComparator(fileOps fileOps, index contentIndex) *Comparator {
 	headerService := header.NewHeader()
 
 	return &Comparator{
 		fileOps:       fileOps,
 		headerService: headerService,
+		index:         index,
 	}
 }
@@ -1,10 +1,13 @@
 package comparator_test
 
 import (
+	"os"
 	"testing"
+	"time"
 
 	"go.uber.org/mock/gomock"
 
+	"github.com/yanodintsovmercuryo/cursync/pkg/index"
 	"github.com/yanodintsovmercuryo/cursync/service/file/comparator"
 	"github.com/yanodintsovmercuryo/cursync/service/file/comparator/mocks"
 )
@@ -13,15 +16,98 @@ type fixture struct {
 	comparator *comparator.Comparator
 
 	fileOpsMock *mocks.MockfileOps
+	indexMock   *mocks.MockcontentIndex
 }
 
 func setUp(t *testing.T) (*fixture, func()) {
 	t.Helper()
 	ctrl := gomock.NewController(t)
 	fileOpsMock := mocks.NewMockfileOps(ctrl)
+	indexMock := mocks.NewMockcontentIndex(ctrl)
 
 	return &fixture{
-		comparator:  comparator.NewComparator(fileOpsMock),
+		comparator:  comparator.NewComparator(fileOpsMock, indexMock),
 		fileOpsMock: fileOpsMock,
+		indexMock:   indexMock,
 	}, ctrl.Finish
 }
+
+// fileInfo is stat information of a test file
+type fileInfo struct {
+	os.FileInfo
+	size int64
+}
+
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) ModTime() time.Time { return time.Time{} }
+
+// expectNotIndexed expects lookup of file missing in the content index
+func (f *fixture) expectNotIndexed(filePath string) {
+	f.indexMock.EXPECT().
+		Lookup(filePath).
+		Return(index.Digest{}, false).
+		Times(1)
+}
+
+// expectIndexed expects lookup of file found in the content index
+func (f *fixture) expectIndexed(filePath string, digest index.Digest) {
+	f.indexMock.EXPECT().
+		Lookup(filePath).
+		Return(digest, true).
+		Times(1)
+}
+
+// expectText expects file to be detected as text
+func (f *fixture) expectText(filePath string) {
+	f.fileOpsMock.EXPECT().
+		IsBinaryFile(filePath).
+		Return(false, nil).
+		Times(1)
+}
+
+// expectRead expects text file to be read and recorded in the content index
+func (f *fixture) expectRead(filePath, content string) {
+	f.expectText(filePath)
+
+	info := fileInfo{size: int64(len(content))}
+	f.fileOpsMock.EXPECT().
+		Stat(filePath).
+		Return(info, nil).
+		Times(1)
+	f.fileOpsMock.EXPECT().
+		ReadFile(filePath).
+		Return([]byte(content), nil).
+		Times(1)
+	f.indexMock.EXPECT().
+		Record(filePath, info, []byte(content)).
+		Times(1)
+}
+
+// expectHash expects binary file to be hashed and recorded in the content index
+func (f *fixture) expectHash(filePath, hash string) {
+	f.fileOpsMock.EXPECT().
+		IsBinaryFile(filePath).
+		Return(true, nil).
+		Times(1)
+
+	info := fileInfo{size: 1}
+	f.fileOpsMock.EXPECT().
+		Stat(filePath).
+		Return(info, nil).
+		Times(1)
+	f.fileOpsMock.EXPECT().
+		HashFile(filePath).
+		Return(hash, nil).
+		Times(1)
+	f.indexMock.EXPECT().
+		RecordBinary(filePath, info, hash).
+		Times(1)
+}
+
+// expectReadError expects detection of file kind to fail
+func (f *fixture) expectReadError(filePath string, err error) {
+	f.fileOpsMock.EXPECT().
+		IsBinaryFile(filePath).
+		Return(false, err).
+		Times(1)
+}
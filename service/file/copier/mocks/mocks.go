@@ -12,7 +12,10 @@ package mocks
 import (
 	os "os"
 	reflect "reflect"
+	time "time"
 
+	models "github.com/yanodintsovmercuryo/cursync/models"
+	index "github.com/yanodintsovmercuryo/cursync/pkg/index"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,6 +43,34 @@ func (m *MockfileOps) EXPECT() *MockfileOpsMockRecorder {
 	return m.recorder
 }
 
+// Chmod mocks base method.
+func (m *MockfileOps) Chmod(filePath string, mode os.FileMode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Chmod", filePath, mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Chmod indicates an expected call of Chmod.
+func (mr *MockfileOpsMockRecorder) Chmod(filePath, mode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Chmod", reflect.TypeOf((*MockfileOps)(nil).Chmod), filePath, mode)
+}
+
+// Chtimes mocks base method.
+func (m *MockfileOps) Chtimes(filePath string, atime, mtime time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Chtimes", filePath, atime, mtime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Chtimes indicates an expected call of Chtimes.
+func (mr *MockfileOpsMockRecorder) Chtimes(filePath, atime, mtime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Chtimes", reflect.TypeOf((*MockfileOps)(nil).Chtimes), filePath, atime, mtime)
+}
+
 // CopyFile mocks base method.
 func (m *MockfileOps) CopyFile(srcPath, dstPath string) error {
 	m.ctrl.T.Helper()
@@ -54,34 +85,79 @@ func (mr *MockfileOpsMockRecorder) CopyFile(srcPath, dstPath any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyFile", reflect.TypeOf((*MockfileOps)(nil).CopyFile), srcPath, dstPath)
 }
 
-// FileExists mocks base method.
-func (m *MockfileOps) FileExists(filePath string) (bool, error) {
+// HashFile mocks base method.
+func (m *MockfileOps) HashFile(filePath string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FileExists", filePath)
+	ret := m.ctrl.Call(m, "HashFile", filePath)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HashFile indicates an expected call of HashFile.
+func (mr *MockfileOpsMockRecorder) HashFile(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashFile", reflect.TypeOf((*MockfileOps)(nil).HashFile), filePath)
+}
+
+// IsBinaryFile mocks base method.
+func (m *MockfileOps) IsBinaryFile(filePath string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBinaryFile", filePath)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FileExists indicates an expected call of FileExists.
-func (mr *MockfileOpsMockRecorder) FileExists(filePath any) *gomock.Call {
+// IsBinaryFile indicates an expected call of IsBinaryFile.
+func (mr *MockfileOpsMockRecorder) IsBinaryFile(filePath any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FileExists", reflect.TypeOf((*MockfileOps)(nil).FileExists), filePath)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBinaryFile", reflect.TypeOf((*MockfileOps)(nil).IsBinaryFile), filePath)
 }
 
-// ReadFileNormalized mocks base method.
-func (m *MockfileOps) ReadFileNormalized(filePath string) (string, error) {
+// IsLargeTextFile mocks base method.
+func (m *MockfileOps) IsLargeTextFile(filePath string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReadFileNormalized", filePath)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "IsLargeTextFile", filePath)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsLargeTextFile indicates an expected call of IsLargeTextFile.
+func (mr *MockfileOpsMockRecorder) IsLargeTextFile(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsLargeTextFile", reflect.TypeOf((*MockfileOps)(nil).IsLargeTextFile), filePath)
+}
+
+// ReadFile mocks base method.
+func (m *MockfileOps) ReadFile(filePath string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFile", filePath)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFile indicates an expected call of ReadFile.
+func (mr *MockfileOpsMockRecorder) ReadFile(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFile", reflect.TypeOf((*MockfileOps)(nil).ReadFile), filePath)
+}
+
+// Stat mocks base method.
+func (m *MockfileOps) Stat(filePath string) (os.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stat", filePath)
+	ret0, _ := ret[0].(os.FileInfo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ReadFileNormalized indicates an expected call of ReadFileNormalized.
-func (mr *MockfileOpsMockRecorder) ReadFileNormalized(filePath any) *gomock.Call {
+// Stat indicates an expected call of Stat.
+func (mr *MockfileOpsMockRecorder) Stat(filePath any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFileNormalized", reflect.TypeOf((*MockfileOps)(nil).ReadFileNormalized), filePath)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockfileOps)(nil).Stat), filePath)
 }
 
 // WriteFile mocks base method.
@@ -98,54 +174,215 @@ func (mr *MockfileOpsMockRecorder) WriteFile(filePath, content, perm any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteFile", reflect.TypeOf((*MockfileOps)(nil).WriteFile), filePath, content, perm)
 }
 
-// MockheaderService is a mock of headerService interface.
-type MockheaderService struct {
+// MockoutputService is a mock of outputService interface.
+type MockoutputService struct {
 	ctrl     *gomock.Controller
-	recorder *MockheaderServiceMockRecorder
+	recorder *MockoutputServiceMockRecorder
 	isgomock struct{}
 }
 
-// MockheaderServiceMockRecorder is the mock recorder for MockheaderService.
-type MockheaderServiceMockRecorder struct {
-	mock *MockheaderService
+// MockoutputServiceMockRecorder is the mock recorder for MockoutputService.
+type MockoutputServiceMockRecorder struct {
+	mock *MockoutputService
 }
 
-// NewMockheaderService creates a new mock instance.
-func NewMockheaderService(ctrl *gomock.Controller) *MockheaderService {
-	mock := &MockheaderService{ctrl: ctrl}
-	mock.recorder = &MockheaderServiceMockRecorder{mock}
+// NewMockoutputService creates a new mock instance.
+func NewMockoutputService(ctrl *gomock.Controller) *MockoutputService {
+	mock := &MockoutputService{ctrl: ctrl}
+	mock.recorder = &MockoutputServiceMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockheaderService) EXPECT() *MockheaderServiceMockRecorder {
+func (m *MockoutputService) EXPECT() *MockoutputServiceMockRecorder {
 	return m.recorder
 }
 
-// ExtractHeaderFromContent mocks base method.
-func (m *MockheaderService) ExtractHeaderFromContent(content string) string {
+// PrintWarningf mocks base method.
+func (m *MockoutputService) PrintWarningf(format string, args ...any) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExtractHeaderFromContent", content)
-	ret0, _ := ret[0].(string)
+	varargs := []any{format}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "PrintWarningf", varargs...)
+}
+
+// PrintWarningf indicates an expected call of PrintWarningf.
+func (mr *MockoutputServiceMockRecorder) PrintWarningf(format any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{format}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrintWarningf", reflect.TypeOf((*MockoutputService)(nil).PrintWarningf), varargs...)
+}
+
+// MockcomparatorService is a mock of comparatorService interface.
+type MockcomparatorService struct {
+	ctrl     *gomock.Controller
+	recorder *MockcomparatorServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockcomparatorServiceMockRecorder is the mock recorder for MockcomparatorService.
+type MockcomparatorServiceMockRecorder struct {
+	mock *MockcomparatorService
+}
+
+// NewMockcomparatorService creates a new mock instance.
+func NewMockcomparatorService(ctrl *gomock.Controller) *MockcomparatorService {
+	mock := &MockcomparatorService{ctrl: ctrl}
+	mock.recorder = &MockcomparatorServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcomparatorService) EXPECT() *MockcomparatorServiceMockRecorder {
+	return m.recorder
+}
+
+// AreBinaryEqual mocks base method.
+func (m *MockcomparatorService) AreBinaryEqual(file1, file2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AreBinaryEqual", file1, file2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AreBinaryEqual indicates an expected call of AreBinaryEqual.
+func (mr *MockcomparatorServiceMockRecorder) AreBinaryEqual(file1, file2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AreBinaryEqual", reflect.TypeOf((*MockcomparatorService)(nil).AreBinaryEqual), file1, file2)
+}
+
+// AreContentsEqual mocks base method.
+func (m *MockcomparatorService) AreContentsEqual(file1 string, data1 []byte, file2 string, data2 []byte, overwriteHeaders bool, normalization models.Normalization) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AreContentsEqual", file1, data1, file2, data2, overwriteHeaders, normalization)
+	ret0, _ := ret[0].(bool)
 	return ret0
 }
 
-// ExtractHeaderFromContent indicates an expected call of ExtractHeaderFromContent.
-func (mr *MockheaderServiceMockRecorder) ExtractHeaderFromContent(content any) *gomock.Call {
+// AreContentsEqual indicates an expected call of AreContentsEqual.
+func (mr *MockcomparatorServiceMockRecorder) AreContentsEqual(file1, data1, file2, data2, overwriteHeaders, normalization any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractHeaderFromContent", reflect.TypeOf((*MockheaderService)(nil).ExtractHeaderFromContent), content)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AreContentsEqual", reflect.TypeOf((*MockcomparatorService)(nil).AreContentsEqual), file1, data1, file2, data2, overwriteHeaders, normalization)
 }
 
-// RemoveHeaderFromContent mocks base method.
-func (m *MockheaderService) RemoveHeaderFromContent(content string) string {
+// Expected mocks base method.
+func (m *MockcomparatorService) Expected(srcPath string, srcData, dstData []byte, overwriteHeaders bool, normalization models.Normalization) string {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveHeaderFromContent", content)
+	ret := m.ctrl.Call(m, "Expected", srcPath, srcData, dstData, overwriteHeaders, normalization)
 	ret0, _ := ret[0].(string)
 	return ret0
 }
 
-// RemoveHeaderFromContent indicates an expected call of RemoveHeaderFromContent.
-func (mr *MockheaderServiceMockRecorder) RemoveHeaderFromContent(content any) *gomock.Call {
+// Expected indicates an expected call of Expected.
+func (mr *MockcomparatorServiceMockRecorder) Expected(srcPath, srcData, dstData, overwriteHeaders, normalization any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expected", reflect.TypeOf((*MockcomparatorService)(nil).Expected), srcPath, srcData, dstData, overwriteHeaders, normalization)
+}
+
+// IndexedEqual mocks base method.
+func (m *MockcomparatorService) IndexedEqual(file1, file2 string, overwriteHeaders bool, normalization models.Normalization) (bool, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IndexedEqual", file1, file2, overwriteHeaders, normalization)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// IndexedEqual indicates an expected call of IndexedEqual.
+func (mr *MockcomparatorServiceMockRecorder) IndexedEqual(file1, file2, overwriteHeaders, normalization any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexedEqual", reflect.TypeOf((*MockcomparatorService)(nil).IndexedEqual), file1, file2, overwriteHeaders, normalization)
+}
+
+// ReadFile mocks base method.
+func (m *MockcomparatorService) ReadFile(filePath string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFile", filePath)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFile indicates an expected call of ReadFile.
+func (mr *MockcomparatorServiceMockRecorder) ReadFile(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFile", reflect.TypeOf((*MockcomparatorService)(nil).ReadFile), filePath)
+}
+
+// MockcontentIndex is a mock of contentIndex interface.
+type MockcontentIndex struct {
+	ctrl     *gomock.Controller
+	recorder *MockcontentIndexMockRecorder
+	isgomock struct{}
+}
+
+// MockcontentIndexMockRecorder is the mock recorder for MockcontentIndex.
+type MockcontentIndexMockRecorder struct {
+	mock *MockcontentIndex
+}
+
+// NewMockcontentIndex creates a new mock instance.
+func NewMockcontentIndex(ctrl *gomock.Controller) *MockcontentIndex {
+	mock := &MockcontentIndex{ctrl: ctrl}
+	mock.recorder = &MockcontentIndexMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcontentIndex) EXPECT() *MockcontentIndexMockRecorder {
+	return m.recorder
+}
+
+// Forget mocks base method.
+func (m *MockcontentIndex) Forget(filePath string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Forget", filePath)
+}
+
+// Forget indicates an expected call of Forget.
+func (mr *MockcontentIndexMockRecorder) Forget(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Forget", reflect.TypeOf((*MockcontentIndex)(nil).Forget), filePath)
+}
+
+// Lookup mocks base method.
+func (m *MockcontentIndex) Lookup(filePath string) (index.Digest, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lookup", filePath)
+	ret0, _ := ret[0].(index.Digest)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Lookup indicates an expected call of Lookup.
+func (mr *MockcontentIndexMockRecorder) Lookup(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lookup", reflect.TypeOf((*MockcontentIndex)(nil).Lookup), filePath)
+}
+
+// Record mocks base method.
+func (m *MockcontentIndex) Record(filePath string, before os.FileInfo, data []byte) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", filePath, before, data)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockcontentIndexMockRecorder) Record(filePath, before, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockcontentIndex)(nil).Record), filePath, before, data)
+}
+
+// RecordBinary mocks base method.
+func (m *MockcontentIndex) RecordBinary(filePath string, before os.FileInfo, hash string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordBinary", filePath, before, hash)
+}
+
+// RecordBinary indicates an expected call of RecordBinary.
+func (mr *MockcontentIndexMockRecorder) RecordBinary(filePath, before, hash any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveHeaderFromContent", reflect.TypeOf((*MockheaderService)(nil).RemoveHeaderFromContent), content)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordBinary", reflect.TypeOf((*MockcontentIndex)(nil).RecordBinary), filePath, before, hash)
 }
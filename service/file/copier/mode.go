@@ -0,0 +1,47 @@
+package copier
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+)
+
+// ModeMatches checks if destination file already has permissions the copy would give it
+func (c *Copier) ModeMatches(srcPath, dstPath string, mode os.FileMode) (bool, error) {
+	if mode == models.FileModePreserve {
+		srcInfo, err := c.fileOps.Stat(srcPath)
+		if err != nil {
+			return false, fmt.Errorf("failed to stat source file %s: %w", srcPath, err)
+		}
+		mode = srcInfo.Mode().Perm()
+	}
+
+	dstInfo, err := c.fileOps.Stat(dstPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat destination file %s: %w", dstPath, err)
+	}
+	return dstInfo.Mode().Perm() == mode, nil
+}
+
+// applyMode sets fixed permissions of destination file, or copies permissions and modification time of source file
+func (c *Copier) applyMode(srcPath, dstPath string, mode os.FileMode) error {
+	if mode != models.FileModePreserve {
+		if err := c.fileOps.Chmod(dstPath, mode); err != nil {
+			return fmt.Errorf("failed to set mode of %s: %w", dstPath, err)
+		}
+		return nil
+	}
+
+	srcInfo, err := c.fileOps.Stat(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat source file %s: %w", srcPath, err)
+	}
+	if err := c.fileOps.Chmod(dstPath, srcInfo.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to set mode of %s: %w", dstPath, err)
+	}
+	if err := c.fileOps.Chtimes(dstPath, srcInfo.ModTime(), srcInfo.ModTime()); err != nil {
+		return fmt.Errorf("failed to set modification time of %s: %w", dstPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package copier_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+)
+
+func TestCopier_ModeMatches(t *testing.T) {
+	dir := t.TempDir()
+	executable := filepath.Join(dir, "script.sh")
+	regular := filepath.Join(dir, "rule.mdc")
+	require.NoError(t, os.WriteFile(executable, []byte("#!/bin/sh\n"), 0600))
+	require.NoError(t, os.Chmod(executable, 0755))
+	require.NoError(t, os.WriteFile(regular, []byte("content"), 0600))
+	require.NoError(t, os.Chmod(regular, 0644))
+	executableInfo, err := os.Stat(executable)
+	require.NoError(t, err)
+	regularInfo, err := os.Stat(regular)
+	require.NoError(t, err)
+
+	t.Run("preserve compares with source mode", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.fileOpsMock.EXPECT().
+			Stat(executable).
+			Return(executableInfo, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Stat(regular).
+			Return(regularInfo, nil).
+			Times(1)
+
+		matches, err := f.copier.ModeMatches(executable, regular, models.FileModePreserve)
+		require.NoError(t, err)
+		require.False(t, matches)
+	})
+
+	t.Run("fixed mode compares with destination mode", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.fileOpsMock.EXPECT().
+			Stat(regular).
+			Return(regularInfo, nil).
+			Times(1)
+
+		matches, err := f.copier.ModeMatches(executable, regular, 0644)
+		require.NoError(t, err)
+		require.True(t, matches)
+	})
+}
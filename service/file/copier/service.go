@@ -3,34 +3,58 @@ package copier
 
 import (
 	"os"
+	"time"
 
-	"github.com/yanodintsovmercuryo/cursync/pkg/header"
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/index"
+	"github.com/yanodintsovmercuryo/cursync/service/file/comparator"
 )
 
 type fileOps interface {
-	ReadFileNormalized(filePath string) (string, error)
+	ReadFile(filePath string) ([]byte, error)
 	WriteFile(filePath, content string, perm os.FileMode) error
-	FileExists(filePath string) (bool, error)
 	CopyFile(srcPath, dstPath string) error
+	Stat(filePath string) (os.FileInfo, error)
+	Chmod(filePath string, mode os.FileMode) error
+	Chtimes(filePath string, atime, mtime time.Time) error
+	IsBinaryFile(filePath string) (bool, error)
+	IsLargeTextFile(filePath string) (bool, error)
+	HashFile(filePath string) (string, error)
 }
 
-type headerService interface {
-	RemoveHeaderFromContent(content string) string
-	ExtractHeaderFromContent(content string) string
+type outputService interface {
+	PrintWarningf(format string, args ...interface{})
+}
+
+type comparatorService interface {
+	IndexedEqual(file1, file2 string, overwriteHeaders bool, normalization models.Normalization) (equal, known bool)
+	ReadFile(filePath string) ([]byte, error)
+	AreBinaryEqual(file1, file2 string) (bool, error)
+	AreContentsEqual(file1 string, data1 []byte, file2 string, data2 []byte, overwriteHeaders bool, normalization models.Normalization) bool
+	Expected(srcPath string, srcData, dstData []byte, overwriteHeaders bool, normalization models.Normalization) string
+}
+
+type contentIndex interface {
+	Lookup(filePath string) (index.Digest, bool)
+	Record(filePath string, before os.FileInfo, data []byte)
+	RecordBinary(filePath string, before os.FileInfo, hash string)
+	Forget(filePath string)
 }
 
 // Copier handles file copying with header preservation support
 type Copier struct {
-	fileOps       fileOps
-	headerService headerService
+	output     outputService
+	fileOps    fileOps
+	comparator comparatorService
+	index      contentIndex
 }
 
 // NewCopier creates a new Copier instance
-func NewCopier(fileOps fileOps) *Copier {
-	headerService := header.NewHeader()
-
+func NewCopier(output outputService, fileOps fileOps, index contentIndex) *Copier {
 	return &Copier{
-		fileOps:       fileOps,
-		headerService: headerService,
+		output:     output,
+		fileOps:    fileOps,
+		comparator: comparator.NewComparator(fileOps, index),
+		index:      index,
 	}
 }
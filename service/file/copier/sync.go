@@ -0,0 +1,109 @@
+package copier
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/file_ops"
+)
+
+const mdcExtension = ".mdc"
+
+// Sync copies file unless destination already has the same content and mode, .mdc files keep YAML header
+// of destination unless headers are overwritten and are written with line endings of the normalization policy,
+// other text files only if the policy applies to all text files.
+// Files indexed as unchanged are compared without reading. Binary files are compared by hashes of streamed
+// content and copied verbatim, each text file is read once and its content is shared between comparison and copy.
+// Unreadable destination is overwritten like a missing one. Reports if destination was written
+func (c *Copier) Sync(srcPath, dstPath string, dstExists, overwriteHeaders bool, mode os.FileMode, normalization models.Normalization) (bool, error) {
+	if dstExists {
+		if equal, known := c.comparator.IndexedEqual(srcPath, dstPath, overwriteHeaders, normalization); known && equal {
+			if matches, err := c.ModeMatches(srcPath, dstPath, mode); err == nil && matches {
+				return false, nil
+			}
+		}
+	}
+
+	binary, err := c.fileOps.IsBinaryFile(srcPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read source file %s: %w", srcPath, err)
+	}
+
+	// Destination is compared only if it is readable and of the same kind, bytes of binary and text files never match
+	compare := false
+	if dstExists {
+		dstBinary, err := c.fileOps.IsBinaryFile(dstPath)
+		compare = err == nil && dstBinary == binary
+	}
+
+	if binary {
+		c.warnLargeText(srcPath)
+		return c.syncBinary(srcPath, dstPath, compare, mode)
+	}
+	return c.syncText(srcPath, dstPath, compare, overwriteHeaders, mode, normalization)
+}
+
+// warnLargeText warns that text file too large to be loaded into memory is synced as binary,
+// so its line endings, trailing whitespace and .mdc header are kept as is
+func (c *Copier) warnLargeText(srcPath string) {
+	if large, err := c.fileOps.IsLargeTextFile(srcPath); err == nil && large {
+		c.output.PrintWarningf("%s is larger than %d MiB and is synced as binary, its line endings and header are kept as is", srcPath, file_ops.MaxTextSize>>20)
+	}
+}
+
+// syncBinary copies binary file verbatim as a stream unless destination has the same bytes and mode
+func (c *Copier) syncBinary(srcPath, dstPath string, compare bool, mode os.FileMode) (bool, error) {
+	if compare {
+		if equal, err := c.comparator.AreBinaryEqual(srcPath, dstPath); err == nil && equal {
+			if matches, err := c.ModeMatches(srcPath, dstPath, mode); err == nil && matches {
+				return false, nil
+			}
+		}
+	}
+
+	if err := c.fileOps.CopyFile(srcPath, dstPath); err != nil {
+		return false, err
+	}
+	c.index.Forget(dstPath)
+
+	if err := c.applyMode(srcPath, dstPath, mode); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// syncText copies text file unless destination has the same content and mode
+func (c *Copier) syncText(srcPath, dstPath string, compare, overwriteHeaders bool, mode os.FileMode, normalization models.Normalization) (bool, error) {
+	srcData, err := c.comparator.ReadFile(srcPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read source file %s: %w", srcPath, err)
+	}
+
+	var dstData []byte
+	if compare {
+		if dstData, err = c.comparator.ReadFile(dstPath); err == nil {
+			if c.comparator.AreContentsEqual(srcPath, srcData, dstPath, dstData, overwriteHeaders, normalization) {
+				if matches, err := c.ModeMatches(srcPath, dstPath, mode); err == nil && matches {
+					return false, nil
+				}
+			}
+		}
+	}
+
+	content := c.comparator.Expected(srcPath, srcData, dstData, overwriteHeaders, normalization)
+	var perm os.FileMode = 0600
+	if filepath.Ext(srcPath) == mdcExtension {
+		perm = 0644
+	}
+	if err := c.fileOps.WriteFile(dstPath, content, perm); err != nil {
+		return false, fmt.Errorf("failed to write destination file %s: %w", dstPath, err)
+	}
+	c.index.Forget(dstPath)
+
+	if err := c.applyMode(srcPath, dstPath, mode); err != nil {
+		return false, err
+	}
+	return true, nil
+}
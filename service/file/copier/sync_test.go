@@ -0,0 +1,529 @@
+package copier_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/index"
+)
+
+const (
+	testFileMdc = "file.mdc"
+	testDestMdc = "dest.mdc"
+)
+
+func TestCopier_Sync(t *testing.T) {
+	dir := t.TempDir()
+	regular := filepath.Join(dir, "rule.mdc")
+	require.NoError(t, os.WriteFile(regular, []byte("content"), 0600))
+	require.NoError(t, os.Chmod(regular, 0644))
+	regularInfo, err := os.Stat(regular)
+	require.NoError(t, err)
+
+	t.Run("skips destination with same content and mode", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectNotIndexed(testFileMdc)
+		f.expectText(testFileMdc)
+		f.expectText(regular)
+		f.expectRead(testFileMdc, "---\nheader1\n---\ncontent\n")
+
+		f.expectRead(regular, "---\nheader2\n---\r\ncontent\r\n\r\n")
+
+		f.fileOpsMock.EXPECT().
+			Stat(regular).
+			Return(regularInfo, nil).
+			Times(1)
+
+		written, err := f.copier.Sync(testFileMdc, regular, true, false, 0644, models.Normalization{})
+		require.NoError(t, err)
+		require.False(t, written)
+	})
+
+	t.Run("skips indexed destination with same content without reading files", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectIndexed(testFileMdc, index.Digest{Content: "hash1", Body: "body"})
+		f.expectIndexed(regular, index.Digest{Content: "hash2", Body: "body"})
+
+		f.fileOpsMock.EXPECT().
+			Stat(regular).
+			Return(regularInfo, nil).
+			Times(1)
+
+		written, err := f.copier.Sync(testFileMdc, regular, true, false, 0644, models.Normalization{})
+		require.NoError(t, err)
+		require.False(t, written)
+	})
+
+	t.Run("copies mdc file keeping destination header", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectNotIndexed(testFileMdc)
+		f.expectText(testFileMdc)
+		f.expectText(testDestMdc)
+		f.expectRead(testFileMdc, "---\nheader1\n---\ncontent")
+
+		f.expectRead(testDestMdc, "---\nheader2\n---\nold")
+
+		f.fileOpsMock.EXPECT().
+			WriteFile(testDestMdc, "---\nheader2\n---\ncontent\n", os.FileMode(0644)).
+			Return(nil).
+			Times(1)
+
+		f.indexMock.EXPECT().
+			Forget(testDestMdc).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Chmod(testDestMdc, os.FileMode(0644)).
+			Return(nil).
+			Times(1)
+
+		written, err := f.copier.Sync(testFileMdc, testDestMdc, true, false, 0644, models.Normalization{})
+		require.NoError(t, err)
+		require.True(t, written)
+	})
+
+	t.Run("copies non-mdc file verbatim", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		content := "line  \r\nend\r\n\r\n"
+		f.expectText("file.txt")
+		f.expectRead("file.txt", content)
+
+		f.fileOpsMock.EXPECT().
+			WriteFile("dest.txt", content, os.FileMode(0600)).
+			Return(nil).
+			Times(1)
+
+		f.indexMock.EXPECT().
+			Forget("dest.txt").
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Chmod("dest.txt", os.FileMode(0640)).
+			Return(nil).
+			Times(1)
+
+		written, err := f.copier.Sync("file.txt", "dest.txt", false, false, 0640, models.Normalization{})
+		require.NoError(t, err)
+		require.True(t, written)
+	})
+
+	t.Run("copies non-mdc file with line endings of explicit policy", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectText("file.txt")
+		f.expectRead("file.txt", "line  \r\nend\r\n\r\n")
+
+		f.fileOpsMock.EXPECT().
+			WriteFile("dest.txt", "line  \nend\n", os.FileMode(0600)).
+			Return(nil).
+			Times(1)
+
+		f.indexMock.EXPECT().
+			Forget("dest.txt").
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Chmod("dest.txt", os.FileMode(0640)).
+			Return(nil).
+			Times(1)
+
+		normalization := models.Normalization{LineEndings: models.LineEndingsLF, AllTextFiles: true}
+		written, err := f.copier.Sync("file.txt", "dest.txt", false, false, 0640, normalization)
+		require.NoError(t, err)
+		require.True(t, written)
+	})
+
+	t.Run("overwrites unreadable destination", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectNotIndexed(testFileMdc)
+		f.expectText(testFileMdc)
+		f.expectRead(testFileMdc, "---\nheader1\n---\ncontent")
+
+		f.expectReadError(testDestMdc, errors.New("permission denied"))
+
+		f.fileOpsMock.EXPECT().
+			WriteFile(testDestMdc, "---\nheader1\n---\ncontent\n", os.FileMode(0644)).
+			Return(nil).
+			Times(1)
+
+		f.indexMock.EXPECT().
+			Forget(testDestMdc).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Chmod(testDestMdc, os.FileMode(0644)).
+			Return(nil).
+			Times(1)
+
+		written, err := f.copier.Sync(testFileMdc, testDestMdc, true, false, 0644, models.Normalization{})
+		require.NoError(t, err)
+		require.True(t, written)
+	})
+
+	t.Run("error reading source file", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		expectedErr := errors.New("read error")
+		f.expectNotIndexed(testFileMdc)
+		f.expectReadError(testFileMdc, expectedErr)
+
+		_, err := f.copier.Sync(testFileMdc, testDestMdc, true, false, 0644, models.Normalization{})
+		require.ErrorIs(t, err, expectedErr)
+		require.Contains(t, err.Error(), "failed to read source file")
+	})
+}
+
+func TestCopier_Sync_Binary(t *testing.T) {
+	dir := t.TempDir()
+	image := filepath.Join(dir, "logo.png")
+	require.NoError(t, os.WriteFile(image, []byte("\x89PNG\x00"), 0600))
+	imageInfo, err := os.Stat(image)
+	require.NoError(t, err)
+
+	t.Run("skips binary destination with same bytes and mode", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectNotIndexed("src.png")
+		f.expectBinarySource("src.png")
+		f.expectBinary(image)
+		f.expectHash("src.png", "hash")
+		f.expectHash(image, "hash")
+
+		f.fileOpsMock.EXPECT().
+			Stat(image).
+			Return(imageInfo, nil).
+			Times(1)
+
+		written, err := f.copier.Sync("src.png", image, true, false, 0600, models.Normalization{})
+		require.NoError(t, err)
+		require.False(t, written)
+	})
+
+	t.Run("copies changed binary file verbatim", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectNotIndexed(testFileMdc)
+		f.expectBinarySource(testFileMdc)
+		f.expectBinary(testDestMdc)
+		f.expectHash(testFileMdc, "hash1")
+		f.expectHash(testDestMdc, "hash2")
+
+		f.fileOpsMock.EXPECT().
+			CopyFile(testFileMdc, testDestMdc).
+			Return(nil).
+			Times(1)
+
+		f.indexMock.EXPECT().
+			Forget(testDestMdc).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Chmod(testDestMdc, os.FileMode(0644)).
+			Return(nil).
+			Times(1)
+
+		written, err := f.copier.Sync(testFileMdc, testDestMdc, true, false, 0644, models.Normalization{})
+		require.NoError(t, err)
+		require.True(t, written)
+	})
+
+	t.Run("copies binary file over text destination without comparing", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectNotIndexed("src.png")
+		f.expectBinarySource("src.png")
+		f.expectText("dest.png")
+
+		f.fileOpsMock.EXPECT().
+			CopyFile("src.png", "dest.png").
+			Return(nil).
+			Times(1)
+
+		f.indexMock.EXPECT().
+			Forget("dest.png").
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Chmod("dest.png", os.FileMode(0644)).
+			Return(nil).
+			Times(1)
+
+		written, err := f.copier.Sync("src.png", "dest.png", true, false, 0644, models.Normalization{})
+		require.NoError(t, err)
+		require.True(t, written)
+	})
+
+	t.Run("warns about large text file copied as binary", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectBinary("large.md")
+		f.fileOpsMock.EXPECT().
+			IsLargeTextFile("large.md").
+			Return(true, nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintWarningf("%s is larger than %d MiB and is synced as binary, its line endings and header are kept as is", "large.md", 8).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			CopyFile("large.md", "dest.md").
+			Return(nil).
+			Times(1)
+
+		f.indexMock.EXPECT().
+			Forget("dest.md").
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Chmod("dest.md", os.FileMode(0644)).
+			Return(nil).
+			Times(1)
+
+		written, err := f.copier.Sync("large.md", "dest.md", false, false, 0644, models.Normalization{})
+		require.NoError(t, err)
+		require.True(t, written)
+	})
+
+	t.Run("error copying binary file", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		expectedErr := errors.New("copy error")
+		f.expectBinarySource("src.png")
+
+		f.fileOpsMock.EXPECT().
+			CopyFile("src.png", "dest.png").
+			Return(expectedErr).
+			Times(1)
+
+		_, err := f.copier.Sync("src.png", "dest.png", false, false, 0644, models.Normalization{})
+		require.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestCopier_Sync_Normalization(t *testing.T) {
+	dir := t.TempDir()
+	regular := filepath.Join(dir, "rule.mdc")
+	require.NoError(t, os.WriteFile(regular, []byte("content"), 0600))
+	require.NoError(t, os.Chmod(regular, 0644))
+	regularInfo, err := os.Stat(regular)
+	require.NoError(t, err)
+
+	t.Run("writes mdc file with crlf line endings", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectText(testFileMdc)
+		f.expectRead(testFileMdc, "---\nheader1\n---\ncontent\n\n")
+
+		f.fileOpsMock.EXPECT().
+			WriteFile(testDestMdc, "---\r\nheader1\r\n---\r\ncontent\r\n", os.FileMode(0644)).
+			Return(nil).
+			Times(1)
+
+		f.indexMock.EXPECT().
+			Forget(testDestMdc).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Chmod(testDestMdc, os.FileMode(0644)).
+			Return(nil).
+			Times(1)
+
+		normalization := models.Normalization{LineEndings: models.LineEndingsCRLF}
+		written, err := f.copier.Sync(testFileMdc, testDestMdc, false, false, 0644, normalization)
+		require.NoError(t, err)
+		require.True(t, written)
+	})
+
+	t.Run("preserve writes mdc file as is", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		content := "---\r\nheader1\r\n---\r\nline  \r\nbreak  \r\n\r\n"
+		f.expectText(testFileMdc)
+		f.expectRead(testFileMdc, content)
+
+		f.fileOpsMock.EXPECT().
+			WriteFile(testDestMdc, content, os.FileMode(0644)).
+			Return(nil).
+			Times(1)
+
+		f.indexMock.EXPECT().
+			Forget(testDestMdc).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Chmod(testDestMdc, os.FileMode(0644)).
+			Return(nil).
+			Times(1)
+
+		normalization := models.Normalization{LineEndings: models.LineEndingsPreserve}
+		written, err := f.copier.Sync(testFileMdc, testDestMdc, false, true, 0644, normalization)
+		require.NoError(t, err)
+		require.True(t, written)
+	})
+
+	t.Run("exact comparison skips destination with bytes of the copy", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectText(testFileMdc)
+		f.expectText(regular)
+		f.expectRead(testFileMdc, "---\nheader1\n---\ncontent\n")
+		f.expectRead(regular, "---\r\nheader2\r\n---\r\ncontent\r\n")
+
+		f.fileOpsMock.EXPECT().
+			Stat(regular).
+			Return(regularInfo, nil).
+			Times(1)
+
+		normalization := models.Normalization{LineEndings: models.LineEndingsCRLF, Compare: models.CompareExact}
+		written, err := f.copier.Sync(testFileMdc, regular, true, false, 0644, normalization)
+		require.NoError(t, err)
+		require.False(t, written)
+	})
+
+	t.Run("exact comparison rewrites destination differing in trailing whitespace", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.expectNotIndexed("file.txt")
+		f.expectText("file.txt")
+		f.expectText("dest.txt")
+		f.expectRead("file.txt", "line  \n")
+		f.expectRead("dest.txt", "line\n")
+
+		f.fileOpsMock.EXPECT().
+			WriteFile("dest.txt", "line  \n", os.FileMode(0600)).
+			Return(nil).
+			Times(1)
+
+		f.indexMock.EXPECT().
+			Forget("dest.txt").
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Chmod("dest.txt", os.FileMode(0644)).
+			Return(nil).
+			Times(1)
+
+		normalization := models.Normalization{Compare: models.CompareExact}
+		written, err := f.copier.Sync("file.txt", "dest.txt", true, false, 0644, normalization)
+		require.NoError(t, err)
+		require.True(t, written)
+	})
+}
+
+func TestCopier_Sync_FileMode(t *testing.T) {
+	t.Run("preserves mode and modification time of source file", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		srcPath := filepath.Join(t.TempDir(), "script.sh")
+		dstPath := "dest.sh"
+		require.NoError(t, os.WriteFile(srcPath, []byte("#!/bin/sh\n"), 0600))
+		require.NoError(t, os.Chmod(srcPath, 0755))
+		srcInfo, err := os.Stat(srcPath)
+		require.NoError(t, err)
+
+		f.expectBinarySource(srcPath)
+
+		f.fileOpsMock.EXPECT().
+			CopyFile(srcPath, dstPath).
+			Return(nil).
+			Times(1)
+
+		f.indexMock.EXPECT().
+			Forget(dstPath).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Stat(srcPath).
+			Return(srcInfo, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Chmod(dstPath, os.FileMode(0755)).
+			Return(nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Chtimes(dstPath, srcInfo.ModTime(), srcInfo.ModTime()).
+			Return(nil).
+			Times(1)
+
+		written, err := f.copier.Sync(srcPath, dstPath, false, false, models.FileModePreserve, models.Normalization{})
+		require.NoError(t, err)
+		require.True(t, written)
+	})
+
+	t.Run("error setting fixed mode", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		srcPath := "image.png"
+		dstPath := "dest.png"
+		expectedErr := errors.New("chmod error")
+
+		f.expectBinarySource(srcPath)
+
+		f.fileOpsMock.EXPECT().
+			CopyFile(srcPath, dstPath).
+			Return(nil).
+			Times(1)
+
+		f.indexMock.EXPECT().
+			Forget(dstPath).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Chmod(dstPath, os.FileMode(0640)).
+			Return(expectedErr).
+			Times(1)
+
+		_, err := f.copier.Sync(srcPath, dstPath, false, false, 0640, models.Normalization{})
+		require.ErrorIs(t, err, expectedErr)
+		require.Contains(t, err.Error(), "failed to set mode")
+	})
+}
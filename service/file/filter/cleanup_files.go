@@ -4,8 +4,9 @@ import (
 	"fmt"
 )
 
-// CleanupExtraFilesByPatterns removes files that exist in destination but not in source, considering patterns
-func (f *Filter) CleanupExtraFilesByPatterns(srcFiles []string, srcBase, dstBase string, patterns []string) error {
+// CleanupExtraFilesByPatterns removes files that exist in destination but not in source, considering patterns,
+// ignored files are never removed
+func (f *Filter) CleanupExtraFilesByPatterns(srcFiles []string, srcBase, dstBase string, patterns, ignorePatterns []string) error {
 	srcFilesMap := make(map[string]bool)
 	for _, srcFile := range srcFiles {
 		relativePath, err := f.pathUtils.GetRelativePath(srcFile, srcBase)
@@ -20,7 +21,7 @@ func (f *Filter) CleanupExtraFilesByPatterns(srcFiles []string, srcBase, dstBase
 		return fmt.Errorf("error walking destination directory: %w", err)
 	}
 
-	destFiles = f.filterFilesByPatterns(destFiles, dstBase, patterns)
+	destFiles = f.filterFilesByPatterns(destFiles, dstBase, patterns, ignorePatterns)
 
 	for _, destFile := range destFiles {
 		relativePath, err := f.pathUtils.GetRelativePath(destFile, dstBase)
@@ -30,10 +31,9 @@ func (f *Filter) CleanupExtraFilesByPatterns(srcFiles []string, srcBase, dstBase
 
 		if !srcFilesMap[relativePath] {
 			if err := f.fileOps.RemoveFile(destFile); err != nil {
-				f.output.PrintErrorf("Error deleting file %s: %v\n", relativePath, err)
-			} else {
-				f.output.PrintOperation("delete", relativePath)
+				return fmt.Errorf("failed to delete file %s: %w", relativePath, err)
 			}
+			f.output.PrintOperation("delete", relativePath)
 		}
 	}
 
@@ -13,14 +13,12 @@ const (
 )
 
 func TestFilter_GetFilePatterns(t *testing.T) {
-	t.Run("from flag", func(t *testing.T) {
+	t.Run("include patterns", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
-		flagValue := "*.txt,*.md"
-
-		result, err := f.filter.GetFilePatterns(flagValue)
+		result, err := f.filter.GetFilePatterns([]string{"*.txt", "*.md"}, nil)
 		require.NoError(t, err)
 
 		expected := []string{"*.txt", "*.md"}
@@ -34,9 +32,7 @@ func TestFilter_GetFilePatterns(t *testing.T) {
 		f, finish := setUp(t)
 		defer finish()
 
-		flagValue := ""
-
-		result, err := f.filter.GetFilePatterns(flagValue)
+		result, err := f.filter.GetFilePatterns(nil, nil)
 		require.NoError(t, err)
 
 		require.Empty(t, result)
@@ -47,9 +43,7 @@ func TestFilter_GetFilePatterns(t *testing.T) {
 		f, finish := setUp(t)
 		defer finish()
 
-		flagValue := " *.txt , *.md , "
-
-		result, err := f.filter.GetFilePatterns(flagValue)
+		result, err := f.filter.GetFilePatterns([]string{" *.txt ", "", " *.md"}, []string{" "})
 		require.NoError(t, err)
 
 		expected := []string{"*.txt", "*.md"}
@@ -57,75 +51,243 @@ func TestFilter_GetFilePatterns(t *testing.T) {
 			t.Fatalf("mismatch (-want +got):\n%s", diff)
 		}
 	})
+
+	t.Run("expands braces and marks exclude patterns", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		result, err := f.filter.GetFilePatterns([]string{"team/*.{md,mdc}"}, []string{"draft_*.{md,mdc}"})
+		require.NoError(t, err)
+
+		expected := []string{"team/*.md", "team/*.mdc", "!draft_*.md", "!draft_*.mdc"}
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("does not mark exclude patterns twice", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		result, err := f.filter.GetFilePatterns(nil, []string{"!*.tmp", " !draft_*", "*.bak"})
+		require.NoError(t, err)
+
+		expected := []string{"!*.tmp", "!draft_*", "!*.bak"}
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
 }
 
-func TestFilter_FindFilesByPatterns(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
+func TestFilter_GetFilePatterns_Invalid(t *testing.T) {
+	t.Run("malformed pattern", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		result, err := f.filter.GetFilePatterns([]string{"*.mdc", "team/[a"}, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "team/[a")
+		require.Nil(t, result)
+	})
+
+	t.Run("malformed regular expression", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		result, err := f.filter.GetFilePatterns(nil, []string{"re:(draft"})
+		require.Error(t, err)
+		require.Nil(t, result)
+	})
+
+	t.Run("regular expression is not brace expanded", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		result, err := f.filter.GetFilePatterns([]string{`re:^v[0-9]{1,2}/.*\.mdc$`}, nil)
+		require.NoError(t, err)
+
+		expected := []string{`re:^v[0-9]{1,2}/.*\.mdc$`}
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestFilter_UnmatchedPatterns(t *testing.T) {
+	t.Run("returns patterns matching none of paths", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		result, err := f.filter.UnmatchedPatterns([]string{"team/**", "local.mdc", "tema/**", "!drafts/"}, []string{"team/rule.mdc", "local.mdc"})
+		require.NoError(t, err)
+
+		expected := []string{"tema/**", "!drafts/"}
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		result, err := f.filter.UnmatchedPatterns([]string{"[abc"}, []string{"a.mdc"})
+		require.Error(t, err)
+		require.Nil(t, result)
+	})
+}
+
+func TestFilter_FilterFilesByPatterns(t *testing.T) {
+	t.Run("selects files by patterns", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
-		dir := testDir
-		patterns := []string{"*.txt"}
 		allFiles := []string{"/test/file1.txt", "/test/file2.txt", "/test/file3.md"}
 
+		result := f.filter.FilterFilesByPatterns(allFiles, testDir, []string{"*.txt"}, nil)
+
+		expected := []string{"/test/file1.txt", "/test/file2.txt"}
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("skips ignored files", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		allFiles := []string{"/test/file.mdc", "/test/scripts/lint.sh", "/test/.cursyncignore"}
+
+		result := f.filter.FilterFilesByPatterns(allFiles, testDir, nil, []string{"scripts/", "/.cursyncignore"})
+
+		if diff := cmp.Diff([]string{"/test/file.mdc"}, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestFilter_LoadIgnorePatterns(t *testing.T) {
+	t.Run("reads ignore files of both directories", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
 		f.fileOpsMock.EXPECT().
-			FindAllFiles(dir).
-			Return(allFiles, nil).
+			FileExists("/src/.cursyncignore").
+			Return(true, nil).
 			Times(1)
 
-		result, err := f.filter.FindFilesByPatterns(dir, patterns)
+		f.fileOpsMock.EXPECT().
+			ReadFileNormalized("/src/.cursyncignore").
+			Return("# repository files\nREADME.md\n\nscripts/\n", nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FileExists("/dst/.cursyncignore").
+			Return(true, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			ReadFileNormalized("/dst/.cursyncignore").
+			Return("local_*.mdc\n", nil).
+			Times(1)
+
+		result, err := f.filter.LoadIgnorePatterns("/src", "/dst")
 		require.NoError(t, err)
 
-		expectedCount := 2
-		if diff := cmp.Diff(expectedCount, len(result)); diff != "" {
+		expected := []string{"README.md", "scripts/", "local_*.mdc", "/.cursyncignore"}
+		if diff := cmp.Diff(expected, result); diff != "" {
 			t.Fatalf("mismatch (-want +got):\n%s", diff)
 		}
 	})
 
-	t.Run("error finding files", func(t *testing.T) {
+	t.Run("no ignore files", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
-		dir := testDir
-		patterns := []string{"*.txt"}
-		expectedErr := errors.New("find error")
+		f.fileOpsMock.EXPECT().
+			FileExists("/src/.cursyncignore").
+			Return(false, nil).
+			Times(1)
+
+		result, err := f.filter.LoadIgnorePatterns("/src")
+		require.NoError(t, err)
+		require.Nil(t, result)
+	})
+
+	t.Run("error reading ignore file", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		expectedErr := errors.New("read error")
 
 		f.fileOpsMock.EXPECT().
-			FindAllFiles(dir).
-			Return(nil, expectedErr).
+			FileExists("/src/.cursyncignore").
+			Return(true, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			ReadFileNormalized("/src/.cursyncignore").
+			Return("", expectedErr).
 			Times(1)
 
-		result, err := f.filter.FindFilesByPatterns(dir, patterns)
+		result, err := f.filter.LoadIgnorePatterns("/src")
 		require.ErrorIs(t, err, expectedErr)
-		require.Empty(t, result)
+		require.Nil(t, result)
 	})
 
-	t.Run("empty patterns returns all files", func(t *testing.T) {
+	t.Run("reports malformed line", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
-		dir := testDir
-		patterns := []string{}
-		allFiles := []string{"/test/file1.txt", "/test/file2.md"}
-
 		f.fileOpsMock.EXPECT().
-			FindAllFiles(dir).
-			Return(allFiles, nil).
+			FileExists("/src/.cursyncignore").
+			Return(true, nil).
 			Times(1)
 
-		result, err := f.filter.FindFilesByPatterns(dir, patterns)
-		require.NoError(t, err)
+		f.fileOpsMock.EXPECT().
+			ReadFileNormalized("/src/.cursyncignore").
+			Return("README.md\n[abc\n", nil).
+			Times(1)
 
-		if diff := cmp.Diff(allFiles, result); diff != "" {
-			t.Fatalf("mismatch (-want +got):\n%s", diff)
-		}
+		result, err := f.filter.LoadIgnorePatterns("/src")
+		require.ErrorContains(t, err, "/src/.cursyncignore: line 2")
+		require.Nil(t, result)
 	})
 }
 
 func TestFilter_CleanupExtraFilesByPatterns(t *testing.T) {
+	t.Run("keeps ignored files", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles("/dst").
+			Return([]string{"/dst/README.md", "/dst/.cursyncignore", "/dst/old.mdc"}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			RemoveFile("/dst/old.mdc").
+			Return(nil).
+			Times(1)
+
+		err := f.filter.CleanupExtraFilesByPatterns(nil, "/src", "/dst", nil, []string{"README.md", "/.cursyncignore"})
+		require.NoError(t, err)
+	})
+
 	t.Run("error walking destination directory", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
@@ -142,8 +304,28 @@ func TestFilter_CleanupExtraFilesByPatterns(t *testing.T) {
 			Return(nil, expectedErr).
 			Times(1)
 
-		err := f.filter.CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns)
+		err := f.filter.CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns, nil)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "error walking destination directory")
 	})
+	t.Run("error deleting file", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles("/dst").
+			Return([]string{"/dst/old.mdc"}, nil).
+			Times(1)
+
+		expectedErr := errors.New("permission denied")
+		f.fileOpsMock.EXPECT().
+			RemoveFile("/dst/old.mdc").
+			Return(expectedErr).
+			Times(1)
+
+		err := f.filter.CleanupExtraFilesByPatterns(nil, "/src", "/dst", []string{"*.mdc"}, nil)
+		require.ErrorIs(t, err, expectedErr)
+		require.Contains(t, err.Error(), "failed to delete file old.mdc")
+	})
 }
@@ -1,16 +1,12 @@
 package filter
 
-// FindFilesByPatterns finds all files matching patterns in directory
-func (f *Filter) FindFilesByPatterns(dir string, patterns []string) ([]string, error) {
-	allFiles, err := f.fileOps.FindAllFiles(dir)
-	if err != nil {
-		return nil, err
-	}
-
-	return f.filterFilesByPatterns(allFiles, dir, patterns), nil
+// FilterFilesByPatterns filters files relative to base directory by patterns skipping ignored files
+func (f *Filter) FilterFilesByPatterns(files []string, baseDir string, patterns, ignorePatterns []string) []string {
+	return f.filterFilesByPatterns(files, baseDir, patterns, ignorePatterns)
 }
 
-// filterFilesByPatterns filters files based on provided patterns
-func (f *Filter) filterFilesByPatterns(files []string, baseDir string, patterns []string) []string {
-	return f.patternFilter.FilterFilesByPatterns(files, baseDir, patterns)
+// filterFilesByPatterns filters files based on provided patterns and removes ignored files
+func (f *Filter) filterFilesByPatterns(files []string, baseDir string, patterns, ignorePatterns []string) []string {
+	files = f.patternFilter.FilterFilesByPatterns(files, baseDir, patterns)
+	return f.patternFilter.RemoveIgnoredFiles(files, baseDir, ignorePatterns)
 }
@@ -1,15 +1,40 @@
 package filter
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/pattern_matcher"
 	"github.com/yanodintsovmercuryo/cursync/pkg/string_utils"
 )
 
-// GetFilePatterns returns file patterns from flag value
-func (f *Filter) GetFilePatterns(flagValue string) ([]string, error) {
-	if flagValue == "" {
-		return []string{}, nil
+// GetFilePatterns returns include patterns followed by exclude patterns marked with "!",
+// exclude patterns already marked are taken as is, brace alternatives are expanded,
+// empty patterns are skipped and malformed patterns are reported
+func (f *Filter) GetFilePatterns(include, exclude []string) ([]string, error) {
+	patterns := []string{}
+	for _, pattern := range include {
+		patterns = append(patterns, expandPattern(pattern)...)
+	}
+	for _, pattern := range exclude {
+		pattern = strings.TrimPrefix(strings.TrimSpace(pattern), pattern_matcher.ExcludePrefix)
+		for _, expanded := range expandPattern(pattern) {
+			patterns = append(patterns, pattern_matcher.ExcludePrefix+expanded)
+		}
 	}
 
-	patterns := string_utils.SplitTrimFilter(flagValue, ",")
+	patterns = string_utils.RemoveDuplicates(patterns)
+	if _, err := pattern_matcher.NewMatcher(patterns); err != nil {
+		return nil, fmt.Errorf("invalid file pattern: %w", err)
+	}
 	return patterns, nil
 }
+
+// expandPattern trims pattern and expands its brace alternatives
+func expandPattern(pattern string) []string {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil
+	}
+	return pattern_matcher.ExpandBraces(pattern)
+}
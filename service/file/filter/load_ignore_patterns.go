@@ -0,0 +1,41 @@
+package filter
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/pattern_matcher"
+)
+
+// LoadIgnorePatterns reads ignore files at the root of given directories, returns nil if there are none,
+// malformed patterns are reported. Ignore files never take part in sync, so their own name is ignored last
+func (f *Filter) LoadIgnorePatterns(dirs ...string) ([]string, error) {
+	var patterns []string
+	found := false
+	for _, dir := range dirs {
+		ignoreFilePath := filepath.Join(dir, pattern_matcher.IgnoreFileName)
+		exists, err := f.fileOps.FileExists(ignoreFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("error checking ignore file %s: %w", ignoreFilePath, err)
+		}
+		if !exists {
+			continue
+		}
+
+		content, err := f.fileOps.ReadFileNormalized(ignoreFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading ignore file %s: %w", ignoreFilePath, err)
+		}
+		filePatterns, err := pattern_matcher.ParseIgnoreFile(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore file %s: %w", ignoreFilePath, err)
+		}
+		patterns = append(patterns, filePatterns...)
+		found = true
+	}
+
+	if !found {
+		return nil, nil
+	}
+	return append(patterns, "/"+pattern_matcher.IgnoreFileName), nil
+}
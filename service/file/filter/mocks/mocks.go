@@ -39,6 +39,21 @@ func (m *MockfileOps) EXPECT() *MockfileOpsMockRecorder {
 	return m.recorder
 }
 
+// FileExists mocks base method.
+func (m *MockfileOps) FileExists(filePath string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FileExists", filePath)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FileExists indicates an expected call of FileExists.
+func (mr *MockfileOpsMockRecorder) FileExists(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FileExists", reflect.TypeOf((*MockfileOps)(nil).FileExists), filePath)
+}
+
 // FindAllFiles mocks base method.
 func (m *MockfileOps) FindAllFiles(dir string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -54,6 +69,21 @@ func (mr *MockfileOpsMockRecorder) FindAllFiles(dir any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllFiles", reflect.TypeOf((*MockfileOps)(nil).FindAllFiles), dir)
 }
 
+// ReadFileNormalized mocks base method.
+func (m *MockfileOps) ReadFileNormalized(filePath string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFileNormalized", filePath)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFileNormalized indicates an expected call of ReadFileNormalized.
+func (mr *MockfileOpsMockRecorder) ReadFileNormalized(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFileNormalized", reflect.TypeOf((*MockfileOps)(nil).ReadFileNormalized), filePath)
+}
+
 // RemoveFile mocks base method.
 func (m *MockfileOps) RemoveFile(filePath string) error {
 	m.ctrl.T.Helper()
@@ -197,3 +227,17 @@ func (mr *MockpatternFilterMockRecorder) FilterFilesByPatterns(files, baseDir, p
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterFilesByPatterns", reflect.TypeOf((*MockpatternFilter)(nil).FilterFilesByPatterns), files, baseDir, patterns)
 }
+
+// RemoveIgnoredFiles mocks base method.
+func (m *MockpatternFilter) RemoveIgnoredFiles(files []string, baseDir string, ignorePatterns []string) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveIgnoredFiles", files, baseDir, ignorePatterns)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// RemoveIgnoredFiles indicates an expected call of RemoveIgnoredFiles.
+func (mr *MockpatternFilterMockRecorder) RemoveIgnoredFiles(files, baseDir, ignorePatterns any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveIgnoredFiles", reflect.TypeOf((*MockpatternFilter)(nil).RemoveIgnoredFiles), files, baseDir, ignorePatterns)
+}
@@ -9,6 +9,8 @@ import (
 
 type fileOps interface {
 	FindAllFiles(dir string) ([]string, error)
+	FileExists(filePath string) (bool, error)
+	ReadFileNormalized(filePath string) (string, error)
 	RemoveFile(filePath string) error
 }
 
@@ -23,6 +25,7 @@ type pathUtils interface {
 
 type patternFilter interface {
 	FilterFilesByPatterns(files []string, baseDir string, patterns []string) []string
+	RemoveIgnoredFiles(files []string, baseDir string, ignorePatterns []string) []string
 }
 
 // Filter handles file filtering
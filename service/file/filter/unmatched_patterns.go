@@ -0,0 +1,16 @@
+package filter
+
+import (
+	"fmt"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/pattern_matcher"
+)
+
+// UnmatchedPatterns returns patterns that match none of slash-separated relative paths
+func (f *Filter) UnmatchedPatterns(patterns []string, relativePaths []string) ([]string, error) {
+	matcher, err := pattern_matcher.NewMatcher(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file pattern: %w", err)
+	}
+	return matcher.Unmatched(relativePaths), nil
+}
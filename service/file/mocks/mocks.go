@@ -12,7 +12,10 @@ package mocks
 import (
 	os "os"
 	reflect "reflect"
+	time "time"
 
+	models "github.com/yanodintsovmercuryo/cursync/models"
+	index "github.com/yanodintsovmercuryo/cursync/pkg/index"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,6 +43,34 @@ func (m *MockfileOps) EXPECT() *MockfileOpsMockRecorder {
 	return m.recorder
 }
 
+// Chmod mocks base method.
+func (m *MockfileOps) Chmod(filePath string, mode os.FileMode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Chmod", filePath, mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Chmod indicates an expected call of Chmod.
+func (mr *MockfileOpsMockRecorder) Chmod(filePath, mode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Chmod", reflect.TypeOf((*MockfileOps)(nil).Chmod), filePath, mode)
+}
+
+// Chtimes mocks base method.
+func (m *MockfileOps) Chtimes(filePath string, atime, mtime time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Chtimes", filePath, atime, mtime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Chtimes indicates an expected call of Chtimes.
+func (mr *MockfileOpsMockRecorder) Chtimes(filePath, atime, mtime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Chtimes", reflect.TypeOf((*MockfileOps)(nil).Chtimes), filePath, atime, mtime)
+}
+
 // CopyFile mocks base method.
 func (m *MockfileOps) CopyFile(srcPath, dstPath string) error {
 	m.ctrl.T.Helper()
@@ -99,6 +130,51 @@ func (mr *MockfileOpsMockRecorder) GetCurrentDir() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentDir", reflect.TypeOf((*MockfileOps)(nil).GetCurrentDir))
 }
 
+// HashFile mocks base method.
+func (m *MockfileOps) HashFile(filePath string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashFile", filePath)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HashFile indicates an expected call of HashFile.
+func (mr *MockfileOpsMockRecorder) HashFile(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashFile", reflect.TypeOf((*MockfileOps)(nil).HashFile), filePath)
+}
+
+// IsBinaryFile mocks base method.
+func (m *MockfileOps) IsBinaryFile(filePath string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBinaryFile", filePath)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBinaryFile indicates an expected call of IsBinaryFile.
+func (mr *MockfileOpsMockRecorder) IsBinaryFile(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBinaryFile", reflect.TypeOf((*MockfileOps)(nil).IsBinaryFile), filePath)
+}
+
+// IsLargeTextFile mocks base method.
+func (m *MockfileOps) IsLargeTextFile(filePath string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsLargeTextFile", filePath)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsLargeTextFile indicates an expected call of IsLargeTextFile.
+func (mr *MockfileOpsMockRecorder) IsLargeTextFile(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsLargeTextFile", reflect.TypeOf((*MockfileOps)(nil).IsLargeTextFile), filePath)
+}
+
 // MkdirAll mocks base method.
 func (m *MockfileOps) MkdirAll(path string, perm os.FileMode) error {
 	m.ctrl.T.Helper()
@@ -113,6 +189,21 @@ func (mr *MockfileOpsMockRecorder) MkdirAll(path, perm any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MkdirAll", reflect.TypeOf((*MockfileOps)(nil).MkdirAll), path, perm)
 }
 
+// ReadFile mocks base method.
+func (m *MockfileOps) ReadFile(filePath string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFile", filePath)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFile indicates an expected call of ReadFile.
+func (mr *MockfileOpsMockRecorder) ReadFile(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFile", reflect.TypeOf((*MockfileOps)(nil).ReadFile), filePath)
+}
+
 // ReadFileNormalized mocks base method.
 func (m *MockfileOps) ReadFileNormalized(filePath string) (string, error) {
 	m.ctrl.T.Helper()
@@ -171,43 +262,79 @@ func (mr *MockfileOpsMockRecorder) WriteFile(filePath, content, perm any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteFile", reflect.TypeOf((*MockfileOps)(nil).WriteFile), filePath, content, perm)
 }
 
-// MockcomparatorService is a mock of comparatorService interface.
-type MockcomparatorService struct {
+// MockcontentIndex is a mock of contentIndex interface.
+type MockcontentIndex struct {
 	ctrl     *gomock.Controller
-	recorder *MockcomparatorServiceMockRecorder
+	recorder *MockcontentIndexMockRecorder
 	isgomock struct{}
 }
 
-// MockcomparatorServiceMockRecorder is the mock recorder for MockcomparatorService.
-type MockcomparatorServiceMockRecorder struct {
-	mock *MockcomparatorService
+// MockcontentIndexMockRecorder is the mock recorder for MockcontentIndex.
+type MockcontentIndexMockRecorder struct {
+	mock *MockcontentIndex
 }
 
-// NewMockcomparatorService creates a new mock instance.
-func NewMockcomparatorService(ctrl *gomock.Controller) *MockcomparatorService {
-	mock := &MockcomparatorService{ctrl: ctrl}
-	mock.recorder = &MockcomparatorServiceMockRecorder{mock}
+// NewMockcontentIndex creates a new mock instance.
+func NewMockcontentIndex(ctrl *gomock.Controller) *MockcontentIndex {
+	mock := &MockcontentIndex{ctrl: ctrl}
+	mock.recorder = &MockcontentIndexMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockcomparatorService) EXPECT() *MockcomparatorServiceMockRecorder {
+func (m *MockcontentIndex) EXPECT() *MockcontentIndexMockRecorder {
 	return m.recorder
 }
 
-// AreEqual mocks base method.
-func (m *MockcomparatorService) AreEqual(file1, file2 string, overwriteHeaders bool) (bool, error) {
+// Forget mocks base method.
+func (m *MockcontentIndex) Forget(filePath string) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AreEqual", file1, file2, overwriteHeaders)
-	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(error)
+	m.ctrl.Call(m, "Forget", filePath)
+}
+
+// Forget indicates an expected call of Forget.
+func (mr *MockcontentIndexMockRecorder) Forget(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Forget", reflect.TypeOf((*MockcontentIndex)(nil).Forget), filePath)
+}
+
+// Lookup mocks base method.
+func (m *MockcontentIndex) Lookup(filePath string) (index.Digest, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lookup", filePath)
+	ret0, _ := ret[0].(index.Digest)
+	ret1, _ := ret[1].(bool)
 	return ret0, ret1
 }
 
-// AreEqual indicates an expected call of AreEqual.
-func (mr *MockcomparatorServiceMockRecorder) AreEqual(file1, file2, overwriteHeaders any) *gomock.Call {
+// Lookup indicates an expected call of Lookup.
+func (mr *MockcontentIndexMockRecorder) Lookup(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lookup", reflect.TypeOf((*MockcontentIndex)(nil).Lookup), filePath)
+}
+
+// Record mocks base method.
+func (m *MockcontentIndex) Record(filePath string, before os.FileInfo, data []byte) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", filePath, before, data)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockcontentIndexMockRecorder) Record(filePath, before, data any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AreEqual", reflect.TypeOf((*MockcomparatorService)(nil).AreEqual), file1, file2, overwriteHeaders)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockcontentIndex)(nil).Record), filePath, before, data)
+}
+
+// RecordBinary mocks base method.
+func (m *MockcontentIndex) RecordBinary(filePath string, before os.FileInfo, hash string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordBinary", filePath, before, hash)
+}
+
+// RecordBinary indicates an expected call of RecordBinary.
+func (mr *MockcontentIndexMockRecorder) RecordBinary(filePath, before, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordBinary", reflect.TypeOf((*MockcontentIndex)(nil).RecordBinary), filePath, before, hash)
 }
 
 // MockcopierService is a mock of copierService interface.
@@ -234,18 +361,34 @@ func (m *MockcopierService) EXPECT() *MockcopierServiceMockRecorder {
 	return m.recorder
 }
 
-// Copy mocks base method.
-func (m *MockcopierService) Copy(srcPath, dstPath string, overwriteHeaders bool) error {
+// ModeMatches mocks base method.
+func (m *MockcopierService) ModeMatches(srcPath, dstPath string, mode os.FileMode) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Copy", srcPath, dstPath, overwriteHeaders)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ModeMatches", srcPath, dstPath, mode)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Copy indicates an expected call of Copy.
-func (mr *MockcopierServiceMockRecorder) Copy(srcPath, dstPath, overwriteHeaders any) *gomock.Call {
+// ModeMatches indicates an expected call of ModeMatches.
+func (mr *MockcopierServiceMockRecorder) ModeMatches(srcPath, dstPath, mode any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Copy", reflect.TypeOf((*MockcopierService)(nil).Copy), srcPath, dstPath, overwriteHeaders)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModeMatches", reflect.TypeOf((*MockcopierService)(nil).ModeMatches), srcPath, dstPath, mode)
+}
+
+// Sync mocks base method.
+func (m *MockcopierService) Sync(srcPath, dstPath string, dstExists, overwriteHeaders bool, mode os.FileMode, normalization models.Normalization) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", srcPath, dstPath, dstExists, overwriteHeaders, mode, normalization)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockcopierServiceMockRecorder) Sync(srcPath, dstPath, dstExists, overwriteHeaders, mode, normalization any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockcopierService)(nil).Sync), srcPath, dstPath, dstExists, overwriteHeaders, mode, normalization)
 }
 
 // MockfilterService is a mock of filterService interface.
@@ -273,45 +416,78 @@ func (m *MockfilterService) EXPECT() *MockfilterServiceMockRecorder {
 }
 
 // CleanupExtraFilesByPatterns mocks base method.
-func (m *MockfilterService) CleanupExtraFilesByPatterns(srcFiles []string, srcBase, dstBase string, patterns []string) error {
+func (m *MockfilterService) CleanupExtraFilesByPatterns(srcFiles []string, srcBase, dstBase string, patterns, ignorePatterns []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CleanupExtraFilesByPatterns", srcFiles, srcBase, dstBase, patterns)
+	ret := m.ctrl.Call(m, "CleanupExtraFilesByPatterns", srcFiles, srcBase, dstBase, patterns, ignorePatterns)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CleanupExtraFilesByPatterns indicates an expected call of CleanupExtraFilesByPatterns.
-func (mr *MockfilterServiceMockRecorder) CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns any) *gomock.Call {
+func (mr *MockfilterServiceMockRecorder) CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns, ignorePatterns any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanupExtraFilesByPatterns", reflect.TypeOf((*MockfilterService)(nil).CleanupExtraFilesByPatterns), srcFiles, srcBase, dstBase, patterns)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanupExtraFilesByPatterns", reflect.TypeOf((*MockfilterService)(nil).CleanupExtraFilesByPatterns), srcFiles, srcBase, dstBase, patterns, ignorePatterns)
 }
 
-// FindFilesByPatterns mocks base method.
-func (m *MockfilterService) FindFilesByPatterns(dir string, patterns []string) ([]string, error) {
+// FilterFilesByPatterns mocks base method.
+func (m *MockfilterService) FilterFilesByPatterns(files []string, baseDir string, patterns, ignorePatterns []string) []string {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindFilesByPatterns", dir, patterns)
+	ret := m.ctrl.Call(m, "FilterFilesByPatterns", files, baseDir, patterns, ignorePatterns)
 	ret0, _ := ret[0].([]string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	return ret0
 }
 
-// FindFilesByPatterns indicates an expected call of FindFilesByPatterns.
-func (mr *MockfilterServiceMockRecorder) FindFilesByPatterns(dir, patterns any) *gomock.Call {
+// FilterFilesByPatterns indicates an expected call of FilterFilesByPatterns.
+func (mr *MockfilterServiceMockRecorder) FilterFilesByPatterns(files, baseDir, patterns, ignorePatterns any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFilesByPatterns", reflect.TypeOf((*MockfilterService)(nil).FindFilesByPatterns), dir, patterns)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterFilesByPatterns", reflect.TypeOf((*MockfilterService)(nil).FilterFilesByPatterns), files, baseDir, patterns, ignorePatterns)
 }
 
 // GetFilePatterns mocks base method.
-func (m *MockfilterService) GetFilePatterns(flagValue string) ([]string, error) {
+func (m *MockfilterService) GetFilePatterns(include, exclude []string) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFilePatterns", flagValue)
+	ret := m.ctrl.Call(m, "GetFilePatterns", include, exclude)
 	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFilePatterns indicates an expected call of GetFilePatterns.
-func (mr *MockfilterServiceMockRecorder) GetFilePatterns(flagValue any) *gomock.Call {
+func (mr *MockfilterServiceMockRecorder) GetFilePatterns(include, exclude any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilePatterns", reflect.TypeOf((*MockfilterService)(nil).GetFilePatterns), include, exclude)
+}
+
+// LoadIgnorePatterns mocks base method.
+func (m *MockfilterService) LoadIgnorePatterns(dirs ...string) ([]string, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range dirs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "LoadIgnorePatterns", varargs...)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadIgnorePatterns indicates an expected call of LoadIgnorePatterns.
+func (mr *MockfilterServiceMockRecorder) LoadIgnorePatterns(dirs ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadIgnorePatterns", reflect.TypeOf((*MockfilterService)(nil).LoadIgnorePatterns), dirs...)
+}
+
+// UnmatchedPatterns mocks base method.
+func (m *MockfilterService) UnmatchedPatterns(patterns, relativePaths []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmatchedPatterns", patterns, relativePaths)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnmatchedPatterns indicates an expected call of UnmatchedPatterns.
+func (mr *MockfilterServiceMockRecorder) UnmatchedPatterns(patterns, relativePaths any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilePatterns", reflect.TypeOf((*MockfilterService)(nil).GetFilePatterns), flagValue)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmatchedPatterns", reflect.TypeOf((*MockfilterService)(nil).UnmatchedPatterns), patterns, relativePaths)
 }
@@ -6,31 +6,46 @@ import (
 	"github.com/yanodintsovmercuryo/cursync/pkg/pattern_matcher"
 )
 
-// FilterFilesByPatterns filters files based on provided patterns
+// FilterFilesByPatterns filters files based on gitignore-style patterns, "!"-prefixed patterns exclude files
+// and the last matching pattern wins
 func (p *PatternFilterService) FilterFilesByPatterns(files []string, baseDir string, patterns []string) []string {
 	if len(patterns) == 0 {
 		return files
 	}
 
+	return p.filterFiles(files, baseDir, pattern_matcher.NewLenientMatcher(patterns), true)
+}
+
+// RemoveIgnoredFiles removes files matching ignore patterns
+func (p *PatternFilterService) RemoveIgnoredFiles(files []string, baseDir string, ignorePatterns []string) []string {
+	if len(ignorePatterns) == 0 {
+		return files
+	}
+
+	return p.filterFiles(files, baseDir, pattern_matcher.NewIgnoreMatcher(ignorePatterns), false)
+}
+
+// filterFiles keeps files whose relative path match result equals keepMatching
+func (p *PatternFilterService) filterFiles(files []string, baseDir string, matcher *pattern_matcher.Matcher, keepMatching bool) []string {
 	var filtered []string
 	for _, file := range files {
-		var relativePath string
-
-		if baseDir != "" {
-			rel, err := p.pathUtils.GetRelativePath(file, baseDir)
-			if err != nil {
-				relativePath = filepath.Base(file)
-			} else {
-				relativePath = rel
-			}
-		} else {
-			relativePath = filepath.Base(file)
-		}
-
-		if pattern_matcher.MatchesAnyPattern(relativePath, patterns) {
+		if matcher.Matches(p.relativePath(file, baseDir)) == keepMatching {
 			filtered = append(filtered, file)
 		}
 	}
 
 	return filtered
 }
+
+// relativePath returns path of file relative to base directory or its base name
+func (p *PatternFilterService) relativePath(file, baseDir string) string {
+	if baseDir == "" {
+		return filepath.Base(file)
+	}
+
+	rel, err := p.pathUtils.GetRelativePath(file, baseDir)
+	if err != nil {
+		return filepath.Base(file)
+	}
+	return rel
+}
@@ -74,3 +74,33 @@ func TestPatternFilterService_FilterFilesByPatterns(t *testing.T) {
 		}
 	})
 }
+
+func TestPatternFilterService_RemoveIgnoredFiles(t *testing.T) {
+	t.Run("removes files matching ignore patterns", func(t *testing.T) {
+		t.Parallel()
+		patternFilter, finish := setUpWithRealPathUtils(t)
+		defer finish()
+
+		files := []string{"/test/rule.mdc", "/test/README.md", "/test/.cursyncignore"}
+		ignorePatterns := []string{"README.md", "/.cursyncignore"}
+		expected := []string{"/test/rule.mdc"}
+
+		result := patternFilter.RemoveIgnoredFiles(files, "/test", ignorePatterns)
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("empty ignore patterns returns all files", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		files := []string{"/test/rule.mdc"}
+
+		result := f.patternFilter.RemoveIgnoredFiles(files, "/test", nil)
+		if diff := cmp.Diff(files, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
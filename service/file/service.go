@@ -3,16 +3,19 @@ package file
 
 import (
 	"os"
+	"time"
 
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/index"
 	"github.com/yanodintsovmercuryo/cursync/pkg/output"
 	"github.com/yanodintsovmercuryo/cursync/pkg/path"
-	"github.com/yanodintsovmercuryo/cursync/service/file/comparator"
 	"github.com/yanodintsovmercuryo/cursync/service/file/copier"
 	"github.com/yanodintsovmercuryo/cursync/service/file/filter"
 )
 
 type fileOps interface {
 	FindAllFiles(dir string) ([]string, error)
+	ReadFile(filePath string) ([]byte, error)
 	ReadFileNormalized(filePath string) (string, error)
 	WriteFile(filePath, content string, perm os.FileMode) error
 	FileExists(filePath string) (bool, error)
@@ -21,72 +24,91 @@ type fileOps interface {
 	MkdirAll(path string, perm os.FileMode) error
 	GetCurrentDir() (string, error)
 	Stat(filePath string) (os.FileInfo, error)
+	Chmod(filePath string, mode os.FileMode) error
+	Chtimes(filePath string, atime, mtime time.Time) error
+	IsBinaryFile(filePath string) (bool, error)
+	IsLargeTextFile(filePath string) (bool, error)
+	HashFile(filePath string) (string, error)
 }
 
-type comparatorService interface {
-	AreEqual(file1, file2 string, overwriteHeaders bool) (bool, error)
+type contentIndex interface {
+	Lookup(filePath string) (index.Digest, bool)
+	Record(filePath string, before os.FileInfo, data []byte)
+	RecordBinary(filePath string, before os.FileInfo, hash string)
+	Forget(filePath string)
 }
 
 type copierService interface {
-	Copy(srcPath, dstPath string, overwriteHeaders bool) error
+	Sync(srcPath, dstPath string, dstExists, overwriteHeaders bool, mode os.FileMode, normalization models.Normalization) (bool, error)
+	ModeMatches(srcPath, dstPath string, mode os.FileMode) (bool, error)
 }
 
 type filterService interface {
-	GetFilePatterns(flagValue string) ([]string, error)
-	FindFilesByPatterns(dir string, patterns []string) ([]string, error)
-	CleanupExtraFilesByPatterns(srcFiles []string, srcBase, dstBase string, patterns []string) error
+	GetFilePatterns(include, exclude []string) ([]string, error)
+	UnmatchedPatterns(patterns []string, relativePaths []string) ([]string, error)
+	LoadIgnorePatterns(dirs ...string) ([]string, error)
+	FilterFilesByPatterns(files []string, baseDir string, patterns, ignorePatterns []string) []string
+	CleanupExtraFilesByPatterns(srcFiles []string, srcBase, dstBase string, patterns, ignorePatterns []string) error
 }
 
 // FileService is a facade for file operations
 type FileService struct {
-	comparator comparatorService
-	copier     copierService
-	filter     filterService
+	copier copierService
+	filter filterService
 }
 
 // NewFileService creates a new FileService
-func NewFileService(output *output.Output, fileOps fileOps, pathUtils *path.PathUtils) *FileService {
-	comparatorImpl := comparator.NewComparator(fileOps)
-	copierImpl := copier.NewCopier(fileOps)
+func NewFileService(output *output.Output, fileOps fileOps, pathUtils *path.PathUtils, index contentIndex) *FileService {
+	copierImpl := copier.NewCopier(output, fileOps, index)
 	filterImpl := filter.NewFilter(output, fileOps, pathUtils)
 
 	return &FileService{
-		comparator: comparatorImpl,
-		copier:     copierImpl,
-		filter:     filterImpl,
+		copier: copierImpl,
+		filter: filterImpl,
 	}
 }
 
 // NewFileServiceWithMocks creates a new FileService with provided mocks for testing
-func NewFileServiceWithMocks(comparator comparatorService, copier copierService, filter filterService) *FileService {
+func NewFileServiceWithMocks(copier copierService, filter filterService) *FileService {
 	return &FileService{
-		comparator: comparator,
-		copier:     copier,
-		filter:     filter,
+		copier: copier,
+		filter: filter,
 	}
 }
 
-// AreEqual compares files using header-aware comparison only for .mdc files
-func (f *FileService) AreEqual(file1, file2 string, overwriteHeaders bool) (bool, error) {
-	return f.comparator.AreEqual(file1, file2, overwriteHeaders)
+// Sync copies file applying header preservation only for .mdc files and file mode to the copy unless destination
+// already has the same content and mode, reading each file once, .mdc files and, if the policy applies to all text files, other text files are written with line endings of the normalization policy. Reports if destination was written
+func (f *FileService) Sync(srcPath, dstPath string, dstExists, overwriteHeaders bool, mode os.FileMode, normalization models.Normalization) (bool, error) {
+	return f.copier.Sync(srcPath, dstPath, dstExists, overwriteHeaders, mode, normalization)
 }
 
-// Copy copies file applying header preservation only for .mdc files
-func (f *FileService) Copy(srcPath, dstPath string, overwriteHeaders bool) error {
-	return f.copier.Copy(srcPath, dstPath, overwriteHeaders)
+// ModeMatches checks if destination file already has permissions the copy would give it
+func (f *FileService) ModeMatches(srcPath, dstPath string, mode os.FileMode) (bool, error) {
+	return f.copier.ModeMatches(srcPath, dstPath, mode)
 }
 
-// GetFilePatterns returns file patterns from flag value
-func (f *FileService) GetFilePatterns(flagValue string) ([]string, error) {
-	return f.filter.GetFilePatterns(flagValue)
+// GetFilePatterns returns include patterns followed by exclude patterns marked with "!"
+func (f *FileService) GetFilePatterns(include, exclude []string) ([]string, error) {
+	return f.filter.GetFilePatterns(include, exclude)
 }
 
-// FindFilesByPatterns finds all files matching patterns in directory
-func (f *FileService) FindFilesByPatterns(dir string, patterns []string) ([]string, error) {
-	return f.filter.FindFilesByPatterns(dir, patterns)
+// UnmatchedPatterns returns patterns that match none of slash-separated relative paths
+func (f *FileService) UnmatchedPatterns(patterns []string, relativePaths []string) ([]string, error) {
+	return f.filter.UnmatchedPatterns(patterns, relativePaths)
 }
 
-// CleanupExtraFilesByPatterns removes files that exist in destination but not in source, considering patterns
-func (f *FileService) CleanupExtraFilesByPatterns(srcFiles []string, srcBase, dstBase string, patterns []string) error {
-	return f.filter.CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns)
+// LoadIgnorePatterns reads .cursyncignore files at the root of given directories
+func (f *FileService) LoadIgnorePatterns(dirs ...string) ([]string, error) {
+	return f.filter.LoadIgnorePatterns(dirs...)
+}
+
+// FilterFilesByPatterns filters files relative to base directory by patterns skipping ignored files
+func (f *FileService) FilterFilesByPatterns(files []string, baseDir string, patterns, ignorePatterns []string) []string {
+	return f.filter.FilterFilesByPatterns(files, baseDir, patterns, ignorePatterns)
+}
+
+// CleanupExtraFilesByPatterns removes files that exist in destination but not in source, considering patterns,
+// ignored files are never removed
+func (f *FileService) CleanupExtraFilesByPatterns(srcFiles []string, srcBase, dstBase string, patterns, ignorePatterns []string) error {
+	return f.filter.CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns, ignorePatterns)
 }
@@ -6,176 +6,152 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
 )
 
-func TestFileService_AreEqual(t *testing.T) {
+func TestFileService_Sync(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
-		file1 := "file1.txt"
-		file2 := "file2.txt"
-		overwriteHeaders := false
-		expected := true
-
-		f.comparatorMock.EXPECT().
-			AreEqual(file1, file2, overwriteHeaders).
-			Return(expected, nil).
+		f.copierMock.EXPECT().
+			Sync("src.mdc", "dst.mdc", true, false, models.FileModePreserve, models.Normalization{}).
+			Return(true, nil).
 			Times(1)
 
-		result, err := f.fileService.AreEqual(file1, file2, overwriteHeaders)
+		written, err := f.fileService.Sync("src.mdc", "dst.mdc", true, false, models.FileModePreserve, models.Normalization{})
 		require.NoError(t, err)
-
-		if diff := cmp.Diff(expected, result); diff != "" {
-			t.Fatalf("mismatch (-want +got):\n%s", diff)
-		}
+		require.True(t, written)
 	})
+}
 
-	t.Run("error from comparator", func(t *testing.T) {
+func TestFileService_ModeMatches(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
-		file1 := "file1.txt"
-		file2 := "file2.txt"
-		overwriteHeaders := false
-		expectedErr := errors.New("comparator error")
-
-		f.comparatorMock.EXPECT().
-			AreEqual(file1, file2, overwriteHeaders).
-			Return(false, expectedErr).
+		f.copierMock.EXPECT().
+			ModeMatches("src.sh", "dst.sh", models.FileModePreserve).
+			Return(true, nil).
 			Times(1)
 
-		result, err := f.fileService.AreEqual(file1, file2, overwriteHeaders)
-		require.ErrorIs(t, err, expectedErr)
-
-		if diff := cmp.Diff(false, result); diff != "" {
-			t.Fatalf("mismatch (-want +got):\n%s", diff)
-		}
+		matches, err := f.fileService.ModeMatches("src.sh", "dst.sh", models.FileModePreserve)
+		require.NoError(t, err)
+		require.True(t, matches)
 	})
 }
 
-func TestFileService_Copy(t *testing.T) {
+func TestFileService_GetFilePatterns(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
-		srcPath := "src.txt"
-		dstPath := "dst.txt"
-		overwriteHeaders := false
+		include := []string{"*.txt"}
+		exclude := []string{"draft.txt"}
+		expected := []string{"*.txt", "!draft.txt"}
 
-		f.copierMock.EXPECT().
-			Copy(srcPath, dstPath, overwriteHeaders).
-			Return(nil).
+		f.filterMock.EXPECT().
+			GetFilePatterns(include, exclude).
+			Return(expected, nil).
 			Times(1)
 
-		err := f.fileService.Copy(srcPath, dstPath, overwriteHeaders)
+		result, err := f.fileService.GetFilePatterns(include, exclude)
 		require.NoError(t, err)
+
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
 	})
 
-	t.Run("error from copier", func(t *testing.T) {
+	t.Run("error from filter", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
-		srcPath := "src.txt"
-		dstPath := "dst.txt"
-		overwriteHeaders := false
-		expectedErr := errors.New("copier error")
+		include := []string{"*.txt"}
+		expectedErr := errors.New("filter error")
 
-		f.copierMock.EXPECT().
-			Copy(srcPath, dstPath, overwriteHeaders).
-			Return(expectedErr).
+		f.filterMock.EXPECT().
+			GetFilePatterns(include, []string(nil)).
+			Return(nil, expectedErr).
 			Times(1)
 
-		err := f.fileService.Copy(srcPath, dstPath, overwriteHeaders)
+		result, err := f.fileService.GetFilePatterns(include, nil)
 		require.ErrorIs(t, err, expectedErr)
+		require.Empty(t, result)
 	})
 }
 
-func TestFileService_GetFilePatterns(t *testing.T) {
+func TestFileService_UnmatchedPatterns(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
-		flagValue := "*.txt"
-		expected := []string{"*.txt"}
+		patterns := []string{"*.mdc", "tema/**"}
+		expected := []string{"tema/**"}
 
 		f.filterMock.EXPECT().
-			GetFilePatterns(flagValue).
+			UnmatchedPatterns(patterns, []string{"a.mdc"}).
 			Return(expected, nil).
 			Times(1)
 
-		result, err := f.fileService.GetFilePatterns(flagValue)
+		result, err := f.fileService.UnmatchedPatterns(patterns, []string{"a.mdc"})
 		require.NoError(t, err)
 
 		if diff := cmp.Diff(expected, result); diff != "" {
 			t.Fatalf("mismatch (-want +got):\n%s", diff)
 		}
 	})
-
-	t.Run("error from filter", func(t *testing.T) {
-		t.Parallel()
-		f, finish := setUp(t)
-		defer finish()
-
-		flagValue := "*.txt"
-		expectedErr := errors.New("filter error")
-
-		f.filterMock.EXPECT().
-			GetFilePatterns(flagValue).
-			Return(nil, expectedErr).
-			Times(1)
-
-		result, err := f.fileService.GetFilePatterns(flagValue)
-		require.ErrorIs(t, err, expectedErr)
-		require.Empty(t, result)
-	})
 }
 
-func TestFileService_FindFilesByPatterns(t *testing.T) {
+func TestFileService_LoadIgnorePatterns(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
-		dir := "/test"
-		patterns := []string{"*.txt"}
-		expected := []string{"/test/file.txt"}
+		expected := []string{"README.md", "/.cursyncignore"}
 
 		f.filterMock.EXPECT().
-			FindFilesByPatterns(dir, patterns).
+			LoadIgnorePatterns("/src", "/dst").
 			Return(expected, nil).
 			Times(1)
 
-		result, err := f.fileService.FindFilesByPatterns(dir, patterns)
+		result, err := f.fileService.LoadIgnorePatterns("/src", "/dst")
 		require.NoError(t, err)
 
 		if diff := cmp.Diff(expected, result); diff != "" {
 			t.Fatalf("mismatch (-want +got):\n%s", diff)
 		}
 	})
+}
 
-	t.Run("error from filter", func(t *testing.T) {
+func TestFileService_FilterFilesByPatterns(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
-		dir := "/test"
+		files := []string{"/test/file.txt", "/test/README.md"}
 		patterns := []string{"*.txt"}
-		expectedErr := errors.New("filter error")
+		ignorePatterns := []string{"README.md"}
+		expected := []string{"/test/file.txt"}
 
 		f.filterMock.EXPECT().
-			FindFilesByPatterns(dir, patterns).
-			Return(nil, expectedErr).
+			FilterFilesByPatterns(files, "/test", patterns, ignorePatterns).
+			Return(expected).
 			Times(1)
 
-		result, err := f.fileService.FindFilesByPatterns(dir, patterns)
-		require.ErrorIs(t, err, expectedErr)
-		require.Empty(t, result)
+		result := f.fileService.FilterFilesByPatterns(files, "/test", patterns, ignorePatterns)
+
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
 	})
 }
 
@@ -189,13 +165,14 @@ func TestFileService_CleanupExtraFilesByPatterns(t *testing.T) {
 		srcBase := "/src"
 		dstBase := "/dst"
 		patterns := []string{"*.txt"}
+		ignorePatterns := []string{"README.md"}
 
 		f.filterMock.EXPECT().
-			CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns).
+			CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns, ignorePatterns).
 			Return(nil).
 			Times(1)
 
-		err := f.fileService.CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns)
+		err := f.fileService.CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns, ignorePatterns)
 		require.NoError(t, err)
 	})
 
@@ -208,14 +185,15 @@ func TestFileService_CleanupExtraFilesByPatterns(t *testing.T) {
 		srcBase := "/src"
 		dstBase := "/dst"
 		patterns := []string{"*.txt"}
+		ignorePatterns := []string{"README.md"}
 		expectedErr := errors.New("filter error")
 
 		f.filterMock.EXPECT().
-			CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns).
+			CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns, ignorePatterns).
 			Return(expectedErr).
 			Times(1)
 
-		err := f.fileService.CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns)
+		err := f.fileService.CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns, ignorePatterns)
 		require.ErrorIs(t, err, expectedErr)
 	})
 }
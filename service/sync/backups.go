@@ -0,0 +1,111 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yanodintsovmercuryo/cursync/pkg/backup"
+)
+
+// beginBackup starts saving previous versions of files changed by the operation,
+// manifest of the project is saved along with them
+func (s *SyncService) beginBackup(projectRoot, operation string) error {
+	if err := s.backups.Begin(projectRoot, operation, s.manifestRepository.GetManifestPath(projectRoot)); err != nil {
+		return fmt.Errorf("failed to start backup: %w", err)
+	}
+	return nil
+}
+
+// finishBackup completes backup of the operation
+func (s *SyncService) finishBackup() {
+	if err := s.backups.Finish(); err != nil {
+		s.output.PrintWarningf("Failed to save backup: %v", err)
+	}
+}
+
+// openIndex loads content index of directories compared by the operation, the operation compares files
+// by reading them if the index cannot be loaded
+func (s *SyncService) openIndex(dirs ...string) {
+	if err := s.index.Open(dirs...); err != nil {
+		s.output.PrintWarningf("Failed to load content index: %v", err)
+	}
+}
+
+// saveIndex stores content index updated by the operation
+func (s *SyncService) saveIndex() {
+	if err := s.index.Save(); err != nil {
+		s.output.PrintWarningf("Failed to save content index: %v", err)
+	}
+}
+
+// rollback restores files changed by the failed operation, so that it either fully succeeds or changes nothing
+func (s *SyncService) rollback(err error) error {
+	if rollbackErr := s.backups.Rollback(); rollbackErr != nil {
+		return fmt.Errorf("%w; rollback failed, run 'cursync undo' to restore remaining files: %v", err, rollbackErr)
+	}
+	return fmt.Errorf("%w; all changes rolled back", err)
+}
+
+// Undo restores files changed by the last pull or push in the current project,
+// files changed after the operation are overwritten only with force
+func (s *SyncService) Undo(force bool) (*backup.Backup, error) {
+	projectRoot, err := s.currentProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := s.backups.Restore(projectRoot, force)
+	if errors.Is(err, backup.ErrModified) {
+		return nil, fmt.Errorf("failed to undo: %w, use --force to restore them anyway", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to undo: %w", err)
+	}
+
+	for _, entry := range b.Entries {
+		if entry.Existed {
+			s.output.PrintOperation("update", entry.Path)
+		} else {
+			s.output.PrintOperation("delete", entry.Path)
+		}
+	}
+	if b.Committed != "" {
+		s.output.PrintWarningf("The %s was committed in %s, the commit is not reverted", b.Operation, b.Committed)
+	}
+	return b, nil
+}
+
+// ListBackups prints backups of the current project, newest first
+func (s *SyncService) ListBackups() ([]*backup.Backup, error) {
+	projectRoot, err := s.currentProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	backups, err := s.backups.List(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	if len(backups) == 0 {
+		s.output.PrintInfo("No backups")
+	}
+	for _, b := range backups {
+		s.output.PrintInfo(fmt.Sprintf("%s  %-4s  %d files  %s", b.ID, b.Operation, len(b.Entries), b.CreatedAt.Local().Format("2006-01-02 15:04:05")))
+	}
+	return backups, nil
+}
+
+// currentProjectRoot returns git root of the current directory
+func (s *SyncService) currentProjectRoot() (string, error) {
+	currentDir, err := s.fileOps.GetCurrentDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectRoot, err := s.gitOps.GetGitRootDir(currentDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to find git root: %w", err)
+	}
+	return projectRoot, nil
+}
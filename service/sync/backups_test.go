@@ -0,0 +1,355 @@
+package sync_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/backup"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
+)
+
+func TestSyncService_Undo(t *testing.T) {
+	t.Run("restores files of the last operation", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		expected := &backup.Backup{
+			ID:        "20260101-120000.000000",
+			Operation: "pull",
+			Entries: []backup.Entry{
+				{Path: testDstFile, Existed: true, File: "0"},
+				{Path: testDestRulesDir + "/new.mdc"},
+			},
+		}
+		f.backupMock.EXPECT().
+			Restore(testGitRoot, false).
+			Return(expected, nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintOperation("update", testDstFile).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintOperation("delete", testDestRulesDir+"/new.mdc").
+			Times(1)
+
+		result, err := f.syncService.Undo(false)
+		require.NoError(t, err)
+		require.Equal(t, expected, result)
+	})
+
+	t.Run("error when there is nothing to undo", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		expectedErr := errors.New("no backups for project /test/git")
+		f.backupMock.EXPECT().
+			Restore(testGitRoot, false).
+			Return(nil, expectedErr).
+			Times(1)
+
+		result, err := f.syncService.Undo(false)
+		require.ErrorIs(t, err, expectedErr)
+		require.Nil(t, result)
+	})
+
+	t.Run("suggests force when files were changed after the operation", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		f.backupMock.EXPECT().
+			Restore(testGitRoot, false).
+			Return(nil, fmt.Errorf("%w: %s", backup.ErrModified, testDstFile)).
+			Times(1)
+
+		result, err := f.syncService.Undo(false)
+		require.ErrorIs(t, err, backup.ErrModified)
+		require.ErrorContains(t, err, "--force")
+		require.Nil(t, result)
+	})
+
+	t.Run("warns about commit of the push", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		expected := &backup.Backup{
+			ID:        "20260101-120000.000000",
+			Operation: "push",
+			Entries:   []backup.Entry{{Path: "/test/rules/file1.mdc", Existed: true, File: "0"}},
+			Committed: "/test/rules",
+		}
+		f.backupMock.EXPECT().
+			Restore(testGitRoot, true).
+			Return(expected, nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintOperation("update", "/test/rules/file1.mdc").
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintWarningf("The %s was committed in %s, the commit is not reverted", "push", "/test/rules").
+			Times(1)
+
+		result, err := f.syncService.Undo(true)
+		require.NoError(t, err)
+		require.Equal(t, expected, result)
+	})
+}
+
+func TestSyncService_ListBackups(t *testing.T) {
+	t.Run("prints backups of the project", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		createdAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local)
+		backups := []*backup.Backup{
+			{ID: "20260101-120000.000000", Operation: "push", CreatedAt: createdAt, Entries: []backup.Entry{{Path: testDstFile}}},
+		}
+		f.backupMock.EXPECT().
+			List(testGitRoot).
+			Return(backups, nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintInfo("20260101-120000.000000  push  1 files  2026-01-01 12:00:00").
+			Times(1)
+
+		result, err := f.syncService.ListBackups()
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+	})
+
+	t.Run("reports missing backups", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		f.backupMock.EXPECT().
+			List(testGitRoot).
+			Return(nil, nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintInfo("No backups").
+			Times(1)
+
+		result, err := f.syncService.ListBackups()
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+}
+
+func TestSyncService_PullRules_Backup(t *testing.T) {
+	t.Run("error starting backup", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		manifestPath := testGitRoot + "/.cursor/cursync-manifest.json"
+		f.manifestMock.EXPECT().
+			GetManifestPath(testGitRoot).
+			Return(manifestPath).
+			Times(1)
+
+		expectedErr := errors.New("permission denied")
+		f.backupMock.EXPECT().
+			Begin(testGitRoot, "pull", manifestPath).
+			Return(expectedErr).
+			Times(1)
+
+		result, err := f.syncService.PullRules(&models.SyncOptions{RulesDir: "/test/rules"})
+		require.ErrorIs(t, err, expectedErr)
+		require.Contains(t, err.Error(), "failed to start backup")
+		require.Nil(t, result)
+	})
+	t.Run("rolls back changes when deleting file fails", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		const (
+			orphanSrcFile = "/test/rules/old.mdc"
+			orphanDstFile = "/test/git/.cursor/rules/old.mdc"
+			orphanRel     = "old.mdc"
+		)
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		f.expectRollback(testGitRoot, "pull")
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", testDestRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll(testDestRulesDir, os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Load(testGitRoot).
+			Return(&manifest.Manifest{
+				RulesDir: "/test/rules",
+				Managed:  map[string]string{orphanRel: "/test/rules"},
+			}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles("/test/rules", noSymlinks).
+			Return(&models.FileScan{Files: []string{}}, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(orphanSrcFile, "/test/rules").
+			Return(orphanRel, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FileExists(orphanDstFile).
+			Return(true, nil).
+			Times(1)
+
+		expectedErr := errors.New("permission denied")
+		f.fileOpsMock.EXPECT().
+			RemoveFile(orphanDstFile).
+			Return(expectedErr).
+			Times(1)
+
+		result, err := f.syncService.PullRules(&models.SyncOptions{RulesDir: "/test/rules", DeleteMode: models.DeleteManaged})
+		require.ErrorIs(t, err, expectedErr)
+		require.Contains(t, err.Error(), "failed to delete file old.mdc")
+		require.Contains(t, err.Error(), "all changes rolled back")
+		require.Nil(t, result)
+	})
+
+	t.Run("reports failed rollback", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		f.expectBackupBegin(testGitRoot, "pull")
+
+		expectedErr := errors.New("file patterns error")
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return(nil, expectedErr).
+			Times(1)
+
+		f.backupMock.EXPECT().
+			Rollback().
+			Return(errors.New("failed to restore old.mdc")).
+			Times(1)
+
+		result, err := f.syncService.PullRules(&models.SyncOptions{RulesDir: "/test/rules"})
+		require.ErrorIs(t, err, expectedErr)
+		require.Contains(t, err.Error(), "rollback failed, run 'cursync undo' to restore remaining files: failed to restore old.mdc")
+		require.Nil(t, result)
+	})
+}
@@ -0,0 +1,71 @@
+package sync
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
+)
+
+// cleanupDestination removes destination files missing in source according to delete mode,
+// returns relative paths of managed files removed in managed mode
+func (s *SyncService) cleanupDestination(mode models.DeleteMode, managed map[string]bool, sourceFiles []string, srcBase, dstBase string, patterns, ignorePatterns []string) ([]string, error) {
+	switch mode {
+	case models.DeleteNone:
+		return nil, nil
+	case models.DeleteManaged:
+		return s.cleanupManagedFiles(managed, sourceFiles, srcBase, dstBase, patterns, ignorePatterns)
+	default:
+		return nil, s.cleanupExtraFilesWithPatterns(sourceFiles, srcBase, dstBase, patterns, ignorePatterns)
+	}
+}
+
+// cleanupManagedFiles removes managed destination files missing in source, files selected by patterns only
+func (s *SyncService) cleanupManagedFiles(managed map[string]bool, sourceFiles []string, srcBase, dstBase string, patterns, ignorePatterns []string) ([]string, error) {
+	sourceSet := make(map[string]bool, len(sourceFiles))
+	for _, srcFile := range sourceFiles {
+		sourceSet[relativeSlashPath(srcFile, srcBase)] = true
+	}
+
+	missing := []string{}
+	for relativePath := range managed {
+		if !sourceSet[relativePath] {
+			missing = append(missing, filepath.Join(srcBase, filepath.FromSlash(relativePath)))
+		}
+	}
+	sort.Strings(missing)
+
+	if len(missing) > 0 && (len(patterns) > 0 || len(ignorePatterns) > 0) {
+		missing = s.fileService.FilterFilesByPatterns(missing, srcBase, patterns, ignorePatterns)
+	}
+
+	return s.removeDeletedFiles(missing, srcBase, dstBase)
+}
+
+// loadManifest loads manifest of the project, returns nil if it is missing or unreadable
+func (s *SyncService) loadManifest(projectRoot string) *manifest.Manifest {
+	m, err := s.manifestRepository.Load(projectRoot)
+	if err != nil {
+		return nil
+	}
+	return m
+}
+
+// relativeSlashPath returns slash-separated path of file relative to base directory
+func relativeSlashPath(file, base string) string {
+	rel, err := filepath.Rel(base, file)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// relativeSlashPaths returns slash-separated paths of files relative to base directory
+func relativeSlashPaths(files []string, base string) []string {
+	relativePaths := make([]string, 0, len(files))
+	for _, file := range files {
+		relativePaths = append(relativePaths, relativeSlashPath(file, base))
+	}
+	return relativePaths
+}
@@ -0,0 +1,275 @@
+package sync_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
+)
+
+func TestSyncService_PullRules_DeleteMode(t *testing.T) {
+	const (
+		orphanSrcFile = "/test/rules/old.mdc"
+		orphanDstFile = "/test/git/.cursor/rules/old.mdc"
+		orphanRel     = "old.mdc"
+	)
+
+	expectFullPull := func(f *fixture, m *manifest.Manifest) {
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		f.expectBackup(testGitRoot, "pull")
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", testDestRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll(testDestRulesDir, os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Load(testGitRoot).
+			Return(m, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles("/test/rules", noSymlinks).
+			Return(&models.FileScan{Files: []string{}}, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetHeadCommit("/test/rules").
+			Return("", errors.New("not a git repository")).
+			Times(1)
+	}
+
+	t.Run("none mode keeps extra destination files", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:   "/test/rules",
+			DeleteMode: models.DeleteNone,
+		}
+		expectFullPull(f, nil)
+
+		files := map[string]manifest.FileEntry{orphanRel: {Size: 1, ModTime: 1}}
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(files, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRoot, &manifest.Manifest{
+				RulesDir:   "/test/rules",
+				DeleteMode: string(models.DeleteNone),
+				Files:      files,
+			}).
+			Return(nil).
+			Times(1)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.False(t, result.HasChanges)
+	})
+
+	t.Run("managed mode removes only files placed by previous syncs", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:   "/test/rules",
+			DeleteMode: models.DeleteManaged,
+		}
+		expectFullPull(f, &manifest.Manifest{
+			RulesDir: "/test/rules",
+			Managed:  map[string]string{orphanRel: "/test/rules"},
+		})
+
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(orphanSrcFile, "/test/rules").
+			Return(orphanRel, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FileExists(orphanDstFile).
+			Return(true, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			RemoveFile(orphanDstFile).
+			Return(nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintOperation("delete", orphanRel).
+			Times(1)
+
+		files := map[string]manifest.FileEntry{testLocalFileRel: {Size: 5, ModTime: 500}}
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(files, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRoot, &manifest.Manifest{
+				RulesDir:   "/test/rules",
+				DeleteMode: string(models.DeleteManaged),
+				Files:      files,
+			}).
+			Return(nil).
+			Times(1)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.False(t, result.HasChanges)
+	})
+
+	t.Run("managed mode skips managed files of another rules directory", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:   "/test/rules",
+			DeleteMode: models.DeleteManaged,
+		}
+		expectFullPull(f, &manifest.Manifest{
+			RulesDir: "/other/rules",
+			Managed:  map[string]string{orphanRel: "/other/rules"},
+		})
+
+		files := map[string]manifest.FileEntry{orphanRel: {Size: 1, ModTime: 1}}
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(files, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRoot, &manifest.Manifest{
+				RulesDir:   "/test/rules",
+				DeleteMode: string(models.DeleteManaged),
+				Files:      files,
+				Managed:    map[string]string{orphanRel: "/other/rules"},
+			}).
+			Return(nil).
+			Times(1)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.False(t, result.HasChanges)
+	})
+}
+
+func TestSyncService_PushRules_DeleteMode(t *testing.T) {
+	t.Run("managed mode records pushed files and forgets removed ones", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:   "/test/rules",
+			DeleteMode: models.DeleteManaged,
+		}
+		rulesSourceDirInProject := testDestRulesDirPush
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDirPush, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDirPush).
+			Return(testGitRootPush, nil).
+			Times(1)
+
+		f.expectBackup(testGitRootPush, "push")
+
+		f.fileOpsMock.EXPECT().
+			FileExists(rulesSourceDirInProject).
+			Return(true, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: []string{}}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll("/test/rules", os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		files := map[string]manifest.FileEntry{"kept.mdc": {Size: 1, ModTime: 1}}
+		f.manifestMock.EXPECT().
+			Load(testGitRootPush).
+			Return(&manifest.Manifest{
+				RulesDir: "/test/rules",
+				Files:    files,
+				Managed:  map[string]string{"gone.mdc": "/test/rules"},
+			}, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(rulesSourceDirInProject+"/gone.mdc", rulesSourceDirInProject).
+			Return("gone.mdc", nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FileExists("/test/rules/gone.mdc").
+			Return(true, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			RemoveFile("/test/rules/gone.mdc").
+			Return(nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintOperation("delete", "gone.mdc").
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRootPush, &manifest.Manifest{
+				RulesDir: "/test/rules",
+				Files:    files,
+			}).
+			Return(nil).
+			Times(1)
+
+		result, err := f.syncService.PushRules(options)
+		require.NoError(t, err)
+		require.False(t, result.HasChanges)
+	})
+}
@@ -0,0 +1,112 @@
+package sync
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
+)
+
+// placeFiles links source files into destination for pull --link or copies them otherwise
+func (s *SyncService) placeFiles(sourceFiles []string, srcBase, dstBase string, options *models.SyncOptions) (*models.SyncResult, error) {
+	if options.Link {
+		return s.linkFiles(sourceFiles, srcBase, dstBase)
+	}
+	return s.copyFiles(sourceFiles, srcBase, dstBase, options)
+}
+
+// linkFiles replaces destination files with symlinks to absolute paths of source files,
+// files already linked to their source are left untouched. Directories are never linked as a whole,
+// since a linked directory would expose files not selected by patterns and turn local files into source files
+func (s *SyncService) linkFiles(sourceFiles []string, srcBase, dstBase string) (*models.SyncResult, error) {
+	result := &models.SyncResult{
+		Operations: []models.FileOperation{},
+		HasChanges: false,
+	}
+
+	for _, srcFileFullPath := range sourceFiles {
+		dstFileFullPath, err := s.pathUtils.RecreateDirectoryStructure(srcFileFullPath, srcBase, dstBase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to recreate directory structure for %s: %w", srcFileFullPath, err)
+		}
+
+		relativePath, err := s.pathUtils.GetRelativePath(srcFileFullPath, srcBase)
+		if err != nil {
+			relativePath = s.pathUtils.GetBaseName(srcFileFullPath)
+		}
+
+		target, err := filepath.Abs(srcFileFullPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve source file %s: %w", relativePath, err)
+		}
+		if s.linkMatches(dstFileFullPath, target) {
+			continue
+		}
+
+		fileExistedBeforeLink, err := s.checkFileExists(dstFileFullPath, relativePath)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.fileOps.WriteSymlink(target, dstFileFullPath); err != nil {
+			return nil, fmt.Errorf("failed to link file %s: %w", relativePath, err)
+		}
+
+		operationType := models.OperationUpdate
+		if !fileExistedBeforeLink {
+			operationType = models.OperationAdd
+		}
+		s.output.PrintOperation(string(operationType), relativePath)
+
+		result.Operations = append(result.Operations, models.FileOperation{
+			Type:         operationType,
+			SourcePath:   srcFileFullPath,
+			TargetPath:   dstFileFullPath,
+			RelativePath: relativePath,
+		})
+		result.HasChanges = true
+	}
+
+	return result, nil
+}
+
+// unlinkFiles removes destination symlinks made by pull --link, so that following copy replaces them with files
+func (s *SyncService) unlinkFiles(sourceFiles []string, srcBase, dstBase string) error {
+	for _, srcFileFullPath := range sourceFiles {
+		relativePath, err := s.pathUtils.GetRelativePath(srcFileFullPath, srcBase)
+		if err != nil {
+			continue
+		}
+
+		target, err := filepath.Abs(srcFileFullPath)
+		if err != nil {
+			continue
+		}
+
+		dstFileFullPath := filepath.Join(dstBase, relativePath)
+		if !s.linkMatches(dstFileFullPath, target) {
+			continue
+		}
+		if err := s.fileOps.RemoveFile(dstFileFullPath); err != nil {
+			return fmt.Errorf("failed to unlink file %s: %w", relativePath, err)
+		}
+	}
+	return nil
+}
+
+// hasLinkedChanges checks if files linked into the rules directory by pull --link were edited in place,
+// such changes are already in the rules directory and only need a commit
+func (s *SyncService) hasLinkedChanges(m *manifest.Manifest, rulesDir string) bool {
+	if m == nil || !m.Linked || m.RulesDir != rulesDir {
+		return false
+	}
+
+	dirty, err := s.gitOps.HasUncommittedChanges(rulesDir)
+	if err != nil || !dirty {
+		return false
+	}
+
+	s.output.PrintInfo("Linked files changed in " + rulesDir)
+	return true
+}
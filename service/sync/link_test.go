@@ -0,0 +1,340 @@
+package sync_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
+)
+
+func TestSyncService_PullRules_Link(t *testing.T) {
+	expectPullPrelude := func(f *fixture, m *manifest.Manifest) {
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		f.expectBackup(testGitRoot, "pull")
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(testRulesDir, testDestRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll(testDestRulesDir, os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Load(testGitRoot).
+			Return(m, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(testRulesDir, noSymlinks).
+			Return(&models.FileScan{Files: []string{testSrcFile}}, nil).
+			Times(1)
+	}
+
+	expectManifestSaved := func(f *fixture, linked bool) {
+		f.gitOpsMock.EXPECT().
+			GetHeadCommit(testRulesDir).
+			Return(testHeadCommit, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			HasUncommittedChanges(testRulesDir).
+			Return(false, nil).
+			Times(1)
+
+		files := map[string]manifest.FileEntry{testRelativePath: {Size: 1, ModTime: 1}}
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(files, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRoot, &manifest.Manifest{
+				RulesDir:   testRulesDir,
+				Commit:     testHeadCommit,
+				DeleteMode: string(models.DeleteNone),
+				Linked:     linked,
+				Files:      files,
+				Managed:    map[string]string{testRelativePath: testRulesDir},
+			}).
+			Return(nil).
+			Times(1)
+	}
+
+	t.Run("replaces copied file with symlink", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:   testRulesDir,
+			DeleteMode: models.DeleteNone,
+			Link:       true,
+		}
+		expectPullPrelude(f, nil)
+
+		f.pathUtilsMock.EXPECT().
+			RecreateDirectoryStructure(testSrcFile, testRulesDir, testDestRulesDir).
+			Return(testDstFile, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(testSrcFile, testRulesDir).
+			Return(testRelativePath, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			ReadLink(testDstFile).
+			Return("", os.ErrInvalid).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Stat(testDstFile).
+			Return(nil, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			WriteSymlink(testSrcFile, testDstFile).
+			Return(nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintOperation("update", testRelativePath).
+			Times(1)
+
+		expectManifestSaved(f, true)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.True(t, result.HasChanges)
+
+		expected := []models.FileOperation{{
+			Type:         models.OperationUpdate,
+			SourcePath:   testSrcFile,
+			TargetPath:   testDstFile,
+			RelativePath: testRelativePath,
+		}}
+		if diff := cmp.Diff(expected, result.Operations); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("leaves linked file untouched", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:   testRulesDir,
+			DeleteMode: models.DeleteNone,
+			Link:       true,
+		}
+		expectPullPrelude(f, nil)
+
+		f.pathUtilsMock.EXPECT().
+			RecreateDirectoryStructure(testSrcFile, testRulesDir, testDestRulesDir).
+			Return(testDstFile, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(testSrcFile, testRulesDir).
+			Return(testRelativePath, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			ReadLink(testDstFile).
+			Return(testSrcFile, nil).
+			Times(1)
+
+		expectManifestSaved(f, true)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.False(t, result.HasChanges)
+	})
+
+	t.Run("converts linked file back to copy", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:   testRulesDir,
+			DeleteMode: models.DeleteNone,
+		}
+		expectPullPrelude(f, &manifest.Manifest{
+			RulesDir: testRulesDir,
+			Commit:   testLastCommit,
+			Linked:   true,
+			Managed:  map[string]string{testRelativePath: testRulesDir},
+		})
+
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(testSrcFile, testRulesDir).
+			Return(testRelativePath, nil).
+			Times(2)
+
+		f.fileOpsMock.EXPECT().
+			ReadLink(testDstFile).
+			Return(testSrcFile, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			RemoveFile(testDstFile).
+			Return(nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			RecreateDirectoryStructure(testSrcFile, testRulesDir, testDestRulesDir).
+			Return(testDstFile, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Stat(testDstFile).
+			Return(nil, os.ErrNotExist).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			Sync(testSrcFile, testDstFile, false, false, models.FileModePreserve, models.Normalization{}).
+			Return(true, nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintOperation("add", testRelativePath).
+			Times(1)
+
+		expectManifestSaved(f, false)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.True(t, result.HasChanges)
+	})
+}
+
+func TestSyncService_PushRules_Link(t *testing.T) {
+	t.Run("commits changes made through linked files", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:   testRulesDir,
+			DeleteMode: models.DeleteNone,
+		}
+		rulesSourceDirInProject := testDestRulesDirPush
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDirPush, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDirPush).
+			Return(testGitRootPush, nil).
+			Times(1)
+
+		f.expectBackup(testGitRootPush, "push")
+
+		f.fileOpsMock.EXPECT().
+			FileExists(rulesSourceDirInProject).
+			Return(true, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(rulesSourceDirInProject, testRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(rulesSourceDirInProject, noSymlinks, testRulesDir).
+			Return(&models.FileScan{Files: []string{testSrcFilePush}}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll(testRulesDir, os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Load(testGitRootPush).
+			Return(&manifest.Manifest{
+				RulesDir: testRulesDir,
+				Linked:   true,
+				Managed:  map[string]string{testRelativePathPush: testRulesDir},
+			}, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			RecreateDirectoryStructure(testSrcFilePush, rulesSourceDirInProject, testRulesDir).
+			Return(testDstFilePush, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(testSrcFilePush, rulesSourceDirInProject).
+			Return(testRelativePathPush, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FileExists(testDstFilePush).
+			Return(true, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			Sync(testSrcFilePush, testDstFilePush, true, false, models.FileModePreserve, models.Normalization{}).
+			Return(false, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			HasUncommittedChanges(testRulesDir).
+			Return(true, nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintInfo("Linked files changed in " + testRulesDir).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			GetBaseName(testGitRootPush).
+			Return("git").
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			CommitChanges(testRulesDir, "Sync cursor rules: updated from project git", false).
+			Return(nil).
+			Times(1)
+
+		f.backupMock.EXPECT().
+			MarkCommitted(testRulesDir).
+			Times(1)
+
+		result, err := f.syncService.PushRules(options)
+		require.NoError(t, err)
+		require.True(t, result.HasChanges)
+		require.Empty(t, result.Operations)
+	})
+}
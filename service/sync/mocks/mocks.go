@@ -10,9 +10,13 @@
 package mocks
 
 import (
+	context "context"
 	os "os"
 	reflect "reflect"
 
+	models "github.com/yanodintsovmercuryo/cursync/models"
+	backup "github.com/yanodintsovmercuryo/cursync/pkg/backup"
+	manifest "github.com/yanodintsovmercuryo/cursync/pkg/manifest"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -57,6 +61,18 @@ func (mr *MockoutputServiceMockRecorder) PrintErrorf(format any, args ...any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrintErrorf", reflect.TypeOf((*MockoutputService)(nil).PrintErrorf), varargs...)
 }
 
+// PrintInfo mocks base method.
+func (m *MockoutputService) PrintInfo(message string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PrintInfo", message)
+}
+
+// PrintInfo indicates an expected call of PrintInfo.
+func (mr *MockoutputServiceMockRecorder) PrintInfo(message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrintInfo", reflect.TypeOf((*MockoutputService)(nil).PrintInfo), message)
+}
+
 // PrintOperation mocks base method.
 func (m *MockoutputService) PrintOperation(operationType, relativePath string) {
 	m.ctrl.T.Helper()
@@ -81,6 +97,23 @@ func (mr *MockoutputServiceMockRecorder) PrintOperationWithTarget(operationType,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrintOperationWithTarget", reflect.TypeOf((*MockoutputService)(nil).PrintOperationWithTarget), operationType, relativePath, target)
 }
 
+// PrintWarningf mocks base method.
+func (m *MockoutputService) PrintWarningf(format string, args ...any) {
+	m.ctrl.T.Helper()
+	varargs := []any{format}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "PrintWarningf", varargs...)
+}
+
+// PrintWarningf indicates an expected call of PrintWarningf.
+func (mr *MockoutputServiceMockRecorder) PrintWarningf(format any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{format}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrintWarningf", reflect.TypeOf((*MockoutputService)(nil).PrintWarningf), varargs...)
+}
+
 // MockpathUtils is a mock of pathUtils interface.
 type MockpathUtils struct {
 	ctrl     *gomock.Controller
@@ -187,6 +220,21 @@ func (mr *MockgitOpsMockRecorder) CommitChanges(repoDir, commitMessage, withoutP
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitChanges", reflect.TypeOf((*MockgitOps)(nil).CommitChanges), repoDir, commitMessage, withoutPush)
 }
 
+// GetChangedFiles mocks base method.
+func (m *MockgitOps) GetChangedFiles(repoDir, fromCommit string) ([]models.ChangedFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChangedFiles", repoDir, fromCommit)
+	ret0, _ := ret[0].([]models.ChangedFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChangedFiles indicates an expected call of GetChangedFiles.
+func (mr *MockgitOpsMockRecorder) GetChangedFiles(repoDir, fromCommit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChangedFiles", reflect.TypeOf((*MockgitOps)(nil).GetChangedFiles), repoDir, fromCommit)
+}
+
 // GetGitRootDir mocks base method.
 func (m *MockgitOps) GetGitRootDir(startDir string) (string, error) {
 	m.ctrl.T.Helper()
@@ -202,6 +250,231 @@ func (mr *MockgitOpsMockRecorder) GetGitRootDir(startDir any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGitRootDir", reflect.TypeOf((*MockgitOps)(nil).GetGitRootDir), startDir)
 }
 
+// GetHeadCommit mocks base method.
+func (m *MockgitOps) GetHeadCommit(repoDir string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeadCommit", repoDir)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHeadCommit indicates an expected call of GetHeadCommit.
+func (mr *MockgitOpsMockRecorder) GetHeadCommit(repoDir any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeadCommit", reflect.TypeOf((*MockgitOps)(nil).GetHeadCommit), repoDir)
+}
+
+// HasUncommittedChanges mocks base method.
+func (m *MockgitOps) HasUncommittedChanges(repoDir string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasUncommittedChanges", repoDir)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasUncommittedChanges indicates an expected call of HasUncommittedChanges.
+func (mr *MockgitOpsMockRecorder) HasUncommittedChanges(repoDir any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasUncommittedChanges", reflect.TypeOf((*MockgitOps)(nil).HasUncommittedChanges), repoDir)
+}
+
+// MockmanifestRepository is a mock of manifestRepository interface.
+type MockmanifestRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockmanifestRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockmanifestRepositoryMockRecorder is the mock recorder for MockmanifestRepository.
+type MockmanifestRepositoryMockRecorder struct {
+	mock *MockmanifestRepository
+}
+
+// NewMockmanifestRepository creates a new mock instance.
+func NewMockmanifestRepository(ctrl *gomock.Controller) *MockmanifestRepository {
+	mock := &MockmanifestRepository{ctrl: ctrl}
+	mock.recorder = &MockmanifestRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockmanifestRepository) EXPECT() *MockmanifestRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetManifestPath mocks base method.
+func (m *MockmanifestRepository) GetManifestPath(projectRoot string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetManifestPath", projectRoot)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetManifestPath indicates an expected call of GetManifestPath.
+func (mr *MockmanifestRepositoryMockRecorder) GetManifestPath(projectRoot any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManifestPath", reflect.TypeOf((*MockmanifestRepository)(nil).GetManifestPath), projectRoot)
+}
+
+// Load mocks base method.
+func (m *MockmanifestRepository) Load(projectRoot string) (*manifest.Manifest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load", projectRoot)
+	ret0, _ := ret[0].(*manifest.Manifest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Load indicates an expected call of Load.
+func (mr *MockmanifestRepositoryMockRecorder) Load(projectRoot any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockmanifestRepository)(nil).Load), projectRoot)
+}
+
+// Save mocks base method.
+func (m_2 *MockmanifestRepository) Save(projectRoot string, m *manifest.Manifest) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "Save", projectRoot, m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockmanifestRepositoryMockRecorder) Save(projectRoot, m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockmanifestRepository)(nil).Save), projectRoot, m)
+}
+
+// Snapshot mocks base method.
+func (m *MockmanifestRepository) Snapshot(dir string) (map[string]manifest.FileEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot", dir)
+	ret0, _ := ret[0].(map[string]manifest.FileEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockmanifestRepositoryMockRecorder) Snapshot(dir any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockmanifestRepository)(nil).Snapshot), dir)
+}
+
+// MockbackupRepository is a mock of backupRepository interface.
+type MockbackupRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockbackupRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockbackupRepositoryMockRecorder is the mock recorder for MockbackupRepository.
+type MockbackupRepositoryMockRecorder struct {
+	mock *MockbackupRepository
+}
+
+// NewMockbackupRepository creates a new mock instance.
+func NewMockbackupRepository(ctrl *gomock.Controller) *MockbackupRepository {
+	mock := &MockbackupRepository{ctrl: ctrl}
+	mock.recorder = &MockbackupRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockbackupRepository) EXPECT() *MockbackupRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockbackupRepository) Begin(projectRoot, operation string, companions ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []any{projectRoot, operation}
+	for _, a := range companions {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Begin", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockbackupRepositoryMockRecorder) Begin(projectRoot, operation any, companions ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{projectRoot, operation}, companions...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockbackupRepository)(nil).Begin), varargs...)
+}
+
+// Finish mocks base method.
+func (m *MockbackupRepository) Finish() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finish")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Finish indicates an expected call of Finish.
+func (mr *MockbackupRepositoryMockRecorder) Finish() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finish", reflect.TypeOf((*MockbackupRepository)(nil).Finish))
+}
+
+// List mocks base method.
+func (m *MockbackupRepository) List(projectRoot string) ([]*backup.Backup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", projectRoot)
+	ret0, _ := ret[0].([]*backup.Backup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockbackupRepositoryMockRecorder) List(projectRoot any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockbackupRepository)(nil).List), projectRoot)
+}
+
+// MarkCommitted mocks base method.
+func (m *MockbackupRepository) MarkCommitted(repoDir string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MarkCommitted", repoDir)
+}
+
+// MarkCommitted indicates an expected call of MarkCommitted.
+func (mr *MockbackupRepositoryMockRecorder) MarkCommitted(repoDir any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkCommitted", reflect.TypeOf((*MockbackupRepository)(nil).MarkCommitted), repoDir)
+}
+
+// Restore mocks base method.
+func (m *MockbackupRepository) Restore(projectRoot string, force bool) (*backup.Backup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", projectRoot, force)
+	ret0, _ := ret[0].(*backup.Backup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockbackupRepositoryMockRecorder) Restore(projectRoot, force any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockbackupRepository)(nil).Restore), projectRoot, force)
+}
+
+// Rollback mocks base method.
+func (m *MockbackupRepository) Rollback() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rollback indicates an expected call of Rollback.
+func (mr *MockbackupRepositoryMockRecorder) Rollback() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockbackupRepository)(nil).Rollback))
+}
+
 // MockfileService is a mock of fileService interface.
 type MockfileService struct {
 	ctrl     *gomock.Controller
@@ -226,77 +499,196 @@ func (m *MockfileService) EXPECT() *MockfileServiceMockRecorder {
 	return m.recorder
 }
 
-// AreEqual mocks base method.
-func (m *MockfileService) AreEqual(file1, file2 string, overwriteHeaders bool) (bool, error) {
+// CleanupExtraFilesByPatterns mocks base method.
+func (m *MockfileService) CleanupExtraFilesByPatterns(srcFiles []string, srcBase, dstBase string, patterns, ignorePatterns []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AreEqual", file1, file2, overwriteHeaders)
-	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CleanupExtraFilesByPatterns", srcFiles, srcBase, dstBase, patterns, ignorePatterns)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// AreEqual indicates an expected call of AreEqual.
-func (mr *MockfileServiceMockRecorder) AreEqual(file1, file2, overwriteHeaders any) *gomock.Call {
+// CleanupExtraFilesByPatterns indicates an expected call of CleanupExtraFilesByPatterns.
+func (mr *MockfileServiceMockRecorder) CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns, ignorePatterns any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AreEqual", reflect.TypeOf((*MockfileService)(nil).AreEqual), file1, file2, overwriteHeaders)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanupExtraFilesByPatterns", reflect.TypeOf((*MockfileService)(nil).CleanupExtraFilesByPatterns), srcFiles, srcBase, dstBase, patterns, ignorePatterns)
 }
 
-// CleanupExtraFilesByPatterns mocks base method.
-func (m *MockfileService) CleanupExtraFilesByPatterns(srcFiles []string, srcBase, dstBase string, patterns []string) error {
+// FilterFilesByPatterns mocks base method.
+func (m *MockfileService) FilterFilesByPatterns(files []string, baseDir string, patterns, ignorePatterns []string) []string {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CleanupExtraFilesByPatterns", srcFiles, srcBase, dstBase, patterns)
-	ret0, _ := ret[0].(error)
+	ret := m.ctrl.Call(m, "FilterFilesByPatterns", files, baseDir, patterns, ignorePatterns)
+	ret0, _ := ret[0].([]string)
 	return ret0
 }
 
-// CleanupExtraFilesByPatterns indicates an expected call of CleanupExtraFilesByPatterns.
-func (mr *MockfileServiceMockRecorder) CleanupExtraFilesByPatterns(srcFiles, srcBase, dstBase, patterns any) *gomock.Call {
+// FilterFilesByPatterns indicates an expected call of FilterFilesByPatterns.
+func (mr *MockfileServiceMockRecorder) FilterFilesByPatterns(files, baseDir, patterns, ignorePatterns any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanupExtraFilesByPatterns", reflect.TypeOf((*MockfileService)(nil).CleanupExtraFilesByPatterns), srcFiles, srcBase, dstBase, patterns)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterFilesByPatterns", reflect.TypeOf((*MockfileService)(nil).FilterFilesByPatterns), files, baseDir, patterns, ignorePatterns)
 }
 
-// Copy mocks base method.
-func (m *MockfileService) Copy(srcPath, dstPath string, overwriteHeaders bool) error {
+// GetFilePatterns mocks base method.
+func (m *MockfileService) GetFilePatterns(include, exclude []string) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Copy", srcPath, dstPath, overwriteHeaders)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetFilePatterns", include, exclude)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Copy indicates an expected call of Copy.
-func (mr *MockfileServiceMockRecorder) Copy(srcPath, dstPath, overwriteHeaders any) *gomock.Call {
+// GetFilePatterns indicates an expected call of GetFilePatterns.
+func (mr *MockfileServiceMockRecorder) GetFilePatterns(include, exclude any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Copy", reflect.TypeOf((*MockfileService)(nil).Copy), srcPath, dstPath, overwriteHeaders)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilePatterns", reflect.TypeOf((*MockfileService)(nil).GetFilePatterns), include, exclude)
 }
 
-// FindFilesByPatterns mocks base method.
-func (m *MockfileService) FindFilesByPatterns(dir string, patterns []string) ([]string, error) {
+// LoadIgnorePatterns mocks base method.
+func (m *MockfileService) LoadIgnorePatterns(dirs ...string) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindFilesByPatterns", dir, patterns)
+	varargs := []any{}
+	for _, a := range dirs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "LoadIgnorePatterns", varargs...)
 	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindFilesByPatterns indicates an expected call of FindFilesByPatterns.
-func (mr *MockfileServiceMockRecorder) FindFilesByPatterns(dir, patterns any) *gomock.Call {
+// LoadIgnorePatterns indicates an expected call of LoadIgnorePatterns.
+func (mr *MockfileServiceMockRecorder) LoadIgnorePatterns(dirs ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFilesByPatterns", reflect.TypeOf((*MockfileService)(nil).FindFilesByPatterns), dir, patterns)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadIgnorePatterns", reflect.TypeOf((*MockfileService)(nil).LoadIgnorePatterns), dirs...)
 }
 
-// GetFilePatterns mocks base method.
-func (m *MockfileService) GetFilePatterns(flagValue string) ([]string, error) {
+// Sync mocks base method.
+func (m *MockfileService) Sync(srcPath, dstPath string, dstExists, overwriteHeaders bool, mode os.FileMode, normalization models.Normalization) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", srcPath, dstPath, dstExists, overwriteHeaders, mode, normalization)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockfileServiceMockRecorder) Sync(srcPath, dstPath, dstExists, overwriteHeaders, mode, normalization any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockfileService)(nil).Sync), srcPath, dstPath, dstExists, overwriteHeaders, mode, normalization)
+}
+
+// UnmatchedPatterns mocks base method.
+func (m *MockfileService) UnmatchedPatterns(patterns, relativePaths []string) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFilePatterns", flagValue)
+	ret := m.ctrl.Call(m, "UnmatchedPatterns", patterns, relativePaths)
 	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetFilePatterns indicates an expected call of GetFilePatterns.
-func (mr *MockfileServiceMockRecorder) GetFilePatterns(flagValue any) *gomock.Call {
+// UnmatchedPatterns indicates an expected call of UnmatchedPatterns.
+func (mr *MockfileServiceMockRecorder) UnmatchedPatterns(patterns, relativePaths any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmatchedPatterns", reflect.TypeOf((*MockfileService)(nil).UnmatchedPatterns), patterns, relativePaths)
+}
+
+// MockcontentIndex is a mock of contentIndex interface.
+type MockcontentIndex struct {
+	ctrl     *gomock.Controller
+	recorder *MockcontentIndexMockRecorder
+	isgomock struct{}
+}
+
+// MockcontentIndexMockRecorder is the mock recorder for MockcontentIndex.
+type MockcontentIndexMockRecorder struct {
+	mock *MockcontentIndex
+}
+
+// NewMockcontentIndex creates a new mock instance.
+func NewMockcontentIndex(ctrl *gomock.Controller) *MockcontentIndex {
+	mock := &MockcontentIndex{ctrl: ctrl}
+	mock.recorder = &MockcontentIndexMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcontentIndex) EXPECT() *MockcontentIndexMockRecorder {
+	return m.recorder
+}
+
+// Open mocks base method.
+func (m *MockcontentIndex) Open(dirs ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range dirs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Open", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockcontentIndexMockRecorder) Open(dirs ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilePatterns", reflect.TypeOf((*MockfileService)(nil).GetFilePatterns), flagValue)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockcontentIndex)(nil).Open), dirs...)
+}
+
+// Save mocks base method.
+func (m *MockcontentIndex) Save() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockcontentIndexMockRecorder) Save() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockcontentIndex)(nil).Save))
+}
+
+// Mockwatcher is a mock of watcher interface.
+type Mockwatcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockwatcherMockRecorder
+	isgomock struct{}
+}
+
+// MockwatcherMockRecorder is the mock recorder for Mockwatcher.
+type MockwatcherMockRecorder struct {
+	mock *Mockwatcher
+}
+
+// NewMockwatcher creates a new mock instance.
+func NewMockwatcher(ctrl *gomock.Controller) *Mockwatcher {
+	mock := &Mockwatcher{ctrl: ctrl}
+	mock.recorder = &MockwatcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockwatcher) EXPECT() *MockwatcherMockRecorder {
+	return m.recorder
+}
+
+// Watch mocks base method.
+func (m *Mockwatcher) Watch(ctx context.Context, dirs ...string) (<-chan string, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range dirs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Watch", varargs...)
+	ret0, _ := ret[0].(<-chan string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockwatcherMockRecorder) Watch(ctx any, dirs ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, dirs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*Mockwatcher)(nil).Watch), varargs...)
 }
 
 // MockfileOps is a mock of fileOps interface.
@@ -353,6 +745,26 @@ func (mr *MockfileOpsMockRecorder) FindAllFiles(dir any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllFiles", reflect.TypeOf((*MockfileOps)(nil).FindAllFiles), dir)
 }
 
+// FindFiles mocks base method.
+func (m *MockfileOps) FindFiles(dir string, symlinks models.SymlinkMode, linkRoots ...string) (*models.FileScan, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{dir, symlinks}
+	for _, a := range linkRoots {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindFiles", varargs...)
+	ret0, _ := ret[0].(*models.FileScan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindFiles indicates an expected call of FindFiles.
+func (mr *MockfileOpsMockRecorder) FindFiles(dir, symlinks any, linkRoots ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{dir, symlinks}, linkRoots...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFiles", reflect.TypeOf((*MockfileOps)(nil).FindFiles), varargs...)
+}
+
 // GetCurrentDir mocks base method.
 func (m *MockfileOps) GetCurrentDir() (string, error) {
 	m.ctrl.T.Helper()
@@ -382,6 +794,21 @@ func (mr *MockfileOpsMockRecorder) MkdirAll(path, perm any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MkdirAll", reflect.TypeOf((*MockfileOps)(nil).MkdirAll), path, perm)
 }
 
+// ReadLink mocks base method.
+func (m *MockfileOps) ReadLink(filePath string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadLink", filePath)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadLink indicates an expected call of ReadLink.
+func (mr *MockfileOpsMockRecorder) ReadLink(filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadLink", reflect.TypeOf((*MockfileOps)(nil).ReadLink), filePath)
+}
+
 // RemoveFile mocks base method.
 func (m *MockfileOps) RemoveFile(filePath string) error {
 	m.ctrl.T.Helper()
@@ -410,3 +837,17 @@ func (mr *MockfileOpsMockRecorder) Stat(filePath any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockfileOps)(nil).Stat), filePath)
 }
+
+// WriteSymlink mocks base method.
+func (m *MockfileOps) WriteSymlink(target, filePath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteSymlink", target, filePath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteSymlink indicates an expected call of WriteSymlink.
+func (mr *MockfileOpsMockRecorder) WriteSymlink(target, filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteSymlink", reflect.TypeOf((*MockfileOps)(nil).WriteSymlink), target, filePath)
+}
@@ -0,0 +1,161 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
+)
+
+// AddFiles marks project rules files as managed on behalf of the rules directory, so push sends them back
+func (s *SyncService) AddFiles(options *models.SyncOptions, paths []string) error {
+	rulesDir, projectRulesDir, projectRoot, err := s.preparePushPaths(options.RulesDir)
+	if err != nil {
+		return err
+	}
+
+	currentDir, err := s.fileOps.GetCurrentDir()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	relativePaths := make([]string, 0, len(paths))
+	for _, path := range paths {
+		relativePath, err := s.projectRulesFile(path, currentDir, projectRulesDir)
+		if err != nil {
+			return err
+		}
+		relativePaths = append(relativePaths, relativePath)
+	}
+
+	m := s.loadManifest(projectRoot)
+	if m == nil {
+		m = &manifest.Manifest{Files: map[string]manifest.FileEntry{}}
+	}
+	m.Manage(rulesDir, relativePaths...)
+
+	if err := s.manifestRepository.Save(projectRoot, m); err != nil {
+		return fmt.Errorf("failed to save sync manifest: %w", err)
+	}
+
+	for _, relativePath := range relativePaths {
+		s.output.PrintOperation("add", relativePath)
+	}
+	return nil
+}
+
+// projectRulesFile checks that path is an existing file inside project rules directory
+// and returns its slash-separated path relative to the directory
+func (s *SyncService) projectRulesFile(path, currentDir, projectRulesDir string) (string, error) {
+	fullPath := path
+	if !filepath.IsAbs(fullPath) {
+		fullPath = filepath.Join(currentDir, path)
+	}
+
+	relativePath, err := filepath.Rel(projectRulesDir, fullPath)
+	if err != nil || relativePath == "." || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is not inside %s", path, projectRulesDir)
+	}
+
+	info, err := s.fileOps.Stat(fullPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("file %s does not exist", path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check file %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", path)
+	}
+
+	return filepath.ToSlash(relativePath), nil
+}
+
+// Status groups files of project rules directory into managed, unmanaged and orphaned ones and prints them
+func (s *SyncService) Status(options *models.SyncOptions) (*models.StatusResult, error) {
+	rulesSourceDir, destRulesDir, gitRoot, err := s.preparePullPaths(options.RulesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	filePatterns, err := s.fileService.GetFilePatterns(options.FilePatterns, options.ExcludePatterns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file patterns: %w", err)
+	}
+
+	ignorePatterns, err := s.fileService.LoadIgnorePatterns(rulesSourceDir, destRulesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ignore files: %w", err)
+	}
+
+	projectFiles, _, err := s.findFilesWithPatterns(destRulesDir, filePatterns, ignorePatterns, options.Symlinks, rulesSourceDir)
+	if err != nil {
+		return nil, err
+	}
+
+	s.openIndex(rulesSourceDir, destRulesDir)
+	defer s.saveIndex()
+
+	m := s.loadManifest(gitRoot)
+	result := &models.StatusResult{
+		Managed:   []models.FileStatus{},
+		Unmanaged: []models.FileStatus{},
+		Orphaned:  []models.FileStatus{},
+	}
+	for _, projectFile := range projectFiles {
+		relativePath := relativeSlashPath(projectFile, destRulesDir)
+		source, ok := m.Owner(relativePath)
+		if !ok {
+			result.Unmanaged = append(result.Unmanaged, models.FileStatus{RelativePath: relativePath})
+			continue
+		}
+
+		status := models.FileStatus{RelativePath: relativePath, Source: source}
+		exists, err := s.fileOps.FileExists(filepath.Join(source, filepath.FromSlash(relativePath)))
+		if err == nil && exists {
+			status.Linked = s.isLinked(m, projectFile, source, relativePath)
+			result.Managed = append(result.Managed, status)
+		} else {
+			result.Orphaned = append(result.Orphaned, status)
+		}
+	}
+
+	s.printStatus(result)
+	return result, nil
+}
+
+// isLinked checks if project file is a symlink to its source made by pull --link
+func (s *SyncService) isLinked(m *manifest.Manifest, projectFile, source, relativePath string) bool {
+	if m == nil || !m.Linked || m.RulesDir != source {
+		return false
+	}
+
+	target, err := filepath.Abs(filepath.Join(source, filepath.FromSlash(relativePath)))
+	return err == nil && s.linkMatches(projectFile, target)
+}
+
+// printStatus prints files of each ownership group
+func (s *SyncService) printStatus(result *models.StatusResult) {
+	s.output.PrintInfo(fmt.Sprintf("Managed files (%d):", len(result.Managed)))
+	for _, file := range result.Managed {
+		if file.Linked {
+			s.output.PrintInfo(fmt.Sprintf("  %s (linked to %s)", file.RelativePath, file.Source))
+		} else {
+			s.output.PrintInfo(fmt.Sprintf("  %s (from %s)", file.RelativePath, file.Source))
+		}
+	}
+
+	s.output.PrintInfo(fmt.Sprintf("Unmanaged files (%d):", len(result.Unmanaged)))
+	for _, file := range result.Unmanaged {
+		s.output.PrintInfo("  " + file.RelativePath)
+	}
+
+	s.output.PrintInfo(fmt.Sprintf("Orphaned files (%d):", len(result.Orphaned)))
+	for _, file := range result.Orphaned {
+		s.output.PrintInfo(fmt.Sprintf("  %s (missing in %s)", file.RelativePath, file.Source))
+	}
+}
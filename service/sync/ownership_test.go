@@ -0,0 +1,486 @@
+package sync_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
+)
+
+func TestSyncService_AddFiles(t *testing.T) {
+	t.Run("records files as managed", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: "/test/rules",
+		}
+		localFile := filepath.Join(t.TempDir(), "local.mdc")
+		require.NoError(t, os.WriteFile(localFile, []byte("rule"), 0o644))
+		info, err := os.Stat(localFile)
+		require.NoError(t, err)
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testDestRulesDirPush, nil).
+			Times(2)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testDestRulesDirPush).
+			Return(testGitRootPush, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Stat(testDestRulesDirPush+"/local.mdc").
+			Return(info, nil).
+			Times(1)
+
+		files := map[string]manifest.FileEntry{"file1.mdc": {Size: 1, ModTime: 1}}
+		f.manifestMock.EXPECT().
+			Load(testGitRootPush).
+			Return(&manifest.Manifest{
+				RulesDir: "/test/rules",
+				Files:    files,
+				Managed:  map[string]string{"file1.mdc": "/test/rules"},
+			}, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRootPush, &manifest.Manifest{
+				RulesDir: "/test/rules",
+				Files:    files,
+				Managed:  map[string]string{"file1.mdc": "/test/rules", "local.mdc": "/test/rules"},
+			}).
+			Return(nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintOperation("add", "local.mdc").
+			Times(1)
+
+		err = f.syncService.AddFiles(options, []string{"local.mdc"})
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects files outside of project rules directory", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: "/test/rules",
+		}
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testDestRulesDirPush, nil).
+			Times(2)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testDestRulesDirPush).
+			Return(testGitRootPush, nil).
+			Times(1)
+
+		err := f.syncService.AddFiles(options, []string{"../../README.md"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not inside")
+	})
+
+	t.Run("rejects missing files", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: "/test/rules",
+		}
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testDestRulesDirPush, nil).
+			Times(2)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testDestRulesDirPush).
+			Return(testGitRootPush, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Stat(testDestRulesDirPush+"/missing.mdc").
+			Return(nil, os.ErrNotExist).
+			Times(1)
+
+		err := f.syncService.AddFiles(options, []string{"missing.mdc"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not exist")
+	})
+}
+
+func TestSyncService_Status(t *testing.T) {
+	t.Run("groups files by ownership", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: "/test/rules",
+		}
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", testDestRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(testDestRulesDir, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: []string{testDstFile, testDestRulesDir + "/local.mdc", testDestRulesDir + "/old.mdc"}}, nil).
+			Times(1)
+
+		f.expectIndex(testRulesDir, testDestRulesDir)
+
+		f.manifestMock.EXPECT().
+			Load(testGitRoot).
+			Return(&manifest.Manifest{
+				Managed: map[string]string{testRelativePath: "/test/rules", "old.mdc": "/test/rules"},
+			}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FileExists(testSrcFile).
+			Return(true, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FileExists("/test/rules/old.mdc").
+			Return(false, nil).
+			Times(1)
+
+		for _, line := range []string{
+			"Managed files (1):",
+			"  file1.mdc (from /test/rules)",
+			"Unmanaged files (1):",
+			"  local.mdc",
+			"Orphaned files (1):",
+			"  old.mdc (missing in /test/rules)",
+		} {
+			f.outputMock.EXPECT().
+				PrintInfo(line).
+				Times(1)
+		}
+
+		result, err := f.syncService.Status(options)
+		require.NoError(t, err)
+
+		expected := &models.StatusResult{
+			Managed:   []models.FileStatus{{RelativePath: testRelativePath, Source: "/test/rules"}},
+			Unmanaged: []models.FileStatus{{RelativePath: "local.mdc"}},
+			Orphaned:  []models.FileStatus{{RelativePath: "old.mdc", Source: "/test/rules"}},
+		}
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("marks linked files", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: "/test/rules",
+		}
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", testDestRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(testDestRulesDir, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: []string{testDstFile}}, nil).
+			Times(1)
+
+		f.expectIndex(testRulesDir, testDestRulesDir)
+
+		f.manifestMock.EXPECT().
+			Load(testGitRoot).
+			Return(&manifest.Manifest{
+				RulesDir: "/test/rules",
+				Linked:   true,
+				Managed:  map[string]string{testRelativePath: "/test/rules"},
+			}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FileExists(testSrcFile).
+			Return(true, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			ReadLink(testDstFile).
+			Return(testSrcFile, nil).
+			Times(1)
+
+		for _, line := range []string{
+			"Managed files (1):",
+			"  file1.mdc (linked to /test/rules)",
+			"Unmanaged files (0):",
+			"Orphaned files (0):",
+		} {
+			f.outputMock.EXPECT().
+				PrintInfo(line).
+				Times(1)
+		}
+
+		result, err := f.syncService.Status(options)
+		require.NoError(t, err)
+
+		expected := []models.FileStatus{{RelativePath: testRelativePath, Source: "/test/rules", Linked: true}}
+		if diff := cmp.Diff(expected, result.Managed); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestSyncService_PushRules_Ownership(t *testing.T) {
+	t.Run("skips unmanaged files", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: "/test/rules",
+		}
+		rulesSourceDirInProject := testDestRulesDirPush
+		localFile := rulesSourceDirInProject + "/local.mdc"
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDirPush, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDirPush).
+			Return(testGitRootPush, nil).
+			Times(1)
+
+		f.expectBackup(testGitRootPush, "push")
+
+		f.fileOpsMock.EXPECT().
+			FileExists(rulesSourceDirInProject).
+			Return(true, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: []string{testSrcFilePush, localFile}}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll("/test/rules", os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Load(testGitRootPush).
+			Return(&manifest.Manifest{
+				Managed: map[string]string{testRelativePathPush: "/test/rules"},
+			}, nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintWarningf("Skipped %d unmanaged files, use 'cursync add' to push them", 1).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(testSrcFilePush, rulesSourceDirInProject).
+			Return(testRelativePathPush, nil).
+			Times(2)
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles("/test/rules").
+			Return([]string{}, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			RecreateDirectoryStructure(testSrcFilePush, rulesSourceDirInProject, "/test/rules").
+			Return(testDstFilePush, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FileExists(testDstFilePush).
+			Return(true, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			Sync(testSrcFilePush, testDstFilePush, true, false, models.FileModePreserve, models.Normalization{}).
+			Return(false, nil).
+			Times(1)
+
+		result, err := f.syncService.PushRules(options)
+		require.NoError(t, err)
+		require.False(t, result.HasChanges)
+	})
+
+	t.Run("pushes all files without records for the rules directory", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: "/test/rules/",
+		}
+		rulesSourceDirInProject := testDestRulesDirPush
+		localFile := rulesSourceDirInProject + "/local.mdc"
+		localDstFile := "/test/rules/local.mdc"
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDirPush, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDirPush).
+			Return(testGitRootPush, nil).
+			Times(1)
+
+		f.expectBackup(testGitRootPush, "push")
+
+		f.fileOpsMock.EXPECT().
+			FileExists(rulesSourceDirInProject).
+			Return(true, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: []string{testSrcFilePush, localFile}}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll("/test/rules", os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Load(testGitRootPush).
+			Return(&manifest.Manifest{
+				Managed: map[string]string{"other.mdc": "/other/rules"},
+			}, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(testSrcFilePush, rulesSourceDirInProject).
+			Return(testRelativePathPush, nil).
+			Times(2)
+
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(localFile, rulesSourceDirInProject).
+			Return("local.mdc", nil).
+			Times(2)
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles("/test/rules").
+			Return([]string{}, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			RecreateDirectoryStructure(testSrcFilePush, rulesSourceDirInProject, "/test/rules").
+			Return(testDstFilePush, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			RecreateDirectoryStructure(localFile, rulesSourceDirInProject, "/test/rules").
+			Return(localDstFile, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FileExists(testDstFilePush).
+			Return(true, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FileExists(localDstFile).
+			Return(true, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			Sync(testSrcFilePush, testDstFilePush, true, false, models.FileModePreserve, models.Normalization{}).
+			Return(false, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			Sync(localFile, localDstFile, true, false, models.FileModePreserve, models.Normalization{}).
+			Return(false, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRootPush, &manifest.Manifest{
+				Managed: map[string]string{
+					"other.mdc":          "/other/rules",
+					testRelativePathPush: "/test/rules",
+					"local.mdc":          "/test/rules",
+				},
+			}).
+			Return(nil).
+			Times(1)
+
+		result, err := f.syncService.PushRules(options)
+		require.NoError(t, err)
+		require.False(t, result.HasChanges)
+	})
+}
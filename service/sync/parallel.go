@@ -0,0 +1,67 @@
+package sync
+
+import (
+	"runtime"
+	"sort"
+	gosync "sync"
+	"sync/atomic"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+)
+
+// fileJob syncs a single file and returns operation performed on it, nil if the file was already in place
+type fileJob func(file string) (*models.FileOperation, error)
+
+// runFileJobs runs job for every file on up to jobs workers, one per CPU when jobs is 0.
+// Files not started yet are skipped once a job fails. Returns performed operations sorted by relative path,
+// so that output does not depend on scheduling, or error of the first failed file in order of files
+func runFileJobs(files []string, jobs int, job fileJob) ([]models.FileOperation, error) {
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	jobs = min(jobs, len(files))
+
+	operations := make([]*models.FileOperation, len(files))
+	errs := make([]error, len(files))
+	var failed atomic.Bool
+
+	indexes := make(chan int)
+	var wg gosync.WaitGroup
+	for range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if failed.Load() {
+					continue
+				}
+				operations[i], errs[i] = job(files[i])
+				if errs[i] != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	performed := []models.FileOperation{}
+	for _, operation := range operations {
+		if operation != nil {
+			performed = append(performed, *operation)
+		}
+	}
+	sort.SliceStable(performed, func(i, j int) bool {
+		return performed[i].RelativePath < performed[j].RelativePath
+	})
+	return performed, nil
+}
@@ -0,0 +1,158 @@
+package sync_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
+)
+
+func TestSyncService_PullRules_Jobs(t *testing.T) {
+	// Source files are listed out of order to check that operations are sorted
+	relativePaths := []string{"c.mdc", "a.mdc", "b.mdc"}
+
+	// expectCopies sets up pull of source files with given error returned by sync of each relative path
+	expectCopies := func(f *fixture, errs map[string]error) {
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(testRulesDir, testDestRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll(testDestRulesDir, os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Load(testGitRoot).
+			Return(nil, nil).
+			Times(1)
+
+		var sourceFiles []string
+		for _, relativePath := range relativePaths {
+			sourceFiles = append(sourceFiles, filepath.Join(testRulesDir, relativePath))
+		}
+		f.fileOpsMock.EXPECT().
+			FindFiles(testRulesDir, noSymlinks).
+			Return(&models.FileScan{Files: sourceFiles}, nil).
+			Times(1)
+
+		for _, relativePath := range relativePaths {
+			srcFile := filepath.Join(testRulesDir, relativePath)
+			dstFile := filepath.Join(testDestRulesDir, relativePath)
+
+			f.pathUtilsMock.EXPECT().
+				RecreateDirectoryStructure(srcFile, testRulesDir, testDestRulesDir).
+				Return(dstFile, nil).
+				MaxTimes(1)
+
+			f.pathUtilsMock.EXPECT().
+				GetRelativePath(srcFile, testRulesDir).
+				Return(relativePath, nil).
+				MaxTimes(1)
+
+			f.fileOpsMock.EXPECT().
+				Stat(dstFile).
+				Return(nil, os.ErrNotExist).
+				MaxTimes(1)
+
+			f.fileServiceMock.EXPECT().
+				Sync(srcFile, dstFile, false, false, models.FileModePreserve, models.Normalization{}).
+				Return(errs[relativePath] == nil, errs[relativePath]).
+				MaxTimes(1)
+		}
+	}
+
+	t.Run("prints operations in order of relative paths", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:   testRulesDir,
+			DeleteMode: models.DeleteNone,
+			Jobs:       len(relativePaths),
+		}
+		f.expectBackup(testGitRoot, "pull")
+		expectCopies(f, nil)
+
+		gomock.InOrder(
+			f.outputMock.EXPECT().PrintOperation("add", "a.mdc").Times(1),
+			f.outputMock.EXPECT().PrintOperation("add", "b.mdc").Times(1),
+			f.outputMock.EXPECT().PrintOperation("add", "c.mdc").Times(1),
+		)
+
+		f.gitOpsMock.EXPECT().
+			GetHeadCommit(testRulesDir).
+			Return(testHeadCommit, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			HasUncommittedChanges(testRulesDir).
+			Return(false, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(map[string]manifest.FileEntry{}, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRoot, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.True(t, result.HasChanges)
+
+		var got []string
+		for _, operation := range result.Operations {
+			got = append(got, operation.RelativePath)
+		}
+		if diff := cmp.Diff([]string{"a.mdc", "b.mdc", "c.mdc"}, got); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("fails on first failed file without printing operations", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:   testRulesDir,
+			DeleteMode: models.DeleteNone,
+			Jobs:       1,
+		}
+		f.expectRollback(testGitRoot, "pull")
+		expectCopies(f, map[string]error{"a.mdc": errors.New("disk full")})
+
+		_, err := f.syncService.PullRules(options)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to synchronize file a.mdc")
+		require.Contains(t, err.Error(), "disk full")
+	})
+}
@@ -0,0 +1,258 @@
+package sync
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
+	"github.com/yanodintsovmercuryo/cursync/pkg/pattern_matcher"
+)
+
+// pullState holds values shared between incremental and full pull
+type pullState struct {
+	options        *models.SyncOptions
+	rulesSourceDir string
+	destRulesDir   string
+	gitRoot        string
+	filePatterns   []string
+	ignorePatterns []string
+	headCommit     string
+	manifest       *manifest.Manifest
+	syncedFiles    []string
+	sourceLinks    bool // Rules directory contains symlinks
+}
+
+// newPullState creates pullState for pull operation
+func (s *SyncService) newPullState(options *models.SyncOptions, rulesSourceDir, destRulesDir, gitRoot string, filePatterns, ignorePatterns []string) *pullState {
+	return &pullState{
+		options:        options,
+		rulesSourceDir: rulesSourceDir,
+		destRulesDir:   destRulesDir,
+		gitRoot:        gitRoot,
+		filePatterns:   filePatterns,
+		ignorePatterns: ignorePatterns,
+	}
+}
+
+// pullIncremental applies only changes made in rules repository since last pull,
+// returns false when history is unavailable and full scan is required
+func (s *SyncService) pullIncremental(state *pullState) (*models.SyncResult, bool, error) {
+	m := state.manifest
+	if m == nil || !s.manifestMatchesOptions(m, state) {
+		return nil, false, nil
+	}
+
+	state.headCommit = s.currentCommit(state.rulesSourceDir)
+	if state.headCommit == "" {
+		return nil, false, nil
+	}
+
+	snapshot, err := s.manifestRepository.Snapshot(state.destRulesDir)
+	if err != nil || !m.MatchesSnapshot(snapshot) {
+		return nil, false, nil
+	}
+
+	result := &models.SyncResult{
+		Operations: []models.FileOperation{},
+		HasChanges: false,
+	}
+	state.sourceLinks = m.SourceLinks
+	if state.headCommit == m.Commit {
+		return result, true, nil
+	}
+	if followsSymlinks(state.options) && state.sourceLinks {
+		return nil, false, nil
+	}
+
+	changes, err := s.gitOps.GetChangedFiles(state.rulesSourceDir, m.Commit)
+	if err != nil || changesIgnoreFile(changes) {
+		return nil, false, nil
+	}
+
+	changedFiles, deletedFiles := s.splitChangedFiles(changes, state)
+	if followsSymlinks(state.options) && s.hasSymlinks(changedFiles) {
+		return nil, false, nil
+	}
+
+	if _, err := s.removeDeletedFiles(s.deletableFiles(deletedFiles, state), state.rulesSourceDir, state.destRulesDir); err != nil {
+		return nil, true, err
+	}
+
+	state.syncedFiles = changedFiles
+	result, err = s.placeFiles(changedFiles, state.rulesSourceDir, state.destRulesDir, state.options)
+	return result, true, err
+}
+
+// manifestMatchesOptions checks if last pull was made from the same rules directory with the same options
+func (s *SyncService) manifestMatchesOptions(m *manifest.Manifest, state *pullState) bool {
+	return m.Commit != "" &&
+		m.RulesDir == state.rulesSourceDir &&
+		m.FilePatterns == strings.Join(state.filePatterns, ",") &&
+		m.OverwriteHeaders == state.options.OverwriteHeaders &&
+		m.FileMode == uint32(state.options.FileMode) &&
+		m.Symlinks == string(state.options.Symlinks) &&
+		m.DeleteMode == string(state.options.DeleteMode) &&
+		m.LineEndings == string(state.options.Normalization.LineEndings) &&
+		m.AllTextFiles == state.options.Normalization.AllTextFiles &&
+		m.Compare == string(state.options.Normalization.Compare) &&
+		m.Linked == state.options.Link
+}
+
+// followsSymlinks checks if symlinks are copied by content, changes of their targets are not reported
+// for link paths so full scan is required when rules directory has links
+func followsSymlinks(options *models.SyncOptions) bool {
+	return options.Symlinks == "" || options.Symlinks == models.SymlinkFollow
+}
+
+// hasSymlinks checks if any of changed files is a symlink, links added since the last full scan
+// are not recorded in manifest
+func (s *SyncService) hasSymlinks(changedFiles []string) bool {
+	for _, srcFile := range changedFiles {
+		if _, err := s.fileOps.ReadLink(srcFile); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// currentCommit returns HEAD commit of rules directory or empty string if it is not a clean git repository
+func (s *SyncService) currentCommit(rulesDir string) string {
+	headCommit, err := s.gitOps.GetHeadCommit(rulesDir)
+	if err != nil {
+		return ""
+	}
+
+	dirty, err := s.gitOps.HasUncommittedChanges(rulesDir)
+	if err != nil || dirty {
+		return ""
+	}
+
+	return headCommit
+}
+
+// splitChangedFiles splits changes into changed and deleted source files matching patterns
+func (s *SyncService) splitChangedFiles(changes []models.ChangedFile, state *pullState) ([]string, []string) {
+	changedFiles := []string{}
+	deletedFiles := []string{}
+	for _, change := range changes {
+		srcFileFullPath := filepath.Join(state.rulesSourceDir, change.RelativePath)
+		if change.Type == models.OperationDelete {
+			deletedFiles = append(deletedFiles, srcFileFullPath)
+		} else {
+			changedFiles = append(changedFiles, srcFileFullPath)
+		}
+	}
+
+	if len(state.filePatterns) > 0 || len(state.ignorePatterns) > 0 {
+		changedFiles = s.fileService.FilterFilesByPatterns(changedFiles, state.rulesSourceDir, state.filePatterns, state.ignorePatterns)
+		deletedFiles = s.fileService.FilterFilesByPatterns(deletedFiles, state.rulesSourceDir, state.filePatterns, state.ignorePatterns)
+	}
+
+	return changedFiles, deletedFiles
+}
+
+// changesIgnoreFile checks if ignore file of rules directory was changed, which may select previously ignored files
+func changesIgnoreFile(changes []models.ChangedFile) bool {
+	for _, change := range changes {
+		if change.RelativePath == pattern_matcher.IgnoreFileName {
+			return true
+		}
+	}
+	return false
+}
+
+// deletableFiles returns source files deleted in history whose destination copies may be removed in delete mode
+func (s *SyncService) deletableFiles(deletedFiles []string, state *pullState) []string {
+	switch state.options.DeleteMode {
+	case models.DeleteNone:
+		return nil
+	case models.DeleteManaged:
+		managed := state.manifest.ManagedFiles(state.rulesSourceDir)
+		deletable := []string{}
+		for _, srcFile := range deletedFiles {
+			if managed[relativeSlashPath(srcFile, state.rulesSourceDir)] {
+				deletable = append(deletable, srcFile)
+			}
+		}
+		return deletable
+	default:
+		return deletedFiles
+	}
+}
+
+// removeDeletedFiles removes destination copies of files deleted in source, returns relative paths of removed files
+func (s *SyncService) removeDeletedFiles(deletedFiles []string, srcBase, dstBase string) ([]string, error) {
+	removed := []string{}
+	for _, srcFileFullPath := range deletedFiles {
+		relativePath, err := s.pathUtils.GetRelativePath(srcFileFullPath, srcBase)
+		if err != nil {
+			continue
+		}
+
+		dstFileFullPath := filepath.Join(dstBase, relativePath)
+		exists, err := s.fileOps.FileExists(dstFileFullPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check destination file %s: %w", relativePath, err)
+		}
+		if !exists {
+			continue
+		}
+
+		if err := s.fileOps.RemoveFile(dstFileFullPath); err != nil {
+			return nil, fmt.Errorf("failed to delete file %s: %w", relativePath, err)
+		}
+		s.output.PrintOperation("delete", relativePath)
+		removed = append(removed, filepath.ToSlash(relativePath))
+	}
+	return removed, nil
+}
+
+// recordPulledFiles carries ownership recorded by previous syncs over to the manifest and marks pulled files
+// as managed, files missing in destination are forgotten
+func (s *SyncService) recordPulledFiles(m *manifest.Manifest, state *pullState, snapshot map[string]manifest.FileEntry) {
+	if state.manifest != nil {
+		for relativePath, rulesDir := range state.manifest.Managed {
+			m.Manage(rulesDir, relativePath)
+		}
+	}
+	for _, srcFile := range state.syncedFiles {
+		m.Manage(state.rulesSourceDir, relativeSlashPath(srcFile, state.rulesSourceDir))
+	}
+	m.RetainManaged(snapshot)
+}
+
+// saveManifest records state of project rules directory after pull
+func (s *SyncService) saveManifest(state *pullState) {
+	commit := state.headCommit
+	if commit == "" {
+		commit = s.currentCommit(state.rulesSourceDir)
+	}
+
+	snapshot, err := s.manifestRepository.Snapshot(state.destRulesDir)
+	if err != nil {
+		s.output.PrintWarningf("Failed to save sync manifest: %v", err)
+		return
+	}
+
+	m := &manifest.Manifest{
+		RulesDir:         state.rulesSourceDir,
+		Commit:           commit,
+		FilePatterns:     strings.Join(state.filePatterns, ","),
+		OverwriteHeaders: state.options.OverwriteHeaders,
+		FileMode:         uint32(state.options.FileMode),
+		Symlinks:         string(state.options.Symlinks),
+		DeleteMode:       string(state.options.DeleteMode),
+		LineEndings:      string(state.options.Normalization.LineEndings),
+		AllTextFiles:     state.options.Normalization.AllTextFiles,
+		Compare:          string(state.options.Normalization.Compare),
+		Linked:           state.options.Link,
+		SourceLinks:      state.sourceLinks,
+		Files:            snapshot,
+	}
+	s.recordPulledFiles(m, state, snapshot)
+	if err := s.manifestRepository.Save(state.gitRoot, m); err != nil {
+		s.output.PrintWarningf("Failed to save sync manifest: %v", err)
+	}
+}
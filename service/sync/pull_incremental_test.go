@@ -0,0 +1,497 @@
+package sync_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
+)
+
+const (
+	testRulesDir     = "/test/rules"
+	testLastCommit   = "abc123"
+	testHeadCommit   = "def456"
+	testDeletedFile  = "/test/rules/old.mdc"
+	testDeletedDst   = "/test/git/.cursor/rules/old.mdc"
+	testDeletedRel   = "old.mdc"
+	testLocalFileRel = "local.mdc"
+)
+
+func TestSyncService_PullRules_Incremental(t *testing.T) {
+	snapshot := map[string]manifest.FileEntry{
+		testRelativePath: {Size: 10, ModTime: 100},
+		testDeletedRel:   {Size: 20, ModTime: 200},
+	}
+
+	expectPullPrelude := func(f *fixture, m *manifest.Manifest) {
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		f.expectBackup(testGitRoot, "pull")
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(testRulesDir, testDestRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll(testDestRulesDir, os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Load(testGitRoot).
+			Return(m, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetHeadCommit(testRulesDir).
+			Return(testHeadCommit, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			HasUncommittedChanges(testRulesDir).
+			Return(false, nil).
+			Times(1)
+	}
+
+	expectManifestSaved := func(f *fixture, files map[string]manifest.FileEntry, managed map[string]string) {
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(files, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRoot, &manifest.Manifest{
+				RulesDir: testRulesDir,
+				Commit:   testHeadCommit,
+				Files:    files,
+				Managed:  managed,
+			}).
+			Return(nil).
+			Times(1)
+	}
+
+	t.Run("applies only changes since last pull", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: testRulesDir,
+		}
+		expectPullPrelude(f, &manifest.Manifest{
+			RulesDir: testRulesDir,
+			Commit:   testLastCommit,
+			Files:    snapshot,
+		})
+
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(snapshot, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetChangedFiles(testRulesDir, testLastCommit).
+			Return([]models.ChangedFile{
+				{Type: models.OperationUpdate, RelativePath: testRelativePath},
+				{Type: models.OperationDelete, RelativePath: testDeletedRel},
+			}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			ReadLink(testSrcFile).
+			Return("", os.ErrInvalid).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(testDeletedFile, testRulesDir).
+			Return(testDeletedRel, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FileExists(testDeletedDst).
+			Return(true, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			RemoveFile(testDeletedDst).
+			Return(nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintOperation("delete", testDeletedRel).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			RecreateDirectoryStructure(testSrcFile, testRulesDir, testDestRulesDir).
+			Return(testDstFile, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(testSrcFile, testRulesDir).
+			Return(testRelativePath, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Stat(testDstFile).
+			Return(nil, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			Sync(testSrcFile, testDstFile, true, false, models.FileModePreserve, models.Normalization{}).
+			Return(true, nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintOperation("update", testRelativePath).
+			Times(1)
+
+		expectManifestSaved(f, map[string]manifest.FileEntry{
+			testRelativePath: {Size: 11, ModTime: 300},
+		}, map[string]string{testRelativePath: testRulesDir})
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.True(t, result.HasChanges)
+		require.Len(t, result.Operations, 1)
+
+		if diff := cmp.Diff(models.OperationUpdate, result.Operations[0].Type); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("nothing to do when head is unchanged", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: testRulesDir,
+		}
+		expectPullPrelude(f, &manifest.Manifest{
+			RulesDir: testRulesDir,
+			Commit:   testHeadCommit,
+			Files:    snapshot,
+		})
+
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(snapshot, nil).
+			Times(1)
+
+		expectManifestSaved(f, snapshot, nil)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.False(t, result.HasChanges)
+		require.Empty(t, result.Operations)
+	})
+
+	t.Run("falls back to full scan when project has local modifications", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: testRulesDir,
+		}
+		expectPullPrelude(f, &manifest.Manifest{
+			RulesDir: testRulesDir,
+			Commit:   testLastCommit,
+			Files:    snapshot,
+		})
+
+		modified := map[string]manifest.FileEntry{
+			testRelativePath: {Size: 10, ModTime: 100},
+			testDeletedRel:   {Size: 20, ModTime: 200},
+			testLocalFileRel: {Size: 5, ModTime: 500},
+		}
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(modified, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(testRulesDir, noSymlinks).
+			Return(&models.FileScan{Files: []string{}}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles(testDestRulesDir).
+			Return([]string{}, nil).
+			Times(1)
+
+		expectManifestSaved(f, modified, nil)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.False(t, result.HasChanges)
+	})
+
+	t.Run("falls back to full scan when line endings changed", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:      testRulesDir,
+			Normalization: models.Normalization{LineEndings: models.LineEndingsCRLF, Compare: models.CompareNormalized},
+		}
+		expectPullPrelude(f, &manifest.Manifest{
+			RulesDir:    testRulesDir,
+			Commit:      testLastCommit,
+			Files:       snapshot,
+			LineEndings: string(models.LineEndingsLF),
+			Compare:     string(models.CompareNormalized),
+		})
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(testRulesDir, noSymlinks).
+			Return(&models.FileScan{Files: []string{}}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles(testDestRulesDir).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(snapshot, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRoot, &manifest.Manifest{
+				RulesDir:    testRulesDir,
+				Commit:      testHeadCommit,
+				Files:       snapshot,
+				LineEndings: string(models.LineEndingsCRLF),
+				Compare:     string(models.CompareNormalized),
+			}).
+			Return(nil).
+			Times(1)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.False(t, result.HasChanges)
+	})
+
+	t.Run("falls back to full scan when delete mode changed", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:   testRulesDir,
+			DeleteMode: models.DeleteAll,
+		}
+		expectPullPrelude(f, &manifest.Manifest{
+			RulesDir:   testRulesDir,
+			Commit:     testLastCommit,
+			DeleteMode: string(models.DeleteNone),
+			Files:      snapshot,
+		})
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(testRulesDir, noSymlinks).
+			Return(&models.FileScan{Files: []string{}}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles(testDestRulesDir).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(snapshot, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRoot, &manifest.Manifest{
+				RulesDir:   testRulesDir,
+				Commit:     testHeadCommit,
+				DeleteMode: string(models.DeleteAll),
+				Files:      snapshot,
+			}).
+			Return(nil).
+			Times(1)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.False(t, result.HasChanges)
+	})
+
+	t.Run("falls back to full scan when ignore file changed", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: testRulesDir,
+		}
+		expectPullPrelude(f, &manifest.Manifest{
+			RulesDir: testRulesDir,
+			Commit:   testLastCommit,
+			Files:    snapshot,
+		})
+
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(snapshot, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetChangedFiles(testRulesDir, testLastCommit).
+			Return([]models.ChangedFile{
+				{Type: models.OperationUpdate, RelativePath: ".cursyncignore"},
+			}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(testRulesDir, noSymlinks).
+			Return(&models.FileScan{Files: []string{}}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles(testDestRulesDir).
+			Return([]string{}, nil).
+			Times(1)
+
+		expectManifestSaved(f, snapshot, nil)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.False(t, result.HasChanges)
+	})
+	t.Run("falls back to full scan when followed symlinks exist", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: testRulesDir,
+			Symlinks: models.SymlinkFollow,
+		}
+		expectPullPrelude(f, &manifest.Manifest{
+			RulesDir:    testRulesDir,
+			Commit:      testLastCommit,
+			Files:       snapshot,
+			Symlinks:    string(models.SymlinkFollow),
+			SourceLinks: true,
+		})
+
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(snapshot, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(testRulesDir, models.SymlinkFollow).
+			Return(&models.FileScan{Files: []string{}, Links: true}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles(testDestRulesDir).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(snapshot, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRoot, &manifest.Manifest{
+				RulesDir:    testRulesDir,
+				Commit:      testHeadCommit,
+				Files:       snapshot,
+				Symlinks:    string(models.SymlinkFollow),
+				SourceLinks: true,
+			}).
+			Return(nil).
+			Times(1)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.False(t, result.HasChanges)
+	})
+
+	t.Run("falls back to full scan when changed file is a followed symlink", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: testRulesDir,
+		}
+		expectPullPrelude(f, &manifest.Manifest{
+			RulesDir: testRulesDir,
+			Commit:   testLastCommit,
+			Files:    snapshot,
+		})
+
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(snapshot, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetChangedFiles(testRulesDir, testLastCommit).
+			Return([]models.ChangedFile{
+				{Type: models.OperationAdd, RelativePath: testRelativePath},
+			}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			ReadLink(testSrcFile).
+			Return("shared/file1.mdc", nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(testRulesDir, noSymlinks).
+			Return(&models.FileScan{Files: []string{}, Links: true}, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles(testDestRulesDir).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(snapshot, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRoot, &manifest.Manifest{
+				RulesDir:    testRulesDir,
+				Commit:      testHeadCommit,
+				Files:       snapshot,
+				SourceLinks: true,
+			}).
+			Return(nil).
+			Times(1)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.False(t, result.HasChanges)
+	})
+}
@@ -4,54 +4,105 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/yanodintsovmercuryo/cursync/models"
 	"github.com/yanodintsovmercuryo/cursync/pkg/string_utils"
 )
 
-// PullRules pulls rules from source directory to project .cursor/rules directory
+// PullRules pulls rules from source directory to project .cursor/rules directory,
+// on failure all changes made to the project are rolled back
 func (s *SyncService) PullRules(options *models.SyncOptions) (*models.SyncResult, error) {
-	rulesSourceDir, destRulesDir, err := s.preparePullPaths(options.RulesDir)
+	rulesSourceDir, destRulesDir, gitRoot, err := s.preparePullPaths(options.RulesDir)
 	if err != nil {
 		return nil, err
 	}
 
-	filePatterns, err := s.fileService.GetFilePatterns(options.FilePatterns)
+	if err := s.beginBackup(gitRoot, "pull"); err != nil {
+		return nil, err
+	}
+	s.openIndex(rulesSourceDir, destRulesDir)
+	defer s.saveIndex()
+
+	result, err := s.pullFiles(options, rulesSourceDir, destRulesDir, gitRoot)
+	if err != nil {
+		return nil, s.rollback(err)
+	}
+
+	s.finishBackup()
+	return result, nil
+}
+
+// pullFiles synchronizes project rules directory with source directory, stops at the first failed file
+func (s *SyncService) pullFiles(options *models.SyncOptions, rulesSourceDir, destRulesDir, gitRoot string) (*models.SyncResult, error) {
+	filePatterns, err := s.fileService.GetFilePatterns(options.FilePatterns, options.ExcludePatterns)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file patterns: %w", err)
 	}
 
+	ignorePatterns, err := s.fileService.LoadIgnorePatterns(rulesSourceDir, destRulesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ignore files: %w", err)
+	}
+
 	if mkdirErr := s.fileOps.MkdirAll(destRulesDir, os.ModePerm); mkdirErr != nil {
 		return nil, fmt.Errorf("failed to create destination directory %s: %w", destRulesDir, mkdirErr)
 	}
 
-	sourceFiles, err := s.findFilesWithPatterns(rulesSourceDir, filePatterns)
+	state := s.newPullState(options, rulesSourceDir, destRulesDir, gitRoot, filePatterns, ignorePatterns)
+	state.manifest = s.loadManifest(gitRoot)
+
+	if result, ok, err := s.pullIncremental(state); err != nil {
+		return nil, err
+	} else if ok {
+		s.saveManifest(state)
+		return result, nil
+	}
+
+	sourceFiles, scan, err := s.findFilesWithPatterns(rulesSourceDir, filePatterns, ignorePatterns, options.Symlinks)
 	if err != nil {
 		return nil, err
 	}
+	state.sourceLinks = scan.Links
 
-	if err := s.cleanupExtraFilesWithPatterns(sourceFiles, rulesSourceDir, destRulesDir, filePatterns); err != nil {
+	s.warnUnmatchedPatterns(filePatterns, relativeSlashPaths(scan.Files, rulesSourceDir), destRulesDir)
+
+	managed := state.manifest.ManagedFiles(rulesSourceDir)
+	if _, err := s.cleanupDestination(options.DeleteMode, managed, sourceFiles, rulesSourceDir, destRulesDir, filePatterns, ignorePatterns); err != nil {
 		return nil, err
 	}
 
-	return s.copyFiles(sourceFiles, rulesSourceDir, destRulesDir, options.OverwriteHeaders), nil
+	if !options.Link && state.manifest != nil && state.manifest.Linked {
+		if err := s.unlinkFiles(sourceFiles, rulesSourceDir, destRulesDir); err != nil {
+			return nil, err
+		}
+	}
+
+	state.syncedFiles = sourceFiles
+	result, err := s.placeFiles(sourceFiles, rulesSourceDir, destRulesDir, options)
+	if err != nil {
+		return nil, err
+	}
+	s.saveManifest(state)
+
+	return result, nil
 }
 
 // preparePullPaths prepares source and destination paths for pull operation
-func (s *SyncService) preparePullPaths(rulesDir string) (string, string, error) {
+func (s *SyncService) preparePullPaths(rulesDir string) (string, string, string, error) {
 	rulesSourceDir, err := s.getRulesSourceDir(rulesDir)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get rules source dir: %w", err)
+		return "", "", "", fmt.Errorf("failed to get rules source dir: %w", err)
 	}
 
 	currentDir, err := s.fileOps.GetCurrentDir()
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get current directory: %w", err)
+		return "", "", "", fmt.Errorf("failed to get current directory: %w", err)
 	}
 
 	gitRoot, err := s.gitOps.GetGitRootDir(currentDir)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to find git root: %w", err)
+		return "", "", "", fmt.Errorf("failed to find git root: %w", err)
 	}
 
 	const (
@@ -60,125 +111,132 @@ func (s *SyncService) preparePullPaths(rulesDir string) (string, string, error)
 	)
 	destRulesDir := filepath.Join(gitRoot, cursorDirName, rulesDirName)
 
-	return rulesSourceDir, destRulesDir, nil
+	return rulesSourceDir, destRulesDir, gitRoot, nil
+}
+
+// findFilesWithPatterns finds files using patterns and ignore files, returns selected files and the scan of directory,
+// symlinks are handled by symlinks mode and links into linkRoots are followed, links pointing outside are skipped with a warning
+func (s *SyncService) findFilesWithPatterns(dir string, patterns, ignorePatterns []string, symlinks models.SymlinkMode, linkRoots ...string) ([]string, *models.FileScan, error) {
+	scan, err := s.fileOps.FindFiles(dir, symlinks, linkRoots...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find source files in %s: %w", dir, err)
+	}
+	for _, link := range scan.Skipped {
+		s.output.PrintWarningf("Skipped symlink %s pointing outside %s", link, dir)
+	}
+	if len(patterns) == 0 && len(ignorePatterns) == 0 {
+		return scan.Files, scan, nil
+	}
+
+	return s.fileService.FilterFilesByPatterns(scan.Files, dir, patterns, ignorePatterns), scan, nil
 }
 
-// findFilesWithPatterns finds files using patterns or returns all files if patterns are empty
-func (s *SyncService) findFilesWithPatterns(dir string, patterns []string) ([]string, error) {
+// warnUnmatchedPatterns warns about patterns matching no file of either synced tree, which usually means a typo.
+// Scanned holds relative paths of files found in the tree synced from, files of otherDir are listed here,
+// so that a pattern selecting files of only one side is not reported
+func (s *SyncService) warnUnmatchedPatterns(patterns []string, scanned []string, otherDir string) {
 	if len(patterns) == 0 {
-		files, err := s.fileOps.FindAllFiles(dir)
-		if err != nil {
-			return nil, fmt.Errorf("failed to find source files in %s: %w", dir, err)
-		}
-		return files, nil
+		return
 	}
 
-	files, err := s.fileService.FindFilesByPatterns(dir, patterns)
+	otherFiles, err := s.fileOps.FindAllFiles(otherDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find files by patterns in %s: %w", dir, err)
+		s.output.PrintWarningf("Failed to check file patterns: %v", err)
+		return
+	}
+	relativePaths := append(append([]string{}, scanned...), relativeSlashPaths(otherFiles, otherDir)...)
+
+	unmatched, err := s.fileService.UnmatchedPatterns(patterns, relativePaths)
+	if err != nil {
+		s.output.PrintWarningf("Failed to check file patterns: %v", err)
+		return
+	}
+	if len(unmatched) > 0 {
+		s.output.PrintWarningf("Patterns matched no files: %s", strings.Join(unmatched, ", "))
 	}
-	return files, nil
 }
 
 // cleanupExtraFilesWithPatterns cleans up extra files using pattern-aware or simple cleanup
-func (s *SyncService) cleanupExtraFilesWithPatterns(sourceFiles []string, srcBase, dstBase string, patterns []string) error {
+func (s *SyncService) cleanupExtraFilesWithPatterns(sourceFiles []string, srcBase, dstBase string, patterns, ignorePatterns []string) error {
 	effectivePatterns := string_utils.RemoveDuplicates(patterns)
-	if len(effectivePatterns) == 0 {
+	if len(effectivePatterns) == 0 && len(ignorePatterns) == 0 {
 		if err := s.cleanupExtraFiles(sourceFiles, srcBase, dstBase); err != nil {
 			return fmt.Errorf("failed to cleanup extra files: %w", err)
 		}
 		return nil
 	}
 
-	if err := s.fileService.CleanupExtraFilesByPatterns(sourceFiles, srcBase, dstBase, effectivePatterns); err != nil {
+	if err := s.fileService.CleanupExtraFilesByPatterns(sourceFiles, srcBase, dstBase, effectivePatterns, ignorePatterns); err != nil {
 		return fmt.Errorf("failed to cleanup extra files: %w", err)
 	}
 	return nil
 }
 
-// copyFiles copies files from source to destination with proper directory structure
-func (s *SyncService) copyFiles(sourceFiles []string, srcBase, dstBase string, overwriteHeaders bool) *models.SyncResult {
-	result := &models.SyncResult{
-		Operations: []models.FileOperation{},
-		HasChanges: false,
+// copyFiles copies files from source to destination with proper directory structure,
+// files are compared and copied by options.Jobs workers and operations are printed in order of relative paths
+func (s *SyncService) copyFiles(sourceFiles []string, srcBase, dstBase string, options *models.SyncOptions) (*models.SyncResult, error) {
+	operations, err := runFileJobs(sourceFiles, options.Jobs, func(srcFileFullPath string) (*models.FileOperation, error) {
+		return s.syncFile(srcFileFullPath, srcBase, dstBase, options, s.checkFileExists)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	for _, srcFileFullPath := range sourceFiles {
-		dstFileFullPath, err := s.pathUtils.RecreateDirectoryStructure(srcFileFullPath, srcBase, dstBase)
-		if err != nil {
-			s.output.PrintErrorf("Error recreating directory structure for %s: %v\n", srcFileFullPath, err)
-			continue
-		}
-
-		relativePath, err := s.pathUtils.GetRelativePath(srcFileFullPath, srcBase)
-		if err != nil {
-			relativePath = s.pathUtils.GetBaseName(srcFileFullPath)
-		}
-
-		fileExistedBeforeCopy, err := s.checkFileExists(dstFileFullPath, relativePath)
-		if err != nil {
-			continue
-		}
-
-		shouldCopy := s.shouldCopyFile(srcFileFullPath, dstFileFullPath, fileExistedBeforeCopy, overwriteHeaders, relativePath)
-		if !shouldCopy {
-			continue
-		}
-
-		if s.copySingleFile(srcFileFullPath, dstFileFullPath, relativePath, fileExistedBeforeCopy, overwriteHeaders, result) {
-			result.HasChanges = true
-		}
+	for _, operation := range operations {
+		s.output.PrintOperation(string(operation.Type), operation.RelativePath)
 	}
-
-	return result
+	return &models.SyncResult{Operations: operations, HasChanges: len(operations) > 0}, nil
 }
 
-// checkFileExists checks if destination file exists
-func (s *SyncService) checkFileExists(dstFileFullPath, relativePath string) (bool, error) {
-	if _, err := s.fileOps.Stat(dstFileFullPath); os.IsNotExist(err) {
-		return false, nil
-	} else if err != nil {
-		s.output.PrintErrorf("Error checking destination file %s: %v\n", relativePath, err)
-		return false, err
+// syncFile copies a single file to destination unless it is already in place, fileExists checks destination file.
+// Returns operation performed on the file, nil if nothing was copied
+func (s *SyncService) syncFile(srcFileFullPath, srcBase, dstBase string, options *models.SyncOptions, fileExists func(dstFileFullPath, relativePath string) (bool, error)) (*models.FileOperation, error) {
+	dstFileFullPath, err := s.pathUtils.RecreateDirectoryStructure(srcFileFullPath, srcBase, dstBase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recreate directory structure for %s: %w", srcFileFullPath, err)
 	}
-	return true, nil
-}
 
-// shouldCopyFile determines if file should be copied based on comparison
-func (s *SyncService) shouldCopyFile(srcFileFullPath, dstFileFullPath string, fileExistedBeforeCopy, overwriteHeaders bool, relativePath string) bool {
-	if !fileExistedBeforeCopy {
-		return true
+	relativePath, err := s.pathUtils.GetRelativePath(srcFileFullPath, srcBase)
+	if err != nil {
+		relativePath = s.pathUtils.GetBaseName(srcFileFullPath)
 	}
 
-	equal, err := s.fileService.AreEqual(srcFileFullPath, dstFileFullPath, overwriteHeaders)
+	fileExistedBeforeCopy, err := fileExists(dstFileFullPath, relativePath)
 	if err != nil {
-		s.output.PrintErrorf("Error comparing files %s: %v\n", relativePath, err)
-		return true
+		return nil, err
 	}
 
-	return !equal
-}
+	link, isLink := s.sourceLink(srcFileFullPath, dstBase, options)
+	if isLink && options.Symlinks == models.SymlinkSkip {
+		return nil, nil
+	}
 
-// copySingleFile copies a single file and updates result
-func (s *SyncService) copySingleFile(srcFileFullPath, dstFileFullPath, relativePath string, fileExistedBeforeCopy, overwriteHeaders bool, result *models.SyncResult) bool {
-	copyErr := s.fileService.Copy(srcFileFullPath, dstFileFullPath, overwriteHeaders)
-	if copyErr != nil {
-		s.output.PrintErrorf("Error synchronizing file %s: %v\n", relativePath, copyErr)
-		return false
+	copied, err := s.copyFile(srcFileFullPath, dstFileFullPath, link, fileExistedBeforeCopy, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to synchronize file %s to %s: %w", relativePath, dstBase, err)
+	}
+	if !copied {
+		return nil, nil
 	}
 
-	operationType := "update"
+	operationType := models.OperationUpdate
 	if !fileExistedBeforeCopy {
-		operationType = "add"
+		operationType = models.OperationAdd
 	}
-	s.output.PrintOperation(operationType, relativePath)
-
-	result.Operations = append(result.Operations, models.FileOperation{
-		Type:         models.OperationType(operationType),
+	return &models.FileOperation{
+		Type:         operationType,
 		SourcePath:   srcFileFullPath,
 		TargetPath:   dstFileFullPath,
 		RelativePath: relativePath,
-	})
+	}, nil
+}
 
-	return true
+// checkFileExists checks if destination file exists
+func (s *SyncService) checkFileExists(dstFileFullPath, relativePath string) (bool, error) {
+	if _, err := s.fileOps.Stat(dstFileFullPath); os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to check destination file %s: %w", relativePath, err)
+	}
+	return true, nil
 }
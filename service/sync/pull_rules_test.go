@@ -7,8 +7,10 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 
 	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
 )
 
 const (
@@ -91,7 +93,7 @@ func TestSyncService_PullRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:     "/test/rules",
-			FilePatterns: "",
+			FilePatterns: nil,
 		}
 		currentDir := testCurrentDir
 		gitRoot := testGitRoot
@@ -106,10 +108,12 @@ func TestSyncService_PullRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "pull")
+
 		expectedErr := errors.New("file patterns error")
 
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return(nil, expectedErr).
 			Times(1)
 
@@ -126,7 +130,7 @@ func TestSyncService_PullRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:     "/test/rules",
-			FilePatterns: "",
+			FilePatterns: nil,
 		}
 		currentDir := testCurrentDir
 		gitRoot := testGitRoot
@@ -142,11 +146,18 @@ func TestSyncService_PullRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "pull")
+
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", destRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
 		expectedErr := errors.New("mkdir error")
 
 		f.fileOpsMock.EXPECT().
@@ -167,7 +178,7 @@ func TestSyncService_PullRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:     "/test/rules",
-			FilePatterns: "",
+			FilePatterns: nil,
 		}
 		currentDir := testCurrentDir
 		gitRoot := testGitRoot
@@ -183,20 +194,32 @@ func TestSyncService_PullRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "pull")
+
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", destRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
 			MkdirAll(destRulesDir, os.ModePerm).
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(gitRoot).
+			Return(nil, nil).
+			Times(1)
+
 		expectedErr := errors.New("find files error")
 
 		f.fileOpsMock.EXPECT().
-			FindAllFiles("/test/rules").
+			FindFiles("/test/rules", noSymlinks).
 			Return(nil, expectedErr).
 			Times(1)
 
@@ -206,14 +229,13 @@ func TestSyncService_PullRules(t *testing.T) {
 		require.Nil(t, result)
 	})
 
-	t.Run("error finding source files with patterns", func(t *testing.T) {
+	t.Run("warns about symlinks pointing outside rules directory", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
 		options := &models.SyncOptions{
-			RulesDir:     "/test/rules",
-			FilePatterns: "*.mdc",
+			RulesDir: "/test/rules",
 		}
 		currentDir := testCurrentDir
 		gitRoot := testGitRoot
@@ -229,9 +251,16 @@ func TestSyncService_PullRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "pull")
+
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("*.mdc").
-			Return([]string{"*.mdc"}, nil).
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", destRulesDir).
+			Return(noPatterns, nil).
 			Times(1)
 
 		f.fileOpsMock.EXPECT().
@@ -239,32 +268,44 @@ func TestSyncService_PullRules(t *testing.T) {
 			Return(nil).
 			Times(1)
 
-		expectedErr := errors.New("find files by patterns error")
+		f.manifestMock.EXPECT().
+			Load(gitRoot).
+			Return(nil, nil).
+			Times(1)
 
-		f.fileServiceMock.EXPECT().
-			FindFilesByPatterns("/test/rules", []string{"*.mdc"}).
+		f.fileOpsMock.EXPECT().
+			FindFiles("/test/rules", noSymlinks).
+			Return(&models.FileScan{Links: true, Skipped: []string{"/test/rules/secret.mdc"}}, nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintWarningf("Skipped symlink %s pointing outside %s", "/test/rules/secret.mdc", "/test/rules").
+			Times(1)
+
+		expectedErr := errors.New("walk error")
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles(destRulesDir).
 			Return(nil, expectedErr).
 			Times(1)
 
 		result, err := f.syncService.PullRules(options)
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "failed to find files by patterns")
+		require.ErrorIs(t, err, expectedErr)
 		require.Nil(t, result)
 	})
 
-	t.Run("error cleaning up extra files", func(t *testing.T) {
+	t.Run("warns about patterns matching no files", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
 		options := &models.SyncOptions{
 			RulesDir:     "/test/rules",
-			FilePatterns: "*.mdc",
+			FilePatterns: []string{"*.mdc"},
 		}
 		currentDir := testCurrentDir
 		gitRoot := testGitRoot
 		destRulesDir := testDestRulesDir
-		sourceFiles := []string{testSrcFile}
 
 		f.fileOpsMock.EXPECT().
 			GetCurrentDir().
@@ -276,51 +317,79 @@ func TestSyncService_PullRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "pull")
+
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("*.mdc").
+			GetFilePatterns([]string{"*.mdc"}, noPatterns).
 			Return([]string{"*.mdc"}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", destRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
 			MkdirAll(destRulesDir, os.ModePerm).
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(gitRoot).
+			Return(nil, nil).
+			Times(1)
+
+		allFiles := []string{"/test/rules/readme.md"}
+
+		f.fileOpsMock.EXPECT().
+			FindFiles("/test/rules", noSymlinks).
+			Return(&models.FileScan{Files: allFiles}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			FilterFilesByPatterns(allFiles, "/test/rules", []string{"*.mdc"}, noPatterns).
+			Return([]string{}).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles(destRulesDir).
+			Return([]string{destRulesDir + "/notes.txt"}, nil).
+			Times(1)
+
 		f.fileServiceMock.EXPECT().
-			FindFilesByPatterns("/test/rules", []string{"*.mdc"}).
-			Return(sourceFiles, nil).
+			UnmatchedPatterns([]string{"*.mdc"}, []string{"readme.md", "notes.txt"}).
+			Return([]string{"*.mdc"}, nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintWarningf("Patterns matched no files: %s", "*.mdc").
 			Times(1)
 
 		expectedErr := errors.New("cleanup error")
 
 		f.fileServiceMock.EXPECT().
-			CleanupExtraFilesByPatterns(sourceFiles, "/test/rules", destRulesDir, []string{"*.mdc"}).
+			CleanupExtraFilesByPatterns([]string{}, "/test/rules", destRulesDir, []string{"*.mdc"}, noPatterns).
 			Return(expectedErr).
 			Times(1)
 
 		result, err := f.syncService.PullRules(options)
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "failed to cleanup extra files")
+		require.ErrorIs(t, err, expectedErr)
 		require.Nil(t, result)
 	})
 
-	t.Run("success with file copy", func(t *testing.T) {
+	t.Run("error cleaning up extra files", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
 		options := &models.SyncOptions{
-			RulesDir:         "/test/rules",
-			FilePatterns:     "",
-			OverwriteHeaders: false,
+			RulesDir:     "/test/rules",
+			FilePatterns: []string{"*.mdc"},
 		}
 		currentDir := testCurrentDir
 		gitRoot := testGitRoot
 		destRulesDir := testDestRulesDir
 		sourceFiles := []string{testSrcFile}
-		srcFile := testSrcFile
-		dstFile := testDstFile
-		relativePath := testRelativePath
 
 		f.fileOpsMock.EXPECT().
 			GetCurrentDir().
@@ -332,9 +401,16 @@ func TestSyncService_PullRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "pull")
+
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
-			Return([]string{}, nil).
+			GetFilePatterns([]string{"*.mdc"}, noPatterns).
+			Return([]string{"*.mdc"}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", destRulesDir).
+			Return(noPatterns, nil).
 			Times(1)
 
 		f.fileOpsMock.EXPECT().
@@ -342,67 +418,159 @@ func TestSyncService_PullRules(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(gitRoot).
+			Return(nil, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
-			FindAllFiles("/test/rules").
-			Return(sourceFiles, nil).
+			FindFiles("/test/rules", noSymlinks).
+			Return(&models.FileScan{Files: sourceFiles}, nil).
 			Times(1)
 
-		// cleanupExtraFiles is called and searches for files in destination
-		// cleanupExtraFiles calls GetRelativePath for each source file
-		f.pathUtilsMock.EXPECT().
-			GetRelativePath(srcFile, "/test/rules").
-			Return(relativePath, nil).
+		f.fileServiceMock.EXPECT().
+			FilterFilesByPatterns(sourceFiles, "/test/rules", []string{"*.mdc"}, noPatterns).
+			Return(sourceFiles).
 			Times(1)
 
 		f.fileOpsMock.EXPECT().
 			FindAllFiles(destRulesDir).
-			Return([]string{}, nil).
+			Return([]string{destRulesDir + "/local.mdc"}, nil).
 			Times(1)
 
-		f.pathUtilsMock.EXPECT().
-			RecreateDirectoryStructure(srcFile, "/test/rules", destRulesDir).
-			Return(dstFile, nil).
+		f.fileServiceMock.EXPECT().
+			UnmatchedPatterns([]string{"*.mdc"}, []string{"file1.mdc", "local.mdc"}).
+			Return([]string{}, nil).
 			Times(1)
 
-		// GetRelativePath is called again in main loop for display
-		f.pathUtilsMock.EXPECT().
-			GetRelativePath(srcFile, "/test/rules").
-			Return(relativePath, nil).
+		expectedErr := errors.New("cleanup error")
+
+		f.fileServiceMock.EXPECT().
+			CleanupExtraFilesByPatterns(sourceFiles, "/test/rules", destRulesDir, []string{"*.mdc"}, noPatterns).
+			Return(expectedErr).
 			Times(1)
 
+		result, err := f.syncService.PullRules(options)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to cleanup extra files")
+		require.Nil(t, result)
+	})
+
+	t.Run("passes ignore patterns to file search and cleanup", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: "/test/rules",
+		}
+		currentDir := testCurrentDir
+		gitRoot := testGitRoot
+		destRulesDir := testDestRulesDir
+		sourceFiles := []string{testSrcFile}
+		ignorePatterns := []string{"README.md", "/.cursyncignore"}
+
 		f.fileOpsMock.EXPECT().
-			Stat(dstFile).
-			Return(nil, os.ErrNotExist).
+			GetCurrentDir().
+			Return(currentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(currentDir).
+			Return(gitRoot, nil).
+			Times(1)
+
+		f.expectRollback(gitRoot, "pull")
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			Copy(srcFile, dstFile, false).
+			LoadIgnorePatterns("/test/rules", destRulesDir).
+			Return(ignorePatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll(destRulesDir, os.ModePerm).
 			Return(nil).
 			Times(1)
 
-		f.outputMock.EXPECT().
-			PrintOperation("add", relativePath).
+		f.manifestMock.EXPECT().
+			Load(gitRoot).
+			Return(nil, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles("/test/rules", noSymlinks).
+			Return(&models.FileScan{Files: sourceFiles}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			FilterFilesByPatterns(sourceFiles, "/test/rules", []string{}, ignorePatterns).
+			Return(sourceFiles).
+			Times(1)
+
+		expectedErr := errors.New("cleanup error")
+
+		f.fileServiceMock.EXPECT().
+			CleanupExtraFilesByPatterns(sourceFiles, "/test/rules", destRulesDir, []string{}, ignorePatterns).
+			Return(expectedErr).
 			Times(1)
 
 		result, err := f.syncService.PullRules(options)
-		require.NoError(t, err)
-		require.NotNil(t, result)
-		require.True(t, result.HasChanges)
-		require.Len(t, result.Operations, 1)
+		require.ErrorIs(t, err, expectedErr)
+		require.Nil(t, result)
+	})
 
-		if diff := cmp.Diff(models.OperationAdd, result.Operations[0].Type); diff != "" {
-			t.Fatalf("mismatch (-want +got):\n%s", diff)
+	t.Run("error loading ignore files", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir: "/test/rules",
 		}
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		f.expectRollback(testGitRoot, "pull")
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		expectedErr := errors.New("read error")
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", testDestRulesDir).
+			Return(nil, expectedErr).
+			Times(1)
+
+		result, err := f.syncService.PullRules(options)
+		require.ErrorIs(t, err, expectedErr)
+		require.Contains(t, err.Error(), "failed to load ignore files")
+		require.Nil(t, result)
 	})
 
-	t.Run("success with file update", func(t *testing.T) {
+	t.Run("success with file copy", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
 		options := &models.SyncOptions{
 			RulesDir:         "/test/rules",
-			FilePatterns:     "",
+			FilePatterns:     nil,
 			OverwriteHeaders: false,
 		}
 		currentDir := testCurrentDir
@@ -423,19 +591,31 @@ func TestSyncService_PullRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectBackup(gitRoot, "pull")
+
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", destRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
 			MkdirAll(destRulesDir, os.ModePerm).
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(gitRoot).
+			Return(nil, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
-			FindAllFiles("/test/rules").
-			Return(sourceFiles, nil).
+			FindFiles("/test/rules", noSymlinks).
+			Return(&models.FileScan{Files: sourceFiles}, nil).
 			Times(1)
 
 		// cleanupExtraFiles is called and searches for files in destination
@@ -463,21 +643,31 @@ func TestSyncService_PullRules(t *testing.T) {
 
 		f.fileOpsMock.EXPECT().
 			Stat(dstFile).
-			Return(nil, nil).
+			Return(nil, os.ErrNotExist).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			AreEqual(srcFile, dstFile, false).
-			Return(false, nil).
+			Sync(srcFile, dstFile, false, false, models.FileModePreserve, models.Normalization{}).
+			Return(true, nil).
 			Times(1)
 
-		f.fileServiceMock.EXPECT().
-			Copy(srcFile, dstFile, false).
-			Return(nil).
+		f.outputMock.EXPECT().
+			PrintOperation("add", relativePath).
 			Times(1)
 
-		f.outputMock.EXPECT().
-			PrintOperation("update", relativePath).
+		f.gitOpsMock.EXPECT().
+			GetHeadCommit("/test/rules").
+			Return("", errors.New("not a git repository")).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Snapshot(destRulesDir).
+			Return(map[string]manifest.FileEntry{}, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(gitRoot, gomock.Any()).
+			Return(nil).
 			Times(1)
 
 		result, err := f.syncService.PullRules(options)
@@ -486,19 +676,19 @@ func TestSyncService_PullRules(t *testing.T) {
 		require.True(t, result.HasChanges)
 		require.Len(t, result.Operations, 1)
 
-		if diff := cmp.Diff(models.OperationUpdate, result.Operations[0].Type); diff != "" {
+		if diff := cmp.Diff(models.OperationAdd, result.Operations[0].Type); diff != "" {
 			t.Fatalf("mismatch (-want +got):\n%s", diff)
 		}
 	})
 
-	t.Run("success skip identical files", func(t *testing.T) {
+	t.Run("success with file update", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
 		options := &models.SyncOptions{
 			RulesDir:         "/test/rules",
-			FilePatterns:     "",
+			FilePatterns:     nil,
 			OverwriteHeaders: false,
 		}
 		currentDir := testCurrentDir
@@ -519,19 +709,31 @@ func TestSyncService_PullRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectBackup(gitRoot, "pull")
+
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", destRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
 			MkdirAll(destRulesDir, os.ModePerm).
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(gitRoot).
+			Return(nil, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
-			FindAllFiles("/test/rules").
-			Return(sourceFiles, nil).
+			FindFiles("/test/rules", noSymlinks).
+			Return(&models.FileScan{Files: sourceFiles}, nil).
 			Times(1)
 
 		// cleanupExtraFiles is called and searches for files in destination
@@ -563,26 +765,50 @@ func TestSyncService_PullRules(t *testing.T) {
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			AreEqual(srcFile, dstFile, false).
+			Sync(srcFile, dstFile, true, false, models.FileModePreserve, models.Normalization{}).
 			Return(true, nil).
 			Times(1)
 
+		f.outputMock.EXPECT().
+			PrintOperation("update", relativePath).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetHeadCommit("/test/rules").
+			Return("", errors.New("not a git repository")).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Snapshot(destRulesDir).
+			Return(map[string]manifest.FileEntry{}, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(gitRoot, gomock.Any()).
+			Return(nil).
+			Times(1)
+
 		result, err := f.syncService.PullRules(options)
 		require.NoError(t, err)
 		require.NotNil(t, result)
-		require.False(t, result.HasChanges)
-		require.Empty(t, result.Operations)
+		require.True(t, result.HasChanges)
+		require.Len(t, result.Operations, 1)
+
+		if diff := cmp.Diff(models.OperationUpdate, result.Operations[0].Type); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
 	})
 
-	t.Run("error checking destination file", func(t *testing.T) {
+	t.Run("success with file mode update", func(t *testing.T) {
 		t.Parallel()
 		f, finish := setUp(t)
 		defer finish()
 
 		options := &models.SyncOptions{
 			RulesDir:         "/test/rules",
-			FilePatterns:     "",
+			FilePatterns:     nil,
 			OverwriteHeaders: false,
+			FileMode:         0755,
 		}
 		currentDir := testCurrentDir
 		gitRoot := testGitRoot
@@ -602,19 +828,31 @@ func TestSyncService_PullRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectBackup(gitRoot, "pull")
+
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", destRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
 			MkdirAll(destRulesDir, os.ModePerm).
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(gitRoot).
+			Return(nil, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
-			FindAllFiles("/test/rules").
-			Return(sourceFiles, nil).
+			FindFiles("/test/rules", noSymlinks).
+			Return(&models.FileScan{Files: sourceFiles}, nil).
 			Times(1)
 
 		// cleanupExtraFiles is called and searches for files in destination
@@ -640,21 +878,486 @@ func TestSyncService_PullRules(t *testing.T) {
 			Return(relativePath, nil).
 			Times(1)
 
-		expectedErr := errors.New("stat error")
-
 		f.fileOpsMock.EXPECT().
 			Stat(dstFile).
-			Return(nil, expectedErr).
+			Return(nil, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			Sync(srcFile, dstFile, true, false, os.FileMode(0755), models.Normalization{}).
+			Return(true, nil).
 			Times(1)
 
 		f.outputMock.EXPECT().
-			PrintErrorf("Error checking destination file %s: %v\n", relativePath, expectedErr).
+			PrintOperation("update", relativePath).
 			Times(1)
 
-		result, err := f.syncService.PullRules(options)
-		require.NoError(t, err)
+		f.gitOpsMock.EXPECT().
+			GetHeadCommit("/test/rules").
+			Return("", errors.New("not a git repository")).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Snapshot(destRulesDir).
+			Return(map[string]manifest.FileEntry{}, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(gitRoot, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.True(t, result.HasChanges)
+		require.Len(t, result.Operations, 1)
+
+		if diff := cmp.Diff(models.OperationUpdate, result.Operations[0].Type); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("success with preserved symlink", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:         "/test/rules",
+			FilePatterns:     nil,
+			OverwriteHeaders: false,
+			Symlinks:         models.SymlinkPreserve,
+		}
+		currentDir := testCurrentDir
+		gitRoot := testGitRoot
+		destRulesDir := testDestRulesDir
+		sourceFiles := []string{testSrcFile}
+		srcFile := testSrcFile
+		dstFile := testDstFile
+		relativePath := testRelativePath
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(currentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(currentDir).
+			Return(gitRoot, nil).
+			Times(1)
+
+		f.expectBackup(gitRoot, "pull")
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", destRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll(destRulesDir, os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Load(gitRoot).
+			Return(nil, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles("/test/rules", models.SymlinkPreserve).
+			Return(&models.FileScan{Files: sourceFiles}, nil).
+			Times(1)
+
+		// cleanupExtraFiles is called and searches for files in destination
+		// cleanupExtraFiles calls GetRelativePath for each source file
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(srcFile, "/test/rules").
+			Return(relativePath, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles(destRulesDir).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			RecreateDirectoryStructure(srcFile, "/test/rules", destRulesDir).
+			Return(dstFile, nil).
+			Times(1)
+
+		// GetRelativePath is called again in main loop for display
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(srcFile, "/test/rules").
+			Return(relativePath, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Stat(dstFile).
+			Return(nil, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			ReadLink(srcFile).
+			Return("shared/file1.mdc", nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			ReadLink(dstFile).
+			Return("old/file1.mdc", nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			WriteSymlink("shared/file1.mdc", dstFile).
+			Return(nil).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintOperation("update", relativePath).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetHeadCommit("/test/rules").
+			Return("", errors.New("not a git repository")).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Snapshot(destRulesDir).
+			Return(map[string]manifest.FileEntry{}, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(gitRoot, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.True(t, result.HasChanges)
+		require.Len(t, result.Operations, 1)
+
+		if diff := cmp.Diff(models.OperationUpdate, result.Operations[0].Type); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("success skip symlinks", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:         "/test/rules",
+			FilePatterns:     nil,
+			OverwriteHeaders: false,
+			Symlinks:         models.SymlinkSkip,
+		}
+		currentDir := testCurrentDir
+		gitRoot := testGitRoot
+		destRulesDir := testDestRulesDir
+		sourceFiles := []string{testSrcFile}
+		srcFile := testSrcFile
+		dstFile := testDstFile
+		relativePath := testRelativePath
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(currentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(currentDir).
+			Return(gitRoot, nil).
+			Times(1)
+
+		f.expectBackup(gitRoot, "pull")
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", destRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll(destRulesDir, os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Load(gitRoot).
+			Return(nil, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles("/test/rules", models.SymlinkSkip).
+			Return(&models.FileScan{Files: sourceFiles}, nil).
+			Times(1)
+
+		// cleanupExtraFiles is called and searches for files in destination
+		// cleanupExtraFiles calls GetRelativePath for each source file
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(srcFile, "/test/rules").
+			Return(relativePath, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles(destRulesDir).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			RecreateDirectoryStructure(srcFile, "/test/rules", destRulesDir).
+			Return(dstFile, nil).
+			Times(1)
+
+		// GetRelativePath is called again in main loop for display
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(srcFile, "/test/rules").
+			Return(relativePath, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Stat(dstFile).
+			Return(nil, os.ErrNotExist).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			ReadLink(srcFile).
+			Return("shared/file1.mdc", nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetHeadCommit("/test/rules").
+			Return("", errors.New("not a git repository")).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Snapshot(destRulesDir).
+			Return(map[string]manifest.FileEntry{}, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(gitRoot, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.False(t, result.HasChanges)
+		require.Empty(t, result.Operations)
+	})
+
+	t.Run("success skip identical files", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:         "/test/rules",
+			FilePatterns:     nil,
+			OverwriteHeaders: false,
+		}
+		currentDir := testCurrentDir
+		gitRoot := testGitRoot
+		destRulesDir := testDestRulesDir
+		sourceFiles := []string{testSrcFile}
+		srcFile := testSrcFile
+		dstFile := testDstFile
+		relativePath := testRelativePath
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(currentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(currentDir).
+			Return(gitRoot, nil).
+			Times(1)
+
+		f.expectBackup(gitRoot, "pull")
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", destRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll(destRulesDir, os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Load(gitRoot).
+			Return(nil, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles("/test/rules", noSymlinks).
+			Return(&models.FileScan{Files: sourceFiles}, nil).
+			Times(1)
+
+		// cleanupExtraFiles is called and searches for files in destination
+		// cleanupExtraFiles calls GetRelativePath for each source file
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(srcFile, "/test/rules").
+			Return(relativePath, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles(destRulesDir).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			RecreateDirectoryStructure(srcFile, "/test/rules", destRulesDir).
+			Return(dstFile, nil).
+			Times(1)
+
+		// GetRelativePath is called again in main loop for display
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(srcFile, "/test/rules").
+			Return(relativePath, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			Stat(dstFile).
+			Return(nil, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			Sync(srcFile, dstFile, true, false, models.FileModePreserve, models.Normalization{}).
+			Return(false, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetHeadCommit("/test/rules").
+			Return("", errors.New("not a git repository")).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Snapshot(destRulesDir).
+			Return(map[string]manifest.FileEntry{}, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(gitRoot, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		result, err := f.syncService.PullRules(options)
+		require.NoError(t, err)
 		require.NotNil(t, result)
 		require.False(t, result.HasChanges)
 		require.Empty(t, result.Operations)
 	})
+
+	t.Run("error checking destination file", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		options := &models.SyncOptions{
+			RulesDir:         "/test/rules",
+			FilePatterns:     nil,
+			OverwriteHeaders: false,
+		}
+		currentDir := testCurrentDir
+		gitRoot := testGitRoot
+		destRulesDir := testDestRulesDir
+		sourceFiles := []string{testSrcFile}
+		srcFile := testSrcFile
+		dstFile := testDstFile
+		relativePath := testRelativePath
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(currentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(currentDir).
+			Return(gitRoot, nil).
+			Times(1)
+
+		f.expectRollback(gitRoot, "pull")
+
+		f.fileServiceMock.EXPECT().
+			GetFilePatterns(noPatterns, noPatterns).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns("/test/rules", destRulesDir).
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll(destRulesDir, os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Load(gitRoot).
+			Return(nil, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles("/test/rules", noSymlinks).
+			Return(&models.FileScan{Files: sourceFiles}, nil).
+			Times(1)
+
+		// cleanupExtraFiles is called and searches for files in destination
+		// cleanupExtraFiles calls GetRelativePath for each source file
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(srcFile, "/test/rules").
+			Return(relativePath, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles(destRulesDir).
+			Return([]string{}, nil).
+			Times(1)
+
+		f.pathUtilsMock.EXPECT().
+			RecreateDirectoryStructure(srcFile, "/test/rules", destRulesDir).
+			Return(dstFile, nil).
+			Times(1)
+
+		// GetRelativePath is called again in main loop for display
+		f.pathUtilsMock.EXPECT().
+			GetRelativePath(srcFile, "/test/rules").
+			Return(relativePath, nil).
+			Times(1)
+
+		expectedErr := errors.New("stat error")
+
+		f.fileOpsMock.EXPECT().
+			Stat(dstFile).
+			Return(nil, expectedErr).
+			Times(1)
+
+		result, err := f.syncService.PullRules(options)
+		require.Error(t, err)
+		require.ErrorIs(t, err, expectedErr)
+		require.Contains(t, err.Error(), "failed to check destination file file1.mdc")
+		require.Contains(t, err.Error(), "all changes rolled back")
+		require.Nil(t, result)
+	})
 }
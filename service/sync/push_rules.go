@@ -2,29 +2,62 @@ package sync
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 
 	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
 )
 
-// PushRules pushes rules from project .cursor/rules directory to source directory
+// PushRules pushes rules from project .cursor/rules directory to source directory,
+// on failure all changes made to the source directory are rolled back
 func (s *SyncService) PushRules(options *models.SyncOptions) (*models.SyncResult, error) {
 	rulesEnvDir, rulesSourceDirInProject, projectGitRoot, err := s.preparePushPaths(options.RulesDir)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.beginBackup(projectGitRoot, "push"); err != nil {
+		return nil, err
+	}
+	s.openIndex(rulesEnvDir, rulesSourceDirInProject)
+	defer s.saveIndex()
+
+	result, err := s.pushFiles(options, rulesEnvDir, rulesSourceDirInProject, projectGitRoot)
+	if err != nil {
+		return nil, s.rollback(err)
+	}
+
+	if result.HasChanges {
+		if err := s.gitOps.CommitChanges(rulesEnvDir, "Sync cursor rules: updated from project "+s.pathUtils.GetBaseName(projectGitRoot), options.GitWithoutPush); err != nil {
+			s.output.PrintErrorf("Commit failed for %s: %v\n", rulesEnvDir, err)
+		} else {
+			s.backups.MarkCommitted(rulesEnvDir)
+		}
+	}
+	s.finishBackup()
+
+	return result, nil
+}
+
+// pushFiles synchronizes source directory with project rules directory, stops at the first failed file
+func (s *SyncService) pushFiles(options *models.SyncOptions, rulesEnvDir, rulesSourceDirInProject, projectGitRoot string) (*models.SyncResult, error) {
 	if validateErr := s.validateRulesDirectory(rulesSourceDirInProject); validateErr != nil {
 		return nil, validateErr
 	}
 
-	filePatterns, err := s.fileService.GetFilePatterns(options.FilePatterns)
+	filePatterns, err := s.fileService.GetFilePatterns(options.FilePatterns, options.ExcludePatterns)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file patterns: %w", err)
 	}
 
-	projectFiles, err := s.findFilesWithPatterns(rulesSourceDirInProject, filePatterns)
+	ignorePatterns, err := s.fileService.LoadIgnorePatterns(rulesSourceDirInProject, rulesEnvDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ignore files: %w", err)
+	}
+
+	projectFiles, projectScan, err := s.findFilesWithPatterns(rulesSourceDirInProject, filePatterns, ignorePatterns, options.Symlinks, rulesEnvDir)
 	if err != nil {
 		return nil, err
 	}
@@ -33,19 +66,72 @@ func (s *SyncService) PushRules(options *models.SyncOptions) (*models.SyncResult
 		return nil, fmt.Errorf("failed to create destination directory %s: %w", rulesEnvDir, mkdirErr)
 	}
 
-	if err := s.cleanupExtraFilesWithPatterns(projectFiles, rulesSourceDirInProject, rulesEnvDir, filePatterns); err != nil {
+	s.warnUnmatchedPatterns(filePatterns, relativeSlashPaths(projectScan.Files, rulesSourceDirInProject), rulesEnvDir)
+
+	m := s.loadManifest(projectGitRoot)
+	managed := m.ManagedFiles(rulesEnvDir)
+	projectFiles = s.selectPushedFiles(m, managed, projectFiles, rulesSourceDirInProject)
+
+	removed, err := s.cleanupDestination(options.DeleteMode, managed, projectFiles, rulesSourceDirInProject, rulesEnvDir, filePatterns, ignorePatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.copyFilesForPush(projectFiles, rulesSourceDirInProject, rulesEnvDir, options)
+	if err != nil {
 		return nil, err
 	}
+	if !result.HasChanges && s.hasLinkedChanges(m, rulesEnvDir) {
+		result.HasChanges = true
+	}
 
-	result := s.copyFilesForPush(projectFiles, rulesSourceDirInProject, rulesEnvDir, options.OverwriteHeaders)
+	s.recordPushedFiles(m, projectGitRoot, rulesEnvDir, removed, projectFiles, rulesSourceDirInProject)
 
-	if result.HasChanges {
-		if err := s.gitOps.CommitChanges(rulesEnvDir, "Sync cursor rules: updated from project "+s.pathUtils.GetBaseName(projectGitRoot), options.GitWithoutPush); err != nil {
-			s.output.PrintErrorf("Commit failed for %s: %v\n", rulesEnvDir, err)
+	return result, nil
+}
+
+// selectPushedFiles returns project files managed on behalf of the rules directory, including explicitly added ones,
+// all files are pushed while the project has no ownership records for the rules directory, e.g. before the first pull
+func (s *SyncService) selectPushedFiles(m *manifest.Manifest, managed map[string]bool, projectFiles []string, projectRulesDir string) []string {
+	if len(managed) == 0 {
+		return projectFiles
+	}
+
+	selected := []string{}
+	skipped := 0
+	for _, projectFile := range projectFiles {
+		if managed[relativeSlashPath(projectFile, projectRulesDir)] {
+			selected = append(selected, projectFile)
+		} else {
+			skipped++
 		}
 	}
 
-	return result, nil
+	if skipped > 0 {
+		s.output.PrintWarningf("Skipped %d unmanaged files, use 'cursync add' to push them", skipped)
+	}
+	return selected
+}
+
+// recordPushedFiles marks pushed files as managed and forgets managed files removed from rules directory,
+// manifest is saved only when ownership changed
+func (s *SyncService) recordPushedFiles(m *manifest.Manifest, projectRoot, rulesDir string, removed, pushedFiles []string, projectRulesDir string) {
+	if m == nil {
+		m = &manifest.Manifest{Files: map[string]manifest.FileEntry{}}
+	}
+
+	before := maps.Clone(m.Managed)
+	m.Unmanage(removed...)
+	for _, pushedFile := range pushedFiles {
+		m.Manage(rulesDir, relativeSlashPath(pushedFile, projectRulesDir))
+	}
+	if maps.Equal(before, m.Managed) {
+		return
+	}
+
+	if err := s.manifestRepository.Save(projectRoot, m); err != nil {
+		s.output.PrintWarningf("Failed to save sync manifest: %v", err)
+	}
 }
 
 // preparePushPaths prepares paths for push operation
@@ -86,77 +172,30 @@ func (s *SyncService) validateRulesDirectory(rulesSourceDirInProject string) err
 	return nil
 }
 
-// copyFilesForPush copies files from project to source directory
-func (s *SyncService) copyFilesForPush(projectFiles []string, srcBase, dstBase string, overwriteHeaders bool) *models.SyncResult {
-	result := &models.SyncResult{
-		Operations: []models.FileOperation{},
-		HasChanges: false,
+// copyFilesForPush copies files from project to source directory,
+// files are compared and copied by options.Jobs workers and operations are printed in order of relative paths
+func (s *SyncService) copyFilesForPush(projectFiles []string, srcBase, dstBase string, options *models.SyncOptions) (*models.SyncResult, error) {
+	fileExists := func(dstFileFullPath, relativePath string) (bool, error) {
+		return s.checkFileExistsForPush(dstFileFullPath, relativePath, dstBase)
 	}
-
-	if len(projectFiles) == 0 {
-		return result
+	operations, err := runFileJobs(projectFiles, options.Jobs, func(srcFileFullPath string) (*models.FileOperation, error) {
+		return s.syncFile(srcFileFullPath, srcBase, dstBase, options, fileExists)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	for _, srcFileFullPath := range projectFiles {
-		dstFileFullPath, err := s.pathUtils.RecreateDirectoryStructure(srcFileFullPath, srcBase, dstBase)
-		if err != nil {
-			s.output.PrintErrorf("Error recreating directory structure for %s: %v\n", srcFileFullPath, err)
-			continue
-		}
-
-		relativePath, err := s.pathUtils.GetRelativePath(srcFileFullPath, srcBase)
-		if err != nil {
-			relativePath = s.pathUtils.GetBaseName(srcFileFullPath)
-		}
-
-		fileExists, err := s.checkFileExistsForPush(dstFileFullPath, relativePath, dstBase)
-		if err != nil {
-			continue
-		}
-
-		shouldCopy := s.shouldCopyFile(srcFileFullPath, dstFileFullPath, fileExists, overwriteHeaders, relativePath)
-		if !shouldCopy {
-			continue
-		}
-
-		if s.copySingleFileForPush(srcFileFullPath, dstFileFullPath, relativePath, fileExists, overwriteHeaders, dstBase, result) {
-			result.HasChanges = true
-		}
+	for _, operation := range operations {
+		s.output.PrintOperationWithTarget(string(operation.Type), operation.RelativePath, s.pathUtils.GetBaseName(dstBase))
 	}
-
-	return result
+	return &models.SyncResult{Operations: operations, HasChanges: len(operations) > 0}, nil
 }
 
 // checkFileExistsForPush checks if destination file exists for push operation
 func (s *SyncService) checkFileExistsForPush(dstFileFullPath, relativePath, dstBase string) (bool, error) {
 	exists, err := s.fileOps.FileExists(dstFileFullPath)
 	if err != nil {
-		s.output.PrintErrorf("Error checking destination file %s in %s: %v\n", relativePath, dstBase, err)
-		return false, err
+		return false, fmt.Errorf("failed to check destination file %s in %s: %w", relativePath, dstBase, err)
 	}
 	return exists, nil
 }
-
-// copySingleFileForPush copies a single file for push operation
-func (s *SyncService) copySingleFileForPush(srcFileFullPath, dstFileFullPath, relativePath string, fileExists, overwriteHeaders bool, dstBase string, result *models.SyncResult) bool {
-	copyErr := s.fileService.Copy(srcFileFullPath, dstFileFullPath, overwriteHeaders)
-	if copyErr != nil {
-		s.output.PrintErrorf("Error synchronizing file %s to %s: %v\n", relativePath, dstBase, copyErr)
-		return false
-	}
-
-	operationType := "update"
-	if !fileExists {
-		operationType = "add"
-	}
-	s.output.PrintOperationWithTarget(operationType, relativePath, s.pathUtils.GetBaseName(dstBase))
-
-	result.Operations = append(result.Operations, models.FileOperation{
-		Type:         models.OperationType(operationType),
-		SourcePath:   srcFileFullPath,
-		TargetPath:   dstFileFullPath,
-		RelativePath: relativePath,
-	})
-
-	return true
-}
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
 )
 
 const (
@@ -106,6 +107,8 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "push")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(rulesSourceDirInProject).
 			Return(false, nil).
@@ -125,7 +128,7 @@ func TestSyncService_PushRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:     "/test/rules",
-			FilePatterns: "",
+			FilePatterns: nil,
 		}
 		currentDir := testCurrentDirPush
 		gitRoot := testGitRootPush
@@ -141,6 +144,8 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "push")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(rulesSourceDirInProject).
 			Return(true, nil).
@@ -149,7 +154,7 @@ func TestSyncService_PushRules(t *testing.T) {
 		expectedErr := errors.New("file patterns error")
 
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return(nil, expectedErr).
 			Times(1)
 
@@ -166,7 +171,7 @@ func TestSyncService_PushRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:     "/test/rules",
-			FilePatterns: "",
+			FilePatterns: nil,
 		}
 		currentDir := testCurrentDirPush
 		gitRoot := testGitRootPush
@@ -182,20 +187,27 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "push")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(rulesSourceDirInProject).
 			Return(true, nil).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
+			Times(1)
+
 		expectedErr := errors.New("find files error")
 
 		f.fileOpsMock.EXPECT().
-			FindAllFiles(rulesSourceDirInProject).
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
 			Return(nil, expectedErr).
 			Times(1)
 
@@ -212,7 +224,7 @@ func TestSyncService_PushRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:     "/test/rules",
-			FilePatterns: "*.mdc",
+			FilePatterns: []string{"*.mdc"},
 		}
 		currentDir := testCurrentDirPush
 		gitRoot := testGitRootPush
@@ -228,26 +240,33 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "push")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(rulesSourceDirInProject).
 			Return(true, nil).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("*.mdc").
+			GetFilePatterns([]string{"*.mdc"}, noPatterns).
 			Return([]string{"*.mdc"}, nil).
 			Times(1)
 
-		expectedErr := errors.New("find files by patterns error")
-
 		f.fileServiceMock.EXPECT().
-			FindFilesByPatterns(rulesSourceDirInProject, []string{"*.mdc"}).
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
+			Times(1)
+
+		expectedErr := errors.New("find files error")
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
 			Return(nil, expectedErr).
 			Times(1)
 
 		result, err := f.syncService.PushRules(options)
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "failed to find files by patterns")
+		require.ErrorIs(t, err, expectedErr)
+		require.Contains(t, err.Error(), "failed to find source files")
 		require.Nil(t, result)
 	})
 
@@ -258,7 +277,7 @@ func TestSyncService_PushRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:     "/test/rules",
-			FilePatterns: "",
+			FilePatterns: nil,
 		}
 		currentDir := testCurrentDirPush
 		gitRoot := testGitRootPush
@@ -275,19 +294,26 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "push")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(rulesSourceDirInProject).
 			Return(true, nil).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
-			FindAllFiles(rulesSourceDirInProject).
-			Return(projectFiles, nil).
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: projectFiles}, nil).
 			Times(1)
 
 		expectedErr := errors.New("mkdir error")
@@ -310,7 +336,7 @@ func TestSyncService_PushRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:     "/test/rules",
-			FilePatterns: "*.mdc",
+			FilePatterns: []string{"*.mdc"},
 		}
 		currentDir := testCurrentDirPush
 		gitRoot := testGitRootPush
@@ -327,19 +353,31 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "push")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(rulesSourceDirInProject).
 			Return(true, nil).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("*.mdc").
+			GetFilePatterns([]string{"*.mdc"}, noPatterns).
 			Return([]string{"*.mdc"}, nil).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			FindFilesByPatterns(rulesSourceDirInProject, []string{"*.mdc"}).
-			Return(projectFiles, nil).
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: projectFiles}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			FilterFilesByPatterns(projectFiles, rulesSourceDirInProject, []string{"*.mdc"}, noPatterns).
+			Return(projectFiles).
 			Times(1)
 
 		f.fileOpsMock.EXPECT().
@@ -347,10 +385,25 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(testGitRootPush).
+			Return(nil, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			FindAllFiles("/test/rules").
+			Return([]string{"/test/rules/shared.mdc"}, nil).
+			Times(1)
+
+		f.fileServiceMock.EXPECT().
+			UnmatchedPatterns([]string{"*.mdc"}, []string{"file1.mdc", "shared.mdc"}).
+			Return([]string{}, nil).
+			Times(1)
+
 		expectedErr := errors.New("cleanup error")
 
 		f.fileServiceMock.EXPECT().
-			CleanupExtraFilesByPatterns(projectFiles, rulesSourceDirInProject, "/test/rules", []string{"*.mdc"}).
+			CleanupExtraFilesByPatterns(projectFiles, rulesSourceDirInProject, "/test/rules", []string{"*.mdc"}, noPatterns).
 			Return(expectedErr).
 			Times(1)
 
@@ -367,7 +420,7 @@ func TestSyncService_PushRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:         "/test/rules",
-			FilePatterns:     "",
+			FilePatterns:     nil,
 			OverwriteHeaders: false,
 			GitWithoutPush:   false,
 		}
@@ -389,19 +442,26 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectBackup(gitRoot, "push")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(rulesSourceDirInProject).
 			Return(true, nil).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
-			FindAllFiles(rulesSourceDirInProject).
-			Return(projectFiles, nil).
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: projectFiles}, nil).
 			Times(1)
 
 		f.fileOpsMock.EXPECT().
@@ -409,6 +469,11 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(testGitRootPush).
+			Return(nil, nil).
+			Times(1)
+
 		// cleanupExtraFiles is called and searches for files in destination
 		// cleanupExtraFiles calls GetRelativePath for each source file
 		f.pathUtilsMock.EXPECT().
@@ -438,8 +503,8 @@ func TestSyncService_PushRules(t *testing.T) {
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			Copy(srcFile, dstFile, false).
-			Return(nil).
+			Sync(srcFile, dstFile, false, false, models.FileModePreserve, models.Normalization{}).
+			Return(true, nil).
 			Times(1)
 
 		f.pathUtilsMock.EXPECT().
@@ -461,6 +526,18 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		f.backupMock.EXPECT().
+			MarkCommitted("/test/rules").
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRootPush, &manifest.Manifest{
+				Files:   map[string]manifest.FileEntry{},
+				Managed: map[string]string{testRelativePathPush: "/test/rules"},
+			}).
+			Return(nil).
+			Times(1)
+
 		result, err := f.syncService.PushRules(options)
 		require.NoError(t, err)
 		require.NotNil(t, result)
@@ -479,7 +556,7 @@ func TestSyncService_PushRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:         "/test/rules",
-			FilePatterns:     "",
+			FilePatterns:     nil,
 			OverwriteHeaders: false,
 			GitWithoutPush:   true,
 		}
@@ -501,19 +578,26 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectBackup(gitRoot, "push")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(rulesSourceDirInProject).
 			Return(true, nil).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
-			FindAllFiles(rulesSourceDirInProject).
-			Return(projectFiles, nil).
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: projectFiles}, nil).
 			Times(1)
 
 		f.fileOpsMock.EXPECT().
@@ -521,6 +605,11 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(testGitRootPush).
+			Return(nil, nil).
+			Times(1)
+
 		// cleanupExtraFiles is called and searches for files in destination
 		// cleanupExtraFiles calls GetRelativePath for each source file
 		f.pathUtilsMock.EXPECT().
@@ -550,13 +639,8 @@ func TestSyncService_PushRules(t *testing.T) {
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			AreEqual(srcFile, dstFile, false).
-			Return(false, nil).
-			Times(1)
-
-		f.fileServiceMock.EXPECT().
-			Copy(srcFile, dstFile, false).
-			Return(nil).
+			Sync(srcFile, dstFile, true, false, models.FileModePreserve, models.Normalization{}).
+			Return(true, nil).
 			Times(1)
 
 		f.pathUtilsMock.EXPECT().
@@ -578,6 +662,18 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		f.backupMock.EXPECT().
+			MarkCommitted("/test/rules").
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRootPush, &manifest.Manifest{
+				Files:   map[string]manifest.FileEntry{},
+				Managed: map[string]string{testRelativePathPush: "/test/rules"},
+			}).
+			Return(nil).
+			Times(1)
+
 		result, err := f.syncService.PushRules(options)
 		require.NoError(t, err)
 		require.NotNil(t, result)
@@ -596,7 +692,7 @@ func TestSyncService_PushRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:         "/test/rules",
-			FilePatterns:     "",
+			FilePatterns:     nil,
 			OverwriteHeaders: false,
 		}
 		currentDir := testCurrentDirPush
@@ -617,19 +713,26 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectBackup(gitRoot, "push")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(rulesSourceDirInProject).
 			Return(true, nil).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
-			FindAllFiles(rulesSourceDirInProject).
-			Return(projectFiles, nil).
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: projectFiles}, nil).
 			Times(1)
 
 		f.fileOpsMock.EXPECT().
@@ -637,6 +740,11 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(testGitRootPush).
+			Return(nil, nil).
+			Times(1)
+
 		// cleanupExtraFiles is called and searches for files in destination
 		// cleanupExtraFiles calls GetRelativePath for each source file
 		f.pathUtilsMock.EXPECT().
@@ -666,8 +774,16 @@ func TestSyncService_PushRules(t *testing.T) {
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			AreEqual(srcFile, dstFile, false).
-			Return(true, nil).
+			Sync(srcFile, dstFile, true, false, models.FileModePreserve, models.Normalization{}).
+			Return(false, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Save(testGitRootPush, &manifest.Manifest{
+				Files:   map[string]manifest.FileEntry{},
+				Managed: map[string]string{testRelativePathPush: "/test/rules"},
+			}).
+			Return(nil).
 			Times(1)
 
 		result, err := f.syncService.PushRules(options)
@@ -684,7 +800,7 @@ func TestSyncService_PushRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:     "/test/rules",
-			FilePatterns: "",
+			FilePatterns: nil,
 		}
 		currentDir := testCurrentDirPush
 		gitRoot := testGitRootPush
@@ -700,19 +816,26 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectBackup(gitRoot, "push")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(rulesSourceDirInProject).
 			Return(true, nil).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
-			FindAllFiles(rulesSourceDirInProject).
-			Return([]string{}, nil).
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: []string{}}, nil).
 			Times(1)
 
 		f.fileOpsMock.EXPECT().
@@ -720,6 +843,11 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(testGitRootPush).
+			Return(nil, nil).
+			Times(1)
+
 		// cleanupExtraFiles is called and searches for files in destination
 		f.fileOpsMock.EXPECT().
 			FindAllFiles("/test/rules").
@@ -740,7 +868,7 @@ func TestSyncService_PushRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:     "/test/rules",
-			FilePatterns: "",
+			FilePatterns: nil,
 		}
 		currentDir := testCurrentDirPush
 		gitRoot := testGitRootPush
@@ -758,19 +886,26 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "push")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(rulesSourceDirInProject).
 			Return(true, nil).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
-			FindAllFiles(rulesSourceDirInProject).
-			Return(projectFiles, nil).
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: projectFiles}, nil).
 			Times(1)
 
 		f.fileOpsMock.EXPECT().
@@ -778,6 +913,11 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(testGitRootPush).
+			Return(nil, nil).
+			Times(1)
+
 		// cleanupExtraFiles is called and searches for files in destination
 		f.fileOpsMock.EXPECT().
 			FindAllFiles("/test/rules").
@@ -790,20 +930,19 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return("file1.mdc", nil).
 			Times(1)
 
+		expectedErr := errors.New("recreate error")
+
 		f.pathUtilsMock.EXPECT().
 			RecreateDirectoryStructure(srcFile, rulesSourceDirInProject, "/test/rules").
-			Return("", errors.New("recreate error")).
-			Times(1)
-
-		f.outputMock.EXPECT().
-			PrintErrorf("Error recreating directory structure for %s: %v\n", srcFile, errors.New("recreate error")).
+			Return("", expectedErr).
 			Times(1)
 
 		result, err := f.syncService.PushRules(options)
-		require.NoError(t, err)
-		require.NotNil(t, result)
-		require.False(t, result.HasChanges)
-		require.Empty(t, result.Operations)
+		require.Error(t, err)
+		require.ErrorIs(t, err, expectedErr)
+		require.Contains(t, err.Error(), "failed to recreate directory structure")
+		require.Contains(t, err.Error(), "all changes rolled back")
+		require.Nil(t, result)
 	})
 
 	t.Run("error checking destination file", func(t *testing.T) {
@@ -813,7 +952,7 @@ func TestSyncService_PushRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:     "/test/rules",
-			FilePatterns: "",
+			FilePatterns: nil,
 		}
 		currentDir := testCurrentDirPush
 		gitRoot := testGitRootPush
@@ -833,19 +972,26 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "push")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(rulesSourceDirInProject).
 			Return(true, nil).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
-			FindAllFiles(rulesSourceDirInProject).
-			Return(projectFiles, nil).
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: projectFiles}, nil).
 			Times(1)
 
 		f.fileOpsMock.EXPECT().
@@ -853,6 +999,11 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(testGitRootPush).
+			Return(nil, nil).
+			Times(1)
+
 		// cleanupExtraFiles is called and searches for files in destination
 		// cleanupExtraFiles calls GetRelativePath for each source file
 		f.pathUtilsMock.EXPECT().
@@ -876,20 +1027,19 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(relativePath, nil).
 			Times(1)
 
+		expectedErr := errors.New("file exists error")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(dstFile).
-			Return(false, errors.New("file exists error")).
-			Times(1)
-
-		f.outputMock.EXPECT().
-			PrintErrorf("Error checking destination file %s in %s: %v\n", relativePath, "/test/rules", errors.New("file exists error")).
+			Return(false, expectedErr).
 			Times(1)
 
 		result, err := f.syncService.PushRules(options)
-		require.NoError(t, err)
-		require.NotNil(t, result)
-		require.False(t, result.HasChanges)
-		require.Empty(t, result.Operations)
+		require.Error(t, err)
+		require.ErrorIs(t, err, expectedErr)
+		require.Contains(t, err.Error(), "failed to check destination file file1.mdc")
+		require.Contains(t, err.Error(), "all changes rolled back")
+		require.Nil(t, result)
 	})
 
 	t.Run("error copying file", func(t *testing.T) {
@@ -899,7 +1049,7 @@ func TestSyncService_PushRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:         "/test/rules",
-			FilePatterns:     "",
+			FilePatterns:     nil,
 			OverwriteHeaders: false,
 		}
 		currentDir := testCurrentDirPush
@@ -920,111 +1070,26 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectRollback(gitRoot, "push")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(rulesSourceDirInProject).
 			Return(true, nil).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
-		f.fileOpsMock.EXPECT().
-			FindAllFiles(rulesSourceDirInProject).
-			Return(projectFiles, nil).
-			Times(1)
-
-		f.fileOpsMock.EXPECT().
-			MkdirAll("/test/rules", os.ModePerm).
-			Return(nil).
-			Times(1)
-
-		// cleanupExtraFiles is called and searches for files in destination
-		// cleanupExtraFiles calls GetRelativePath for each source file
-		f.pathUtilsMock.EXPECT().
-			GetRelativePath(srcFile, rulesSourceDirInProject).
-			Return(relativePath, nil).
-			Times(1)
-
-		f.fileOpsMock.EXPECT().
-			FindAllFiles("/test/rules").
-			Return([]string{}, nil).
-			Times(1)
-
-		f.pathUtilsMock.EXPECT().
-			RecreateDirectoryStructure(srcFile, rulesSourceDirInProject, "/test/rules").
-			Return(dstFile, nil).
-			Times(1)
-
-		// GetRelativePath is called again in main loop for display
-		f.pathUtilsMock.EXPECT().
-			GetRelativePath(srcFile, rulesSourceDirInProject).
-			Return(relativePath, nil).
-			Times(1)
-
-		f.fileOpsMock.EXPECT().
-			FileExists(dstFile).
-			Return(false, nil).
-			Times(1)
-
-		f.fileServiceMock.EXPECT().
-			Copy(srcFile, dstFile, false).
-			Return(errors.New("copy error")).
-			Times(1)
-
-		f.outputMock.EXPECT().
-			PrintErrorf("Error synchronizing file %s to %s: %v\n", relativePath, "/test/rules", errors.New("copy error")).
-			Times(1)
-
-		result, err := f.syncService.PushRules(options)
-		require.NoError(t, err)
-		require.NotNil(t, result)
-		require.False(t, result.HasChanges)
-		require.Empty(t, result.Operations)
-	})
-
-	t.Run("error comparing files", func(t *testing.T) {
-		t.Parallel()
-		f, finish := setUp(t)
-		defer finish()
-
-		options := &models.SyncOptions{
-			RulesDir:         "/test/rules",
-			FilePatterns:     "",
-			OverwriteHeaders: false,
-		}
-		currentDir := testCurrentDirPush
-		gitRoot := testGitRootPush
-		rulesSourceDirInProject := testDestRulesDirPush
-		projectFiles := []string{testSrcFilePush}
-		srcFile := testSrcFilePush
-		dstFile := testDstFilePush
-		relativePath := testRelativePathPush
-
-		f.fileOpsMock.EXPECT().
-			GetCurrentDir().
-			Return(currentDir, nil).
-			Times(1)
-
-		f.gitOpsMock.EXPECT().
-			GetGitRootDir(currentDir).
-			Return(gitRoot, nil).
-			Times(1)
-
-		f.fileOpsMock.EXPECT().
-			FileExists(rulesSourceDirInProject).
-			Return(true, nil).
-			Times(1)
-
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
-			Return([]string{}, nil).
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
 			Times(1)
 
 		f.fileOpsMock.EXPECT().
-			FindAllFiles(rulesSourceDirInProject).
-			Return(projectFiles, nil).
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: projectFiles}, nil).
 			Times(1)
 
 		f.fileOpsMock.EXPECT().
@@ -1032,6 +1097,11 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(testGitRootPush).
+			Return(nil, nil).
+			Times(1)
+
 		// cleanupExtraFiles is called and searches for files in destination
 		// cleanupExtraFiles calls GetRelativePath for each source file
 		f.pathUtilsMock.EXPECT().
@@ -1057,47 +1127,22 @@ func TestSyncService_PushRules(t *testing.T) {
 
 		f.fileOpsMock.EXPECT().
 			FileExists(dstFile).
-			Return(true, nil).
-			Times(1)
-
-		f.fileServiceMock.EXPECT().
-			AreEqual(srcFile, dstFile, false).
-			Return(false, errors.New("compare error")).
+			Return(false, nil).
 			Times(1)
 
-		f.outputMock.EXPECT().
-			PrintErrorf("Error comparing files %s: %v\n", relativePath, errors.New("compare error")).
-			Times(1)
+		expectedErr := errors.New("copy error")
 
 		f.fileServiceMock.EXPECT().
-			Copy(srcFile, dstFile, false).
-			Return(nil).
-			Times(1)
-
-		f.pathUtilsMock.EXPECT().
-			GetBaseName("/test/rules").
-			Return("rules").
-			Times(1)
-
-		f.outputMock.EXPECT().
-			PrintOperationWithTarget("update", relativePath, "rules").
-			Times(1)
-
-		f.pathUtilsMock.EXPECT().
-			GetBaseName(gitRoot).
-			Return("git").
-			Times(1)
-
-		f.gitOpsMock.EXPECT().
-			CommitChanges("/test/rules", "Sync cursor rules: updated from project git", false).
-			Return(nil).
+			Sync(srcFile, dstFile, false, false, models.FileModePreserve, models.Normalization{}).
+			Return(false, expectedErr).
 			Times(1)
 
 		result, err := f.syncService.PushRules(options)
-		require.NoError(t, err)
-		require.NotNil(t, result)
-		require.True(t, result.HasChanges)
-		require.Len(t, result.Operations, 1)
+		require.Error(t, err)
+		require.ErrorIs(t, err, expectedErr)
+		require.Contains(t, err.Error(), "failed to synchronize file file1.mdc")
+		require.Contains(t, err.Error(), "all changes rolled back")
+		require.Nil(t, result)
 	})
 
 	t.Run("error commit changes", func(t *testing.T) {
@@ -1107,7 +1152,7 @@ func TestSyncService_PushRules(t *testing.T) {
 
 		options := &models.SyncOptions{
 			RulesDir:         "/test/rules",
-			FilePatterns:     "",
+			FilePatterns:     nil,
 			OverwriteHeaders: false,
 		}
 		currentDir := testCurrentDirPush
@@ -1128,19 +1173,26 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(gitRoot, nil).
 			Times(1)
 
+		f.expectBackup(gitRoot, "push")
+
 		f.fileOpsMock.EXPECT().
 			FileExists(rulesSourceDirInProject).
 			Return(true, nil).
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			GetFilePatterns("").
+			GetFilePatterns(noPatterns, noPatterns).
 			Return([]string{}, nil).
 			Times(1)
 
+		f.fileServiceMock.EXPECT().
+			LoadIgnorePatterns(rulesSourceDirInProject, "/test/rules").
+			Return(noPatterns, nil).
+			Times(1)
+
 		f.fileOpsMock.EXPECT().
-			FindAllFiles(rulesSourceDirInProject).
-			Return(projectFiles, nil).
+			FindFiles(rulesSourceDirInProject, noSymlinks, "/test/rules").
+			Return(&models.FileScan{Files: projectFiles}, nil).
 			Times(1)
 
 		f.fileOpsMock.EXPECT().
@@ -1148,6 +1200,11 @@ func TestSyncService_PushRules(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Load(testGitRootPush).
+			Return(nil, nil).
+			Times(1)
+
 		// cleanupExtraFiles is called and searches for files in destination
 		// cleanupExtraFiles calls GetRelativePath for each source file
 		f.pathUtilsMock.EXPECT().
@@ -1177,8 +1234,8 @@ func TestSyncService_PushRules(t *testing.T) {
 			Times(1)
 
 		f.fileServiceMock.EXPECT().
-			Copy(srcFile, dstFile, false).
-			Return(nil).
+			Sync(srcFile, dstFile, false, false, models.FileModePreserve, models.Normalization{}).
+			Return(true, nil).
 			Times(1)
 
 		f.pathUtilsMock.EXPECT().
@@ -1204,6 +1261,14 @@ func TestSyncService_PushRules(t *testing.T) {
 			PrintErrorf("Commit failed for %s: %v\n", "/test/rules", errors.New("commit error")).
 			Times(1)
 
+		f.manifestMock.EXPECT().
+			Save(testGitRootPush, &manifest.Manifest{
+				Files:   map[string]manifest.FileEntry{},
+				Managed: map[string]string{testRelativePathPush: "/test/rules"},
+			}).
+			Return(nil).
+			Times(1)
+
 		result, err := f.syncService.PushRules(options)
 		require.NoError(t, err)
 		require.NotNil(t, result)
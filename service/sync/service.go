@@ -2,14 +2,22 @@
 package sync
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/backup"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
 )
 
 type outputService interface {
+	PrintInfo(message string)
 	PrintErrorf(format string, args ...interface{})
 	PrintOperation(operationType, relativePath string)
 	PrintOperationWithTarget(operationType, relativePath, target string)
+	PrintWarningf(format string, args ...interface{})
 }
 
 type pathUtils interface {
@@ -21,19 +29,53 @@ type pathUtils interface {
 type gitOps interface {
 	GetGitRootDir(startDir string) (string, error)
 	CommitChanges(repoDir, commitMessage string, withoutPush bool) error
+	GetHeadCommit(repoDir string) (string, error)
+	HasUncommittedChanges(repoDir string) (bool, error)
+	GetChangedFiles(repoDir, fromCommit string) ([]models.ChangedFile, error)
+}
+
+type manifestRepository interface {
+	Load(projectRoot string) (*manifest.Manifest, error)
+	Save(projectRoot string, m *manifest.Manifest) error
+	Snapshot(dir string) (map[string]manifest.FileEntry, error)
+	GetManifestPath(projectRoot string) string
+}
+
+type backupRepository interface {
+	Begin(projectRoot, operation string, companions ...string) error
+	MarkCommitted(repoDir string)
+	Finish() error
+	Rollback() error
+	List(projectRoot string) ([]*backup.Backup, error)
+	Restore(projectRoot string, force bool) (*backup.Backup, error)
 }
 
 type fileService interface {
-	GetFilePatterns(flagValue string) ([]string, error)
-	FindFilesByPatterns(dir string, patterns []string) ([]string, error)
-	CleanupExtraFilesByPatterns(srcFiles []string, srcBase, dstBase string, patterns []string) error
-	AreEqual(file1, file2 string, overwriteHeaders bool) (bool, error)
-	Copy(srcPath, dstPath string, overwriteHeaders bool) error
+	GetFilePatterns(include, exclude []string) ([]string, error)
+	UnmatchedPatterns(patterns []string, relativePaths []string) ([]string, error)
+	LoadIgnorePatterns(dirs ...string) ([]string, error)
+	FilterFilesByPatterns(files []string, baseDir string, patterns, ignorePatterns []string) []string
+	CleanupExtraFilesByPatterns(srcFiles []string, srcBase, dstBase string, patterns, ignorePatterns []string) error
+	Sync(srcPath, dstPath string, dstExists, overwriteHeaders bool, mode os.FileMode, normalization models.Normalization) (bool, error)
+}
+
+// contentIndex keeps digests of file contents of opened directories between operations
+type contentIndex interface {
+	Open(dirs ...string) error
+	Save() error
+}
+
+// watcher reports changes of directory trees
+type watcher interface {
+	Watch(ctx context.Context, dirs ...string) (<-chan string, error)
 }
 
 // fileOps defines interface for file operations used in sync
 type fileOps interface {
 	FindAllFiles(dir string) ([]string, error)
+	FindFiles(dir string, symlinks models.SymlinkMode, linkRoots ...string) (*models.FileScan, error)
+	ReadLink(filePath string) (string, error)
+	WriteSymlink(target, filePath string) error
 	GetCurrentDir() (string, error)
 	MkdirAll(path string, perm os.FileMode) error
 	Stat(filePath string) (os.FileInfo, error)
@@ -43,32 +85,44 @@ type fileOps interface {
 
 // SyncService handles all sync operations
 type SyncService struct {
-	output      outputService
-	fileOps     fileOps
-	pathUtils   pathUtils
-	gitOps      gitOps
-	fileService fileService
+	output             outputService
+	fileOps            fileOps
+	pathUtils          pathUtils
+	gitOps             gitOps
+	fileService        fileService
+	manifestRepository manifestRepository
+	backups            backupRepository
+	index              contentIndex
+	watcher            watcher
 }
 
 // NewSyncService creates a new SyncService instance
-func NewSyncService(output outputService, fileOps fileOps, pathUtils pathUtils, gitOps gitOps, fileService fileService) *SyncService {
+func NewSyncService(output outputService, fileOps fileOps, pathUtils pathUtils, gitOps gitOps, fileService fileService, manifestRepository manifestRepository, backups backupRepository, index contentIndex, watcher watcher) *SyncService {
 	return &SyncService{
-		output:      output,
-		fileOps:     fileOps,
-		pathUtils:   pathUtils,
-		gitOps:      gitOps,
-		fileService: fileService,
+		output:             output,
+		fileOps:            fileOps,
+		pathUtils:          pathUtils,
+		gitOps:             gitOps,
+		fileService:        fileService,
+		manifestRepository: manifestRepository,
+		backups:            backups,
+		index:              index,
+		watcher:            watcher,
 	}
 }
 
 // NewSyncServiceWithMocks creates a new SyncService with provided mocks for testing
-func NewSyncServiceWithMocks(output outputService, fileOps fileOps, pathUtils pathUtils, gitOps gitOps, fileService fileService) *SyncService {
+func NewSyncServiceWithMocks(output outputService, fileOps fileOps, pathUtils pathUtils, gitOps gitOps, fileService fileService, manifestRepository manifestRepository, backups backupRepository, index contentIndex, watcher watcher) *SyncService {
 	return &SyncService{
-		output:      output,
-		fileOps:     fileOps,
-		pathUtils:   pathUtils,
-		gitOps:      gitOps,
-		fileService: fileService,
+		output:             output,
+		fileOps:            fileOps,
+		pathUtils:          pathUtils,
+		gitOps:             gitOps,
+		fileService:        fileService,
+		manifestRepository: manifestRepository,
+		backups:            backups,
+		index:              index,
+		watcher:            watcher,
 	}
 }
 
@@ -77,7 +131,11 @@ func (s *SyncService) getRulesSourceDir(flagValue string) (string, error) {
 	if flagValue == "" {
 		return "", fmt.Errorf("rules directory not specified: use --rules-dir flag")
 	}
-	return flagValue, nil
+	rulesDir, err := filepath.Abs(flagValue)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve rules directory %s: %w", flagValue, err)
+	}
+	return rulesDir, nil
 }
 
 // cleanupExtraFiles removes files that exist in destination but not in source
@@ -104,10 +162,9 @@ func (s *SyncService) cleanupExtraFiles(srcFiles []string, srcBase, dstBase stri
 
 		if !srcFilesMap[relativePath] {
 			if err := s.fileOps.RemoveFile(destFile); err != nil {
-				s.output.PrintErrorf("Error deleting file %s: %v\n", relativePath, err)
-			} else {
-				s.output.PrintOperation("delete", relativePath)
+				return fmt.Errorf("failed to delete file %s: %w", relativePath, err)
 			}
+			s.output.PrintOperation("delete", relativePath)
 		}
 	}
 
@@ -0,0 +1,59 @@
+package sync
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+)
+
+// sourceLink returns target of source file if it is a symlink not copied by content in preserve or skip mode,
+// absolute target is made relative to link directory so that link stays valid in destination.
+// Links into destination directory made by pull --link are not reported, their files are already in place
+func (s *SyncService) sourceLink(srcFileFullPath, dstBase string, options *models.SyncOptions) (string, bool) {
+	if options.Symlinks != models.SymlinkPreserve && options.Symlinks != models.SymlinkSkip {
+		return "", false
+	}
+
+	target, err := s.fileOps.ReadLink(srcFileFullPath)
+	if err != nil {
+		return "", false
+	}
+	if filepath.IsAbs(target) {
+		if isWithinDir(dstBase, target) {
+			return "", false
+		}
+		if rel, err := filepath.Rel(filepath.Dir(srcFileFullPath), target); err == nil {
+			target = rel
+		}
+	}
+	return target, true
+}
+
+// isWithinDir checks if path is located inside dir
+func isWithinDir(dir, path string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// linkMatches checks if destination file is a symlink to target
+func (s *SyncService) linkMatches(dstFileFullPath, target string) bool {
+	dstTarget, err := s.fileOps.ReadLink(dstFileFullPath)
+	return err == nil && dstTarget == target
+}
+
+// copyFile copies source file to destination unless destination already has its content and mode,
+// or recreates preserved symlink with target link unless destination already links to it. Reports if destination was written
+func (s *SyncService) copyFile(srcFileFullPath, dstFileFullPath, link string, dstExists bool, options *models.SyncOptions) (bool, error) {
+	if link != "" {
+		if dstExists && s.linkMatches(dstFileFullPath, link) {
+			return false, nil
+		}
+		return true, s.fileOps.WriteSymlink(link, dstFileFullPath)
+	}
+	return s.fileService.Sync(srcFileFullPath, dstFileFullPath, dstExists, options.OverwriteHeaders, options.FileMode, options.Normalization)
+}
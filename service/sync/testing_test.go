@@ -5,10 +5,17 @@ import (
 
 	"go.uber.org/mock/gomock"
 
+	"github.com/yanodintsovmercuryo/cursync/models"
 	"github.com/yanodintsovmercuryo/cursync/service/sync"
 	syncMocks "github.com/yanodintsovmercuryo/cursync/service/sync/mocks"
 )
 
+// noPatterns matches patterns of options without file patterns
+var noPatterns []string
+
+// noSymlinks matches symlink mode of options without symlink mode
+var noSymlinks models.SymlinkMode
+
 type fixture struct {
 	syncService *sync.SyncService
 
@@ -17,6 +24,10 @@ type fixture struct {
 	pathUtilsMock   *syncMocks.MockpathUtils
 	gitOpsMock      *syncMocks.MockgitOps
 	fileServiceMock *syncMocks.MockfileService
+	manifestMock    *syncMocks.MockmanifestRepository
+	backupMock      *syncMocks.MockbackupRepository
+	indexMock       *syncMocks.MockcontentIndex
+	watcherMock     *syncMocks.Mockwatcher
 }
 
 func setUp(t *testing.T) (*fixture, func()) {
@@ -27,9 +38,13 @@ func setUp(t *testing.T) (*fixture, func()) {
 	pathUtilsMock := syncMocks.NewMockpathUtils(ctrl)
 	gitOpsMock := syncMocks.NewMockgitOps(ctrl)
 	fileServiceMock := syncMocks.NewMockfileService(ctrl)
+	manifestMock := syncMocks.NewMockmanifestRepository(ctrl)
+	backupMock := syncMocks.NewMockbackupRepository(ctrl)
+	indexMock := syncMocks.NewMockcontentIndex(ctrl)
+	watcherMock := syncMocks.NewMockwatcher(ctrl)
 
 	// Use constructor for tests with mocks
-	syncService := sync.NewSyncServiceWithMocks(outputMock, fileOpsMock, pathUtilsMock, gitOpsMock, fileServiceMock)
+	syncService := sync.NewSyncServiceWithMocks(outputMock, fileOpsMock, pathUtilsMock, gitOpsMock, fileServiceMock, manifestMock, backupMock, indexMock, watcherMock)
 
 	return &fixture{
 		syncService:     syncService,
@@ -38,5 +53,58 @@ func setUp(t *testing.T) (*fixture, func()) {
 		pathUtilsMock:   pathUtilsMock,
 		gitOpsMock:      gitOpsMock,
 		fileServiceMock: fileServiceMock,
+		manifestMock:    manifestMock,
+		backupMock:      backupMock,
+		indexMock:       indexMock,
+		watcherMock:     watcherMock,
 	}, ctrl.Finish
 }
+
+// expectBackup sets up recording of backup around the operation in the project
+func (f *fixture) expectBackup(projectRoot, operation string) {
+	f.expectBackupBegin(projectRoot, operation)
+
+	f.backupMock.EXPECT().
+		Finish().
+		Return(nil).
+		Times(1)
+}
+
+// expectRollback sets up recording of backup around the operation in the project which fails and is rolled back
+func (f *fixture) expectRollback(projectRoot, operation string) {
+	f.expectBackupBegin(projectRoot, operation)
+
+	f.backupMock.EXPECT().
+		Rollback().
+		Return(nil).
+		Times(1)
+}
+
+// expectBackupBegin sets up start of backup of the operation in the project
+func (f *fixture) expectBackupBegin(projectRoot, operation string) {
+	manifestPath := projectRoot + "/.cursor/cursync-manifest.json"
+	f.manifestMock.EXPECT().
+		GetManifestPath(projectRoot).
+		Return(manifestPath).
+		Times(1)
+
+	f.backupMock.EXPECT().
+		Begin(projectRoot, operation, manifestPath).
+		Return(nil).
+		Times(1)
+
+	f.expectIndex(testRulesDir, projectRoot+"/.cursor/rules")
+}
+
+// expectIndex sets up loading and saving of content index of directories compared by the operation
+func (f *fixture) expectIndex(dirs ...string) {
+	f.indexMock.EXPECT().
+		Open(dirs).
+		Return(nil).
+		Times(1)
+
+	f.indexMock.EXPECT().
+		Save().
+		Return(nil).
+		Times(1)
+}
@@ -0,0 +1,111 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
+)
+
+// Watch monitors project rules directory and rules source directory, as selected by direction,
+// and runs push or pull once changes settle for the debounce period, so that rapid edits end up in a single commit.
+// Changes made by the push or pull itself are ignored. Sync errors are printed and watching goes on until ctx is done
+func (s *SyncService) Watch(ctx context.Context, options *models.WatchOptions) error {
+	rulesSourceDir, projectRulesDir, _, err := s.preparePullPaths(options.Pull.RulesDir)
+	if err != nil {
+		return err
+	}
+
+	if err := s.fileOps.MkdirAll(projectRulesDir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create project rules directory: %w", err)
+	}
+
+	var dirs []string
+	if options.Direction != models.WatchPull {
+		dirs = append(dirs, projectRulesDir)
+	}
+	if options.Direction != models.WatchPush {
+		dirs = append(dirs, rulesSourceDir)
+	}
+
+	events, err := s.watcher.Watch(ctx, dirs...)
+	if err != nil {
+		return fmt.Errorf("failed to watch rules: %w", err)
+	}
+	s.output.PrintInfo(fmt.Sprintf("Watching %s for changes, press Ctrl+C to stop", strings.Join(dirs, " and ")))
+
+	timer := time.NewTimer(options.Debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	settled := s.snapshotDirs(dirs)
+	pending := make(map[string]bool)
+	for {
+		select {
+		case <-ctx.Done():
+			s.output.PrintInfo("Stopped watching")
+			return nil
+		case dir, ok := <-events:
+			if !ok {
+				s.output.PrintInfo("Stopped watching")
+				return nil
+			}
+			pending[dir] = true
+			timer.Reset(options.Debounce)
+		case <-timer.C:
+			for dir := range pending {
+				if s.isSettled(dir, settled) {
+					delete(pending, dir)
+				}
+			}
+			if len(pending) > 0 {
+				s.syncChanges(options, pending[projectRulesDir], pending[rulesSourceDir])
+				settled = s.snapshotDirs(dirs)
+			}
+			pending = make(map[string]bool)
+		}
+	}
+}
+
+// syncChanges pushes changes of project rules and then pulls changes of rules source directory.
+// Edits made through files linked by pull --link change the rules source directory, so they are pushed to be committed
+func (s *SyncService) syncChanges(options *models.WatchOptions, projectChanged, sourceChanged bool) {
+	if options.Direction != models.WatchPull && (projectChanged || (sourceChanged && options.Pull.Link)) {
+		if _, err := s.PushRules(options.Push); err != nil {
+			s.output.PrintErrorf("Push failed: %v\n", err)
+		}
+	}
+
+	if options.Direction != models.WatchPush && sourceChanged {
+		if _, err := s.PullRules(options.Pull); err != nil {
+			s.output.PrintErrorf("Pull failed: %v\n", err)
+		}
+	}
+}
+
+// snapshotDirs records state of directories left by the last sync, directories that cannot be read are left out
+func (s *SyncService) snapshotDirs(dirs []string) map[string]map[string]manifest.FileEntry {
+	snapshots := make(map[string]map[string]manifest.FileEntry)
+	for _, dir := range dirs {
+		if snapshot, err := s.manifestRepository.Snapshot(dir); err == nil {
+			snapshots[dir] = snapshot
+		}
+	}
+	return snapshots
+}
+
+// isSettled checks if directory is still in the state left by the last sync, i.e. it was changed only by the sync
+// itself, whose writes are reported by the watcher after the sync is over
+func (s *SyncService) isSettled(dir string, settled map[string]map[string]manifest.FileEntry) bool {
+	before, ok := settled[dir]
+	if !ok {
+		return false
+	}
+	current, err := s.manifestRepository.Snapshot(dir)
+	return err == nil && maps.Equal(before, current)
+}
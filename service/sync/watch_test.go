@@ -0,0 +1,212 @@
+package sync_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/yanodintsovmercuryo/cursync/models"
+	"github.com/yanodintsovmercuryo/cursync/pkg/manifest"
+)
+
+func TestSyncService_Watch(t *testing.T) {
+	settled := map[string]manifest.FileEntry{testRelativePath: {Size: 10, ModTime: 100}}
+	changed := map[string]manifest.FileEntry{testRelativePath: {Size: 10, ModTime: 200}}
+
+	newOptions := func(direction models.WatchDirection) *models.WatchOptions {
+		return &models.WatchOptions{
+			Pull:      &models.SyncOptions{RulesDir: testRulesDir},
+			Push:      &models.SyncOptions{RulesDir: testRulesDir},
+			Direction: direction,
+			Debounce:  time.Millisecond,
+		}
+	}
+
+	// expectWatch sets up watching of dirs reporting changes sent to the returned channel
+	expectWatch := func(f *fixture, dirs ...string) chan string {
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll(testDestRulesDir, os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		events := make(chan string, 1)
+		watched := make([]any, 0, len(dirs))
+		for _, dir := range dirs {
+			watched = append(watched, dir)
+		}
+		f.watcherMock.EXPECT().
+			Watch(gomock.Any(), watched...).
+			Return(events, nil).
+			Times(1)
+
+		for _, dir := range dirs {
+			f.manifestMock.EXPECT().
+				Snapshot(dir).
+				Return(settled, nil).
+				Times(1)
+		}
+		return events
+	}
+
+	// expectChanged reports dir changed since the last sync and expects it to be recorded once synced
+	expectChanged := func(f *fixture, dir string) {
+		f.manifestMock.EXPECT().
+			Snapshot(dir).
+			Return(changed, nil).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Snapshot(dir).
+			Return(changed, nil).
+			Times(1)
+	}
+
+	t.Run("stops when context is done", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		expectWatch(f, testDestRulesDir, testRulesDir)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		f.outputMock.EXPECT().
+			PrintInfo("Watching " + testDestRulesDir + " and " + testRulesDir + " for changes, press Ctrl+C to stop").
+			Do(func(string) { cancel() }).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintInfo("Stopped watching").
+			Times(1)
+
+		require.NoError(t, f.syncService.Watch(ctx, newOptions(models.WatchBoth)))
+	})
+
+	t.Run("pushes project changes and goes on after failure", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		events := expectWatch(f, testDestRulesDir)
+		f.outputMock.EXPECT().
+			PrintInfo("Watching " + testDestRulesDir + " for changes, press Ctrl+C to stop").
+			Do(func(string) { events <- testDestRulesDir }).
+			Times(1)
+
+		expectChanged(f, testDestRulesDir)
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return("", errors.New("no cwd")).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintErrorf("Push failed: %v\n", gomock.Any()).
+			Do(func(string, ...interface{}) { close(events) }).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintInfo("Stopped watching").
+			Times(1)
+
+		require.NoError(t, f.syncService.Watch(context.Background(), newOptions(models.WatchPush)))
+	})
+
+	t.Run("pulls source changes", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		events := expectWatch(f, testRulesDir)
+		f.outputMock.EXPECT().
+			PrintInfo("Watching " + testRulesDir + " for changes, press Ctrl+C to stop").
+			Do(func(string) { events <- testRulesDir }).
+			Times(1)
+
+		expectChanged(f, testRulesDir)
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return("", errors.New("no cwd")).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintErrorf("Pull failed: %v\n", gomock.Any()).
+			Do(func(string, ...interface{}) { close(events) }).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintInfo("Stopped watching").
+			Times(1)
+
+		require.NoError(t, f.syncService.Watch(context.Background(), newOptions(models.WatchPull)))
+	})
+
+	t.Run("ignores changes made by its own sync", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		events := expectWatch(f, testDestRulesDir)
+		f.outputMock.EXPECT().
+			PrintInfo("Watching " + testDestRulesDir + " for changes, press Ctrl+C to stop").
+			Do(func(string) { events <- testDestRulesDir }).
+			Times(1)
+
+		f.manifestMock.EXPECT().
+			Snapshot(testDestRulesDir).
+			Return(settled, nil).
+			Do(func(string) { close(events) }).
+			Times(1)
+
+		f.outputMock.EXPECT().
+			PrintInfo("Stopped watching").
+			Times(1)
+
+		require.NoError(t, f.syncService.Watch(context.Background(), newOptions(models.WatchPush)))
+	})
+
+	t.Run("returns watch error", func(t *testing.T) {
+		t.Parallel()
+		f, finish := setUp(t)
+		defer finish()
+
+		f.fileOpsMock.EXPECT().
+			GetCurrentDir().
+			Return(testCurrentDir, nil).
+			Times(1)
+
+		f.gitOpsMock.EXPECT().
+			GetGitRootDir(testCurrentDir).
+			Return(testGitRoot, nil).
+			Times(1)
+
+		f.fileOpsMock.EXPECT().
+			MkdirAll(testDestRulesDir, os.ModePerm).
+			Return(nil).
+			Times(1)
+
+		f.watcherMock.EXPECT().
+			Watch(gomock.Any(), testDestRulesDir, testRulesDir).
+			Return(nil, errors.New("too many watches")).
+			Times(1)
+
+		err := f.syncService.Watch(context.Background(), newOptions(models.WatchBoth))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to watch rules")
+	})
+}